@@ -55,7 +55,10 @@ func main() {
 	// коэффициент регуляризации,
 	// устанавливаем печать текущей эпохи,
 	// устанавливаем флаг, чтобы при обучении была нормализация данных.
-	nn.Sgd(&dfTrain, 1, 10, 0.01, 5, true, false)
+	err = nn.Sgd(&dfTrain, 1, 10, 0.01, 5, true, false)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// Печатаем метрику оценки качества (количество правильно угаданных цифр).
 	fmt.Println("Accuracy: ", nn.Accuracy(dfTest))
@@ -0,0 +1,165 @@
+/*
+Package autodiff реализует автоматическое дифференцирование в обратном режиме над матрицами пакета matrix.
+Операции над переменными (структурами Variable) записываются на ленту (структуру Tape) в порядке вычисления,
+метод Backward проходит ленту в обратном порядке и находит градиенты результата по всем переменным,
+поэтому для нового слоя или функции потерь достаточно составить их из операций пакета,
+не выводя производные вручную.
+
+Операции вычисляют значения сразу (как методы matrix.Matrix) и вызывают панику при несовместимых
+размерностях или типах элементов операндов. Переменные разных лент смешивать нельзя.
+Лента не безопасна для одновременного использования несколькими горутинами.
+*/
+package autodiff
+
+import (
+	"github.com/Davmgiz/GoblinNeuronet/pkg/matrix"
+)
+
+// Tape представляет ленту вычислений, на которую записываются операции над переменными.
+type Tape struct {
+	nodes []*node // Узлы в порядке вычисления
+	pass  int     // Номер текущего обратного прохода
+}
+
+// node узел ленты: значение переменной, градиент по ней и правило обратного распространения.
+type node struct {
+	value    matrix.Matrix         // Значение переменной
+	grad     matrix.Matrix         // Градиент по переменной, создается при обратном проходе
+	hasGrad  bool                  // Создан ли градиент
+	pass     int                   // Номер обратного прохода, в котором вычислен градиент по результату операции
+	constant bool                  // Константа, градиент по которой не нужен
+	backward func(g matrix.Matrix) // Прибавляет к градиентам операндов вклад градиента g по результату, nil для листьев
+}
+
+// Variable представляет переменную (узел) ленты вычислений.
+// Переменная является либо листом (см. Tape.Variable и Tape.Constant), либо результатом операции.
+type Variable struct {
+	tape *Tape
+	node *node
+}
+
+// NewTape возвращает указатель на новую пустую ленту вычислений.
+func NewTape() *Tape {
+	return &Tape{}
+}
+
+// Variable возвращает переменную-лист ленты со значением M, по которой вычисляется градиент.
+// Значение не копируется, поэтому M не должна изменяться до окончания обратного прохода.
+func (t *Tape) Variable(M matrix.Matrix) Variable {
+	return t.push(&node{value: M})
+}
+
+// Constant возвращает переменную-лист ленты со значением M, по которой градиент не вычисляется
+// (например вектор признаков или целевую переменную).
+func (t *Tape) Constant(M matrix.Matrix) Variable {
+	return t.push(&node{value: M, constant: true})
+}
+
+// Reset очищает ленту, чтобы использовать ее для нового вычисления.
+// Переменные, созданные до вызова Reset, больше не используются.
+func (t *Tape) Reset() {
+	for i := range t.nodes {
+		t.nodes[i] = nil
+	}
+	t.nodes = t.nodes[:0]
+}
+
+// Len возвращает количество переменных, записанных на ленту.
+func (t *Tape) Len() int {
+	return len(t.nodes)
+}
+
+// Backward вычисляет градиенты суммы элементов v по всем переменным ленты, от которых зависит v.
+// Градиенты по листьям прибавляются к уже вычисленным, поэтому повторный вызов для другого результата
+// складывает их (для обнуления используется ZeroGrad), градиенты по результатам операций
+// относятся только к последнему вызову.
+func (t *Tape) Backward(v Variable) {
+	seed := matrix.ZeroOf(v.Value().GetRows(), v.Value().GetColumns(), v.Value().DType())
+	seed.Fill(1.)
+	t.BackwardWith(v, seed)
+}
+
+// BackwardWith вычисляет градиенты по всем переменным ленты, от которых зависит v,
+// если градиент по v равен seed (произведение seed на матрицу Якоби v).
+// Метод вызывает панику, если размерность или тип элементов seed отличаются от значения v,
+// или v принадлежит другой ленте.
+func (t *Tape) BackwardWith(v Variable, seed matrix.Matrix) {
+	t.check(v)
+
+	t.pass++
+	v.add(seed)
+
+	// узлы лежат на ленте в порядке вычисления, поэтому градиент по результату операции
+	// полностью вычислен до того, как он передается операндам
+	for i := len(t.nodes) - 1; i >= 0; i-- {
+		n := t.nodes[i]
+		if n.backward != nil && n.pass == t.pass {
+			n.backward(n.grad)
+		}
+	}
+}
+
+// ZeroGrad обнуляет градиенты всех переменных ленты.
+func (t *Tape) ZeroGrad() {
+	for _, n := range t.nodes {
+		if n.hasGrad {
+			n.grad.Fill(0.)
+		}
+	}
+}
+
+// Value возвращает значение переменной. Значение не должно изменяться,
+// пока переменная используется в вычислениях.
+func (v Variable) Value() matrix.Matrix {
+	return v.node.value
+}
+
+// Grad возвращает градиент по переменной, вычисленный Backward, или нулевую матрицу,
+// если результат не зависит от переменной или переменная является константой.
+func (v Variable) Grad() matrix.Matrix {
+	if !v.node.hasGrad {
+		return matrix.ZeroOf(v.node.value.GetRows(), v.node.value.GetColumns(), v.node.value.DType())
+	}
+	return v.node.grad
+}
+
+// IsConstant возвращает true, если градиент по переменной не вычисляется:
+// переменная является константой или результатом операции только над константами.
+func (v Variable) IsConstant() bool {
+	return v.node.constant
+}
+
+// push добавляет узел на ленту и возвращает его переменную.
+func (t *Tape) push(n *node) Variable {
+	t.nodes = append(t.nodes, n)
+	return Variable{tape: t, node: n}
+}
+
+// check вызывает панику, если переменные vs не принадлежат ленте t.
+func (t *Tape) check(vs ...Variable) {
+	for _, v := range vs {
+		if v.tape != t {
+			panic("autodiff: variable belongs to another tape")
+		}
+	}
+}
+
+// add прибавляет g к градиенту по переменной в текущем обратном проходе.
+// Градиент по результату операции, вычисленный в предыдущем проходе, предварительно обнуляется.
+// Для констант градиент не накапливается.
+func (v Variable) add(g matrix.Matrix) {
+	n := v.node
+	if n.constant {
+		return
+	}
+
+	if !n.hasGrad {
+		n.grad = matrix.ZeroOf(n.value.GetRows(), n.value.GetColumns(), n.value.DType())
+		n.hasGrad = true
+	} else if n.backward != nil && n.pass != v.tape.pass {
+		n.grad.Fill(0.)
+	}
+	n.pass = v.tape.pass
+
+	n.grad.AddInPlace(g)
+}
@@ -0,0 +1,145 @@
+package autodiff
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/Davmgiz/GoblinNeuronet/pkg/matrix"
+)
+
+// checkGrad сравнивает градиенты функции f по листьям leaves, вычисленные Backward,
+// с численными производными (центральными разностями).
+func checkGrad(t *testing.T, name string, f func(tape *Tape, vs []Variable) Variable, leaves ...matrix.Matrix) {
+	t.Helper()
+
+	tape := NewTape()
+	vs := make([]Variable, len(leaves))
+	for k, M := range leaves {
+		vs[k] = tape.Variable(M)
+	}
+	tape.Backward(f(tape, vs))
+
+	// значение суммы элементов результата
+	eval := func() float64 {
+		tape := NewTape()
+		vs := make([]Variable, len(leaves))
+		for k, M := range leaves {
+			vs[k] = tape.Variable(M)
+		}
+		return f(tape, vs).Value().Sum()
+	}
+
+	h := 1e-6
+	for k, M := range leaves {
+		for i := 0; i < M.GetRows(); i++ {
+			for j := 0; j < M.GetColumns(); j++ {
+				x := M.GetIJ(i, j)
+				M.SetIJ(i, j, x+h)
+				plus := eval()
+				M.SetIJ(i, j, x-h)
+				minus := eval()
+				M.SetIJ(i, j, x)
+
+				num := (plus - minus) / (2 * h)
+				if got := vs[k].Grad().GetIJ(i, j); math.Abs(got-num) > 1e-6*(1+math.Abs(num)) {
+					t.Errorf("%s: gradient of leaf %d element %d, %d: %v != %v", name, k, i, j, got, num)
+				}
+			}
+		}
+	}
+}
+
+func TestGradients(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	rnd := func(rows, columns int) matrix.Matrix {
+		M := matrix.RandMatrixFrom(rows, columns, matrix.Float64, rng)
+		M.ScaleInPlace(10)
+		return M
+	}
+	sigmoid := func(x float64) float64 { return 1. / (1. + math.Exp(-x)) }
+	sigmoidPrime := func(x float64) float64 { return sigmoid(x) * (1 - sigmoid(x)) }
+
+	S, err := matrix.SparseFromTriplets(4, 2, []int{0, 2, 3}, []int{0, 1, 1}, []float64{1.5, -2, 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkGrad(t, "Dot", func(tape *Tape, vs []Variable) Variable {
+		return vs[0].Dot(vs[1])
+	}, rnd(3, 4), rnd(4, 2))
+	checkGrad(t, "DotSparse", func(tape *Tape, vs []Variable) Variable {
+		return vs[0].DotSparse(S).HadamardProduct(vs[0].DotSparse(S))
+	}, rnd(3, 4))
+	checkGrad(t, "Add Sub HadamardProduct", func(tape *Tape, vs []Variable) Variable {
+		return vs[0].Add(vs[1]).HadamardProduct(vs[0].Sub(vs[1])).Scale(0.5)
+	}, rnd(2, 3), rnd(2, 3))
+	checkGrad(t, "AddBroadcast", func(tape *Tape, vs []Variable) Variable {
+		x := vs[0].AddBroadcast(vs[1]).AddBroadcast(vs[2])
+		return x.HadamardProduct(x)
+	}, rnd(3, 4), rnd(3, 1), rnd(1, 4))
+	checkGrad(t, "ForEach T", func(tape *Tape, vs []Variable) Variable {
+		return vs[0].ForEach(sigmoid, sigmoidPrime).T().Dot(vs[1])
+	}, rnd(3, 2), rnd(3, 2))
+	checkGrad(t, "Softmax", func(tape *Tape, vs []Variable) Variable {
+		return vs[0].Softmax().HadamardProduct(vs[1])
+	}, rnd(4, 3), rnd(4, 3))
+	checkGrad(t, "LogSoftmax", func(tape *Tape, vs []Variable) Variable {
+		return vs[0].LogSoftmax().HadamardProduct(vs[1])
+	}, rnd(4, 3), rnd(4, 3))
+	checkGrad(t, "LogSumExp Mean", func(tape *Tape, vs []Variable) Variable {
+		return vs[0].LogSumExp().HadamardProduct(vs[1]).Mean()
+	}, rnd(4, 3), rnd(1, 3))
+
+	// двухслойная сеть с функцией потерь перекрестной энтропии
+	x, y := rnd(4, 1), matrix.Zero(2, 1)
+	y.SetIJ(1, 0, 1)
+	checkGrad(t, "network", func(tape *Tape, vs []Variable) Variable {
+		a := vs[0].Dot(tape.Constant(x)).AddBroadcast(vs[1]).ForEach(math.Tanh, func(z float64) float64 {
+			return 1 - math.Tanh(z)*math.Tanh(z)
+		})
+		logits := vs[2].Dot(a).Add(vs[3])
+		return logits.LogSoftmax().HadamardProduct(tape.Constant(y)).Scale(-1).Sum()
+	}, rnd(3, 4), rnd(3, 1), rnd(2, 3), rnd(2, 1))
+}
+
+func TestTape(t *testing.T) {
+	tape := NewTape()
+	a := tape.Variable(matrix.Zero(2, 2))
+	c := tape.Constant(matrix.Zero(2, 2))
+	a.Value().Fill(3)
+
+	if !c.Add(c).IsConstant() || a.Add(c).IsConstant() {
+		t.Errorf("Incorrect constant propagation")
+	}
+
+	// градиенты по листьям накапливаются, по результатам операций относятся к последнему проходу
+	b := a.HadamardProduct(a)
+	tape.Backward(b)
+	tape.Backward(b)
+	if a.Grad().GetIJ(0, 1) != 12 || b.Grad().GetIJ(0, 1) != 1 {
+		t.Errorf("Incorrect accumulated gradients: %v, %v", a.Grad().GetIJ(0, 1), b.Grad().GetIJ(0, 1))
+	}
+	if c.Grad().Sum() != 0 {
+		t.Errorf("Gradient of constant must be zero")
+	}
+
+	tape.ZeroGrad()
+	if a.Grad().Sum() != 0 {
+		t.Errorf("ZeroGrad does not clear gradients")
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Expected panic for variables of different tapes")
+			}
+		}()
+		a.Add(NewTape().Variable(matrix.Zero(2, 2)))
+	}()
+
+	tape.Reset()
+	if tape.Len() != 0 {
+		t.Errorf("Reset does not clear tape")
+	}
+}
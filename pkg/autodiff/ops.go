@@ -0,0 +1,196 @@
+package autodiff
+
+// файл содержит операции над переменными и их правила обратного распространения
+
+import (
+	"math"
+
+	"github.com/Davmgiz/GoblinNeuronet/pkg/matrix"
+)
+
+// tapeOf возвращает ленту переменных vs и вызывает панику, если переменные принадлежат разным лентам.
+func tapeOf(vs ...Variable) *Tape {
+	t := vs[0].tape
+	t.check(vs...)
+	return t
+}
+
+// record добавляет на ленту результат value операции над операндами args с правилом обратного распространения backward.
+// Если все операнды константы, то результат также является константой и правило не сохраняется.
+func (t *Tape) record(value matrix.Matrix, backward func(g matrix.Matrix), args ...Variable) Variable {
+	for _, a := range args {
+		if !a.IsConstant() {
+			return t.push(&node{value: value, backward: backward})
+		}
+	}
+	return t.push(&node{value: value, constant: true})
+}
+
+// Dot возвращает переменную, равную произведению матриц a и b.
+func (a Variable) Dot(b Variable) Variable {
+	t := tapeOf(a, b)
+	A, B := a.Value(), b.Value()
+
+	return t.record(A.Dot(B), func(g matrix.Matrix) {
+		if !a.IsConstant() {
+			a.add(g.DotT(B))
+		}
+		if !b.IsConstant() {
+			b.add(A.TDot(g))
+		}
+	}, a, b)
+}
+
+// DotSparse возвращает переменную, равную произведению матрицы a на разреженную матрицу S
+// (например матрицы весов на разреженный вектор признаков). Градиент по S не вычисляется.
+func (a Variable) DotSparse(S matrix.Sparse) Variable {
+	return a.tape.record(a.Value().DotSparse(S), func(g matrix.Matrix) {
+		a.add(g.DotTSparse(S))
+	}, a)
+}
+
+// Add возвращает переменную, равную сумме матриц a и b.
+func (a Variable) Add(b Variable) Variable {
+	t := tapeOf(a, b)
+
+	return t.record(a.Value().Add(b.Value()), func(g matrix.Matrix) {
+		a.add(g)
+		b.add(g)
+	}, a, b)
+}
+
+// Sub возвращает переменную, равную разности матриц a и b.
+func (a Variable) Sub(b Variable) Variable {
+	t := tapeOf(a, b)
+
+	return t.record(a.Value().Sub(b.Value()), func(g matrix.Matrix) {
+		a.add(g)
+		if !b.IsConstant() {
+			b.add(g.ForEach(func(x float64) float64 { return -x }))
+		}
+	}, a, b)
+}
+
+// HadamardProduct возвращает переменную, равную адамарному произведению матриц a и b.
+func (a Variable) HadamardProduct(b Variable) Variable {
+	t := tapeOf(a, b)
+	A, B := a.Value(), b.Value()
+
+	return t.record(A.HadamardProduct(B), func(g matrix.Matrix) {
+		if !a.IsConstant() {
+			a.add(g.HadamardProduct(B))
+		}
+		if !b.IsConstant() {
+			b.add(g.HadamardProduct(A))
+		}
+	}, a, b)
+}
+
+// AddBroadcast возвращает переменную, равную сумме матрицы a и вектора v, растянутого на размерность a
+// (см. matrix.Matrix.AddBroadcast), например прибавление смещений к мини батчу.
+// Градиент по v равен сумме градиента по результату вдоль растянутого направления.
+func (a Variable) AddBroadcast(v Variable) Variable {
+	t := tapeOf(a, v)
+	A, V := a.Value(), v.Value()
+
+	return t.record(A.AddBroadcast(V), func(g matrix.Matrix) {
+		a.add(g)
+		if v.IsConstant() {
+			return
+		}
+		if V.GetRows() == A.GetRows() && V.GetColumns() == 1 {
+			v.add(g.SumAxis(matrix.AxisRows))
+		} else {
+			v.add(g.SumAxis(matrix.AxisColumns))
+		}
+	}, a, v)
+}
+
+// Scale возвращает переменную, равную матрице a, умноженной на число k.
+func (a Variable) Scale(k float64) Variable {
+	res := a.Value().AsType(a.Value().DType())
+	res.ScaleInPlace(k)
+
+	return a.tape.record(res, func(g matrix.Matrix) {
+		gk := g.AsType(g.DType())
+		gk.ScaleInPlace(k)
+		a.add(gk)
+	}, a)
+}
+
+// ForEach возвращает переменную, равную результату применения функции f к каждому элементу матрицы a.
+// prime производная функции f, которая используется при обратном проходе.
+func (a Variable) ForEach(f, prime func(float64) float64) Variable {
+	A := a.Value()
+
+	return a.tape.record(A.ForEach(f), func(g matrix.Matrix) {
+		a.add(g.HadamardProduct(A.ForEach(prime)))
+	}, a)
+}
+
+// T возвращает переменную, равную транспонированной матрице a.
+func (a Variable) T() Variable {
+	return a.tape.record(a.Value().T(), func(g matrix.Matrix) {
+		a.add(g.T())
+	}, a)
+}
+
+// Softmax возвращает переменную, равную softmax столбцов матрицы a (см. matrix.Matrix.Softmax).
+func (a Variable) Softmax() Variable {
+	A := a.Value()
+
+	// матрица Якоби softmax симметрична, поэтому произведение градиента на нее равно SoftmaxJVP
+	return a.tape.record(A.Softmax(), func(g matrix.Matrix) {
+		a.add(A.SoftmaxJVP(g))
+	}, a)
+}
+
+// LogSoftmax возвращает переменную, равную логарифму softmax столбцов матрицы a (см. matrix.Matrix.LogSoftmax).
+func (a Variable) LogSoftmax() Variable {
+	res := a.Value().LogSoftmax()
+
+	// градиент по столбцу равен g - softmax · sum(g)
+	return a.tape.record(res, func(g matrix.Matrix) {
+		s := res.ForEach(math.Exp)
+		s.HadamardProductBroadcastInPlace(g.SumAxis(matrix.AxisColumns))
+		a.add(g.Sub(s))
+	}, a)
+}
+
+// LogSumExp возвращает переменную, равную вектору-строке логарифмов сумм экспонент столбцов матрицы a
+// (см. matrix.Matrix.LogSumExp).
+func (a Variable) LogSumExp() Variable {
+	A := a.Value()
+
+	// градиент по столбцу равен softmax столбца, умноженному на градиент по его log-sum-exp
+	return a.tape.record(A.LogSumExp(), func(g matrix.Matrix) {
+		s := A.Softmax()
+		s.HadamardProductBroadcastInPlace(g)
+		a.add(s)
+	}, a)
+}
+
+// Sum возвращает переменную, равную матрице 1 на 1 из суммы всех элементов матрицы a.
+func (a Variable) Sum() Variable {
+	return a.reduce(a.Value().Sum(), 1.)
+}
+
+// Mean возвращает переменную, равную матрице 1 на 1 из среднего арифметического всех элементов матрицы a.
+func (a Variable) Mean() Variable {
+	A := a.Value()
+	return a.reduce(A.Mean(), 1./float64(A.GetRows()*A.GetColumns()))
+}
+
+// reduce возвращает переменную, равную матрице 1 на 1 из числа x, производная которого
+// по каждому элементу матрицы a равна k.
+func (a Variable) reduce(x, k float64) Variable {
+	A := a.Value()
+	res := matrix.ZeroOf(1, 1, A.DType())
+	res.SetIJ(0, 0, x)
+
+	return a.tape.record(res, func(g matrix.Matrix) {
+		grad := matrix.ZeroOf(A.GetRows(), A.GetColumns(), A.DType())
+		grad.Fill(k * g.GetIJ(0, 0))
+		a.add(grad)
+	}, a)
+}
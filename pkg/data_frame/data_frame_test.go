@@ -1,6 +1,8 @@
 package data_frame
 
 import (
+	"bytes"
+	"errors"
 	"testing"
 
 	"github.com/Davmgiz/GoblinNeuronet/pkg/matrix"
@@ -94,3 +96,65 @@ func TestNormalization(t *testing.T) {
 		}
 	}
 }
+
+// TestLIBSVM проверяет чтение и запись датафрейма (структуры DataFrame) в формате LIBSVM.
+func TestLIBSVM(t *testing.T) {
+	data := "1 1:0.5 3:2 # comment\n\n-1 qid:3 2:-1.25\n0\n"
+
+	df, err := ReadLIBSVMFrom(bytes.NewBufferString(data), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]float64{{0.5, 0, 2}, {0, -1.25, 0}, {0, 0, 0}}
+	labels := []float64{1, -1, 0}
+	if df.Lenght() != len(expected) {
+		t.Fatalf("Expected %d records, got %d", len(expected), df.Lenght())
+	}
+	for i := range expected {
+		x, y := df.GetRow(i)
+		e := matrix.Zero(3, 1)
+		e.Slice2Matrix(expected[i])
+		if !df.Data[i].IsSparse() || !matrix.IsMatrixesEqual(x, e) || y.GetIJ(0, 0) != labels[i] {
+			t.Errorf("Incorrect record %d", i)
+		}
+	}
+
+	// количество признаков может быть больше наибольшего индекса
+	if df, err := ReadLIBSVMFrom(bytes.NewBufferString(data), 5); err != nil || df.Data[0].GetX().GetRows() != 5 {
+		t.Errorf("Incorrect number of features: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := df.WriteLIBSVM(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "1 1:0.5 3:2\n-1 2:-1.25\n0\n" {
+		t.Errorf("Incorrect libsvm output %q", buf.String())
+	}
+
+	malformed := []struct {
+		data string
+		line int
+	}{
+		{"1 1:2\nx 1:2\n", 2},
+		{"1 1:2 3\n", 1},
+		{"1 0:2\n", 1},
+		{"1 2:1 1:2\n", 1},
+		{"1 1:a\n", 1},
+		{"1 1:1\n\n1 4:1\n", 3},
+	}
+	for _, m := range malformed {
+		var parseErr *matrix.ParseError
+		if _, err := ReadLIBSVMFrom(bytes.NewBufferString(m.data), 3); !errors.As(err, &parseErr) || parseErr.Line != m.line {
+			t.Errorf("Expected *ParseError at line %d for %q, got %v", m.line, m.data, err)
+		}
+	}
+
+	// целевая переменная, закодированная вектором, не записывается
+	var vec DataFrame
+	vec.Append(matrix.Zero(3, 1), matrix.Zero(2, 1))
+	if err := vec.WriteLIBSVM(&buf); err == nil {
+		t.Errorf("Expected error for vector target")
+	}
+}
@@ -1,6 +1,6 @@
 /*
 Package data_frame предоставляет набор инструментов для работы с данными. Основные возможности
-включают в себя создание датафрейма из CSV файла, нормализацию датафрейма.
+включают в себя создание датафрейма из CSV и LIBSVM файлов, нормализацию датафрейма.
 Пакет предназначен для реализации нейронной сети.
 */
 package data_frame
@@ -10,16 +10,37 @@ import (
 )
 
 // rowDataFrame представляет структуру одного наблюдения.
+// Вектор признаков хранится либо в плотном виде в x, либо в разреженном виде в xSparse.
 type rowDataFrame struct {
-	x matrix.Matrix // Вектор из признаков (матрица размерности n на 1, где n некоторое число)
-	y matrix.Matrix // Целевая переменная
+	x        matrix.Matrix // Вектор из признаков (матрица размерности n на 1, где n некоторое число)
+	y        matrix.Matrix // Целевая переменная
+	xSparse  matrix.Sparse // Разреженный вектор из признаков (разреженная матрица размерности n на 1)
+	isSparse bool          // Хранится ли вектор признаков в разреженном виде
 }
 
 // GetX возвращает вектор признаков исходного наблюдения(структуру Matrix)
+// Если вектор признаков хранится в разреженном виде, то возвращается его плотная копия.
 func (row *rowDataFrame) GetX() matrix.Matrix {
+	if row.isSparse {
+		return row.xSparse.ToMatrix()
+	}
 	return row.x
 }
 
+// IsSparse возвращает true, если вектор признаков наблюдения хранится в разреженном виде.
+func (row *rowDataFrame) IsSparse() bool {
+	return row.isSparse
+}
+
+// GetSparseX возвращает разреженный вектор признаков исходного наблюдения (структуру Sparse).
+// Если вектор признаков хранится в плотном виде, то он преобразуется в разреженный.
+func (row *rowDataFrame) GetSparseX() matrix.Sparse {
+	if row.isSparse {
+		return row.xSparse
+	}
+	return matrix.SparseFromMatrix(row.x)
+}
+
 // GetY возвращает матрицу (структуру Matrix) размерности 1 на 1,
 // в которой содержится целевая переменная исходного наблюдения.
 func (row *rowDataFrame) GetY() matrix.Matrix {
@@ -39,6 +60,27 @@ func (df *DataFrame) Lenght() int {
 
 // GetRow возвращает x, y наблюдения с индексом i датафрейма.
 // Возвращает вектор признаков и целевую переменную соответственно.
+// Разреженный вектор признаков возвращается в плотном виде (см. GetX).
 func (df *DataFrame) GetRow(i int) (matrix.Matrix, matrix.Matrix) {
-	return df.Data[i].x, df.Data[i].y
+	return df.Data[i].GetX(), df.Data[i].y
+}
+
+// Append добавляет в конец датафрейма наблюдение с вектором признаков x (матрицей размерности n на 1)
+// и целевой переменной y.
+func (df *DataFrame) Append(x, y matrix.Matrix) {
+	df.Data = append(df.Data, &rowDataFrame{
+		x: x,
+		y: y,
+	})
+}
+
+// AppendSparse добавляет в конец датафрейма наблюдение с разреженным вектором признаков x
+// (разреженной матрицей размерности n на 1) и целевой переменной y.
+// Вектор признаков хранится и подается на вход нейронной сети в разреженном виде.
+func (df *DataFrame) AppendSparse(x matrix.Sparse, y matrix.Matrix) {
+	df.Data = append(df.Data, &rowDataFrame{
+		xSparse:  x,
+		y:        y,
+		isSparse: true,
+	})
 }
@@ -5,19 +5,26 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
-	"time"
 
 	"github.com/Davmgiz/GoblinNeuronet/pkg/matrix"
 )
 
-// init инициализирует генератор псевдослучайных чисел
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
 // Shuffle перемешивает датафрейм, изменяя его.
+// Используется глобальный генератор пакета math/rand.
 func (df *DataFrame) Shuffle() {
-	rand.Shuffle(len(df.Data), func(i int, j int) {
+	df.ShuffleFrom(nil)
+}
+
+// ShuffleFrom работает так же, как Shuffle, но берет случайные перестановки из генератора rng.
+// Генераторы с одинаковым начальным значением перемешивают одинаковые датафреймы одинаково.
+// Если rng равен nil, используется глобальный генератор пакета math/rand.
+func (df *DataFrame) ShuffleFrom(rng *rand.Rand) {
+	shuffle := rand.Shuffle
+	if rng != nil {
+		shuffle = rng.Shuffle
+	}
+
+	shuffle(len(df.Data), func(i int, j int) {
 		df.Data[i], df.Data[j] = df.Data[j], df.Data[i]
 	})
 }
@@ -30,6 +37,31 @@ func (df *DataFrame) CopyMiniBatch(i int, miniBatchSize int) DataFrame {
 	}
 }
 
+// AsType возвращает копию датафрейма (структуры DataFrame), в которой признаки и целевые переменные
+// всех наблюдений преобразованы к типу элементов dtype.
+// Разреженные векторы признаков не преобразуются.
+// Исходный датафрейм не изменяется.
+func (df *DataFrame) AsType(dtype matrix.DType) DataFrame {
+	data := make([]*rowDataFrame, len(df.Data))
+
+	for i, row := range df.Data {
+		data[i] = &rowDataFrame{
+			y:        row.y.AsType(dtype),
+			xSparse:  row.xSparse,
+			isSparse: row.isSparse,
+		}
+
+		// разреженные векторы признаков всегда хранят элементы float64
+		if !row.isSparse {
+			data[i].x = row.x.AsType(dtype)
+		}
+	}
+
+	return DataFrame{
+		Data: data,
+	}
+}
+
 // Num2Vec кодирует в вектор матрицу размерности n на 1 (структуру Matrix) целевую переменную (структуру Matrix) датафрейма.
 // Метод возвращает ошибку, если целевая переменная не может быть представлена целым числом.
 func (df *DataFrame) Num2Vec(n int) error {
@@ -56,11 +88,22 @@ func (df *DataFrame) Normalization() (matrix.Matrix, error) {
 	}
 
 	// Храним максимальные модули.
-	maxAll := make([]float64, df.Data[0].x.GetRows())
+	maxAll := make([]float64, df.Data[0].features())
 
 	for i := 0; i < df.Lenght(); i++ {
-		for j := 0; j < df.Data[0].x.GetRows(); j++ {
-			maxAll[j] = maxF64(math.Abs(df.Data[i].x.GetIJ(j, 0)), maxAll[j])
+		row := df.Data[i]
+
+		// в разреженном векторе перебираются только ненулевые признаки
+		if row.isSparse {
+			rowIdx, _, values := row.xSparse.Triplets()
+			for n, j := range rowIdx {
+				maxAll[j] = maxF64(math.Abs(values[n]), maxAll[j])
+			}
+			continue
+		}
+
+		for j := 0; j < len(maxAll); j++ {
+			maxAll[j] = maxF64(math.Abs(row.x.GetIJ(j, 0)), maxAll[j])
 		}
 	}
 
@@ -74,16 +117,36 @@ func (df *DataFrame) Normalization() (matrix.Matrix, error) {
 		}
 	}
 
-	norm := matrix.Zero(df.Data[0].x.GetRows(), 1)
+	norm := matrix.Zero(len(maxAll), 1)
 	norm.Slice2Matrix(maxAll)
 
+	// для наблюдений с элементами float32
+	norm32 := norm.AsType(matrix.Float32)
+
 	for i := 0; i < df.Lenght(); i++ {
-		df.Data[i].x.HadamardProductInPlace(norm)
+		row := df.Data[i]
+
+		switch {
+		case row.isSparse:
+			row.xSparse = row.xSparse.ScaleRows(norm)
+		case row.x.DType() == matrix.Float32:
+			row.x.HadamardProductInPlace(norm32)
+		default:
+			row.x.HadamardProductInPlace(norm)
+		}
 	}
 
 	return norm, nil
 }
 
+// features возвращает количество признаков наблюдения.
+func (row *rowDataFrame) features() int {
+	if row.isSparse {
+		return row.xSparse.GetRows()
+	}
+	return row.x.GetRows()
+}
+
 // maxF64 вспомогательная функция для функции Normalization.
 func maxF64(a, b float64) float64 {
 	if a > b {
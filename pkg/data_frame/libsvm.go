@@ -0,0 +1,185 @@
+package data_frame
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Davmgiz/GoblinNeuronet/pkg/matrix"
+)
+
+// libsvmFormat имя формата LIBSVM в ошибках чтения.
+const libsvmFormat = "libsvm"
+
+// libsvmMaxLine наибольшая длина строки файла LIBSVM.
+const libsvmMaxLine = 64 << 20
+
+// ReadLIBSVM возвращает датафрейм (структуру DataFrame), считанный из файла в формате LIBSVM, и ошибку.
+// Каждая строка файла содержит целевую переменную и ненулевые признаки наблюдения:
+// "label index:value index:value ...", индексы признаков начинаются с 1 и возрастают.
+// Векторы признаков хранятся в разреженном виде (см. AppendSparse), целевая переменная в матрице 1 на 1.
+// Количество признаков равно features или, если features не положительно, наибольшему индексу в файле.
+// Пустые строки и текст после # пропускаются, поля qid:n (ранжирование) игнорируются.
+// Функция возвращает *matrix.ParseError с номером строки, если строка имеет некорректный формат
+// или индекс признака больше features.
+func ReadLIBSVM(filename string, features int) (DataFrame, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return DataFrame{}, fmt.Errorf("error opening a libsvm file: %v", err)
+	}
+	defer file.Close()
+
+	return ReadLIBSVMFrom(file, features)
+}
+
+// ReadLIBSVMFrom работает так же, как ReadLIBSVM, но читает данные из потока reader.
+// Функция не открывает и не закрывает поток.
+func ReadLIBSVMFrom(reader io.Reader, features int) (DataFrame, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, libsvmMaxLine)
+
+	// признаки читаются целиком, так как их количество может быть неизвестно до конца файла
+	var (
+		labels  []float64
+		indices [][]int
+		values  [][]float64
+		maxIdx  int
+	)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if k := strings.IndexByte(text, '#'); k >= 0 {
+			text = text[:k]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+
+		label, idx, vals, err := parseLIBSVMLine(fields)
+		if err != nil {
+			return DataFrame{}, &matrix.ParseError{Format: libsvmFormat, Line: line, Err: err}
+		}
+		if len(idx) > 0 && features > 0 && idx[len(idx)-1] >= features {
+			err := fmt.Errorf("feature index %d greater than number of features %d", idx[len(idx)-1]+1, features)
+			return DataFrame{}, &matrix.ParseError{Format: libsvmFormat, Line: line, Err: err}
+		}
+		if len(idx) > 0 && idx[len(idx)-1]+1 > maxIdx {
+			maxIdx = idx[len(idx)-1] + 1
+		}
+
+		labels = append(labels, label)
+		indices = append(indices, idx)
+		values = append(values, vals)
+	}
+	if err := scanner.Err(); err != nil {
+		return DataFrame{}, fmt.Errorf("error reading a libsvm file: %v", err)
+	}
+
+	if features <= 0 {
+		features = maxIdx
+	}
+	if features == 0 {
+		return DataFrame{}, errors.New("libsvm file contains no features")
+	}
+
+	df := DataFrame{
+		Data: make([]*rowDataFrame, 0, len(labels)),
+	}
+	for n, label := range labels {
+		x, err := matrix.SparseFromTriplets(features, 1, indices[n], make([]int, len(indices[n])), values[n])
+		if err != nil {
+			return DataFrame{}, err
+		}
+
+		y := matrix.Zero(1, 1)
+		y.SetIJ(0, 0, label)
+		df.AppendSparse(x, y)
+	}
+
+	return df, nil
+}
+
+// parseLIBSVMLine возвращает целевую переменную, индексы признаков (начиная с 0) и их значения
+// по полям строки файла LIBSVM.
+func parseLIBSVMLine(fields []string) (float64, []int, []float64, error) {
+	label, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("incorrect label %q", fields[0])
+	}
+
+	indices := make([]int, 0, len(fields)-1)
+	values := make([]float64, 0, len(fields)-1)
+	for _, field := range fields[1:] {
+		name, value, ok := strings.Cut(field, ":")
+		if !ok {
+			return 0, nil, nil, fmt.Errorf("expected index:value, found %q", field)
+		}
+		if name == "qid" {
+			continue
+		}
+
+		idx, err := strconv.Atoi(name)
+		if err != nil || idx <= 0 {
+			return 0, nil, nil, fmt.Errorf("incorrect feature index %q", name)
+		}
+		if len(indices) > 0 && idx-1 <= indices[len(indices)-1] {
+			return 0, nil, nil, fmt.Errorf("feature indexes must be increasing: %d after %d", idx, indices[len(indices)-1]+1)
+		}
+
+		x, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, nil, nil, fmt.Errorf("incorrect value %q of feature %d", value, idx)
+		}
+
+		indices = append(indices, idx-1)
+		values = append(values, x)
+	}
+
+	return label, indices, values, nil
+}
+
+// WriteLIBSVM записывает датафрейм в поток writer в формате LIBSVM (см. ReadLIBSVM) без потери точности
+// и возвращает ошибку, если запись не удалась.
+// Записываются только ненулевые признаки. Целевая переменная каждого наблюдения должна быть матрицей 1 на 1,
+// для целевой переменной, закодированной Num2Vec, метод возвращает ошибку.
+// Метод не открывает и не закрывает поток.
+func (df *DataFrame) WriteLIBSVM(writer io.Writer) error {
+	w := bufio.NewWriter(writer)
+
+	for n, row := range df.Data {
+		if row.y.GetRows() != 1 || row.y.GetColumns() != 1 {
+			return fmt.Errorf("target of record %d is not a number: %d*%d matrix", n, row.y.GetRows(), row.y.GetColumns())
+		}
+		w.WriteString(strconv.FormatFloat(row.y.GetIJ(0, 0), 'g', -1, 64))
+
+		rowIdx, _, values := row.GetSparseX().Triplets()
+		for k, i := range rowIdx {
+			if values[k] == 0 {
+				continue
+			}
+			fmt.Fprintf(w, " %d:%s", i+1, strconv.FormatFloat(values[k], 'g', -1, 64))
+		}
+		w.WriteByte('\n')
+	}
+
+	return w.Flush()
+}
+
+// WriteLIBSVMFile записывает датафрейм в файл filename в формате LIBSVM (см. WriteLIBSVM).
+func (df *DataFrame) WriteLIBSVMFile(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("error creating a libsvm file: %v", err)
+	}
+
+	if err := df.WriteLIBSVM(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,159 @@
+package matrix
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// RawMatrix представляет данные плотной матрицы в том виде, в котором их получает реализация Backend.
+// Элементы хранятся в Data, если DType равен Float64, и в Data32, если DType равен Float32.
+// Элемент i строки, j столбца находится в Data[i*Stride+j] (Data32[i*Stride+j]), строки хранятся друг за другом (row-major).
+// Stride может быть больше Columns, тогда между строками находятся элементы,
+// не принадлежащие матрице, и реализация не должна их изменять.
+type RawMatrix struct {
+	Rows    int       // Количество строк
+	Columns int       // Количество столбцов
+	Stride  int       // Расстояние в элементах между началами соседних строк
+	DType   DType     // Тип элементов
+	Data    []float64 // Данные матрицы с элементами float64
+	Data32  []float32 // Данные матрицы с элементами float32
+}
+
+// Backend интерфейс реализации вычислений над матрицами.
+// Произведения матриц, поэлементные операции (сложение, вычитание, адамарное произведение, ForEach),
+// транспонирование и масштабирование (ScaleInPlace, AddScaledInPlace) структуры Matrix передаются
+// текущей реализации (см. SetBackend), что позволяет подменить вычисления, например на оптимизированные
+// под конкретный процессор, не меняя при этом код нейронной сети.
+// Остальные операции (свертки вроде Sum и Max, softmax, растяжение векторов, заполнение и копирование,
+// операции с разреженными матрицами) выполняются встроенными ядрами пакета.
+// Размерности и типы элементов операндов проверяются пакетом matrix до вызова методов, поэтому реализация
+// может считать их корректными: все операнды одного вызова имеют одинаковый DType,
+// и реализация должна поддерживать как Float64, так и Float32.
+// Реализация должна проходить набор тестов из пакета matrix/backendtest.
+type Backend interface {
+	// Name возвращает имя реализации, под которым она регистрируется.
+	Name() string
+
+	// Gemm прибавляет к c произведение op(a)·op(b), где op(x) равно транспонированной x,
+	// если соответствующий флаг transA или transB равен true, и самой x иначе.
+	// c не разделяет память с a и b.
+	Gemm(transA, transB bool, a, b, c RawMatrix)
+
+	// Add записывает в c поэлементную сумму a и b, c может совпадать с a или b.
+	Add(a, b, c RawMatrix)
+
+	// Sub записывает в c поэлементную разность a и b, c может совпадать с a или b.
+	Sub(a, b, c RawMatrix)
+
+	// HadamardProduct записывает в c поэлементное произведение a и b, c может совпадать с a или b.
+	HadamardProduct(a, b, c RawMatrix)
+
+	// Transpose записывает в c транспонированную a, c не разделяет память с a.
+	Transpose(a, c RawMatrix)
+
+	// ForEach записывает в c результат применения функции f к каждому элементу a, c может совпадать с a.
+	ForEach(f func(float64) float64, a, c RawMatrix)
+
+	// Scale записывает в c матрицу a, умноженную на число k, c может совпадать с a.
+	Scale(k float64, a, c RawMatrix)
+
+	// AddScaled прибавляет к c матрицу a, умноженную на число k, c может совпадать с a.
+	AddScaled(k float64, a, c RawMatrix)
+}
+
+// Реестр реализаций вычислений.
+var (
+	backendsMu sync.RWMutex
+	backends   = make(map[string]Backend)
+	backend    atomic.Pointer[Backend] // текущая реализация
+)
+
+// init регистрирует встроенные реализации и выбирает блочную реализацию по умолчанию.
+func init() {
+	RegisterBackend(naiveBackend{})
+	RegisterBackend(blockedBackend{})
+
+	var b Backend = blockedBackend{}
+	backend.Store(&b)
+}
+
+// RegisterBackend добавляет реализацию вычислений в реестр под именем b.Name().
+// Функция вызывает панику, если реализация с таким именем уже зарегистрирована.
+func RegisterBackend(b Backend) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+
+	if _, ok := backends[b.Name()]; ok {
+		panic(fmt.Sprintf("matrix backend %q already registered", b.Name()))
+	}
+	backends[b.Name()] = b
+}
+
+// SetBackend выбирает зарегистрированную реализацию вычислений с именем name.
+// Все последующие операции над матрицами, входящие в интерфейс Backend, выполняются этой реализацией.
+// Функция возвращает ошибку, если реализация с таким именем не зарегистрирована.
+func SetBackend(name string) error {
+	backendsMu.RLock()
+	b, ok := backends[name]
+	backendsMu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("matrix backend %q not registered", name)
+	}
+
+	backend.Store(&b)
+	return nil
+}
+
+// CurrentBackend возвращает текущую реализацию вычислений.
+func CurrentBackend() Backend {
+	return *backend.Load()
+}
+
+// Backends возвращает отсортированный список имен зарегистрированных реализаций вычислений.
+func Backends() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// raw возвращает представление данных матрицы (структуры myMatrix) для реализации Backend.
+func (M *myMatrix) raw() RawMatrix {
+	return RawMatrix{
+		Rows:    M.rows,
+		Columns: M.columns,
+		Stride:  M.stride,
+		DType:   M.dtype,
+		Data:    M.data,
+		Data32:  M.data32,
+	}
+}
+
+// denseOf возвращает представление r с элементами float64 для внутренних ядер вычислений, разделяющее память с r.
+func denseOf(r RawMatrix) dense[float64] {
+	return dense[float64]{
+		rows:    r.Rows,
+		columns: r.Columns,
+		stride:  r.Stride,
+		data:    r.Data,
+	}
+}
+
+// dense32Of возвращает представление r с элементами float32 для внутренних ядер вычислений, разделяющее память с r.
+func dense32Of(r RawMatrix) dense[float32] {
+	return dense[float32]{
+		rows:    r.Rows,
+		columns: r.Columns,
+		stride:  r.Stride,
+		data:    r.Data32,
+	}
+}
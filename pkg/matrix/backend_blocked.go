@@ -0,0 +1,84 @@
+package matrix
+
+// blockedBackend реализация вычислений по умолчанию.
+// Произведение матриц считается по блокам (см. gemm), а поэлементные операции и транспонирование
+// разбиваются по строкам между исполнителями из набора (см. SetNumWorkers).
+// Матрицы обоих типов элементов обрабатываются одними и теми же ядрами.
+type blockedBackend struct{}
+
+// Name возвращает имя реализации.
+func (blockedBackend) Name() string {
+	return "blocked"
+}
+
+// Gemm прибавляет к c произведение op(a)·op(b).
+func (blockedBackend) Gemm(transA, transB bool, a, b, c RawMatrix) {
+	if c.DType == Float32 {
+		gemm(transA, transB, dense32Of(a), dense32Of(b), dense32Of(c))
+		return
+	}
+	gemm(transA, transB, denseOf(a), denseOf(b), denseOf(c))
+}
+
+// Add записывает в c поэлементную сумму a и b.
+func (blockedBackend) Add(a, b, c RawMatrix) {
+	if c.DType == Float32 {
+		addRows(dense32Of(c), dense32Of(a), dense32Of(b))
+		return
+	}
+	addRows(denseOf(c), denseOf(a), denseOf(b))
+}
+
+// Sub записывает в c поэлементную разность a и b.
+func (blockedBackend) Sub(a, b, c RawMatrix) {
+	if c.DType == Float32 {
+		subRows(dense32Of(c), dense32Of(a), dense32Of(b))
+		return
+	}
+	subRows(denseOf(c), denseOf(a), denseOf(b))
+}
+
+// HadamardProduct записывает в c поэлементное произведение a и b.
+func (blockedBackend) HadamardProduct(a, b, c RawMatrix) {
+	if c.DType == Float32 {
+		mulRows(dense32Of(c), dense32Of(a), dense32Of(b))
+		return
+	}
+	mulRows(denseOf(c), denseOf(a), denseOf(b))
+}
+
+// Transpose записывает в c транспонированную a.
+func (blockedBackend) Transpose(a, c RawMatrix) {
+	if c.DType == Float32 {
+		transposeRows(dense32Of(c), dense32Of(a))
+		return
+	}
+	transposeRows(denseOf(c), denseOf(a))
+}
+
+// ForEach записывает в c результат применения функции f к каждому элементу a.
+func (blockedBackend) ForEach(f func(float64) float64, a, c RawMatrix) {
+	if c.DType == Float32 {
+		applyRows(dense32Of(c), dense32Of(a), f)
+		return
+	}
+	applyRows(denseOf(c), denseOf(a), f)
+}
+
+// Scale записывает в c матрицу a, умноженную на число k.
+func (blockedBackend) Scale(k float64, a, c RawMatrix) {
+	if c.DType == Float32 {
+		scaleRows(dense32Of(c), dense32Of(a), k)
+		return
+	}
+	scaleRows(denseOf(c), denseOf(a), k)
+}
+
+// AddScaled прибавляет к c матрицу a, умноженную на число k.
+func (blockedBackend) AddScaled(k float64, a, c RawMatrix) {
+	if c.DType == Float32 {
+		axpyRows(dense32Of(c), dense32Of(a), k)
+		return
+	}
+	axpyRows(denseOf(c), denseOf(a), k)
+}
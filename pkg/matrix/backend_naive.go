@@ -0,0 +1,119 @@
+package matrix
+
+// naiveBackend простейшая последовательная реализация вычислений по определению операций.
+// Используется как эталон при проверке других реализаций.
+type naiveBackend struct{}
+
+// Name возвращает имя реализации.
+func (naiveBackend) Name() string {
+	return "naive"
+}
+
+// Gemm прибавляет к c произведение op(a)·op(b) по определению произведения матриц.
+func (naiveBackend) Gemm(transA, transB bool, a, b, c RawMatrix) {
+	if c.DType == Float32 {
+		naiveGemm(transA, transB, dense32Of(a), dense32Of(b), dense32Of(c))
+		return
+	}
+	naiveGemm(transA, transB, denseOf(a), denseOf(b), denseOf(c))
+}
+
+// Add записывает в c поэлементную сумму a и b.
+func (naiveBackend) Add(a, b, c RawMatrix) {
+	naiveElementwise(a, b, c, func(x, y float64) float64 { return x + y })
+}
+
+// Sub записывает в c поэлементную разность a и b.
+func (naiveBackend) Sub(a, b, c RawMatrix) {
+	naiveElementwise(a, b, c, func(x, y float64) float64 { return x - y })
+}
+
+// HadamardProduct записывает в c поэлементное произведение a и b.
+func (naiveBackend) HadamardProduct(a, b, c RawMatrix) {
+	naiveElementwise(a, b, c, func(x, y float64) float64 { return x * y })
+}
+
+// Transpose записывает в c транспонированную a.
+func (naiveBackend) Transpose(a, c RawMatrix) {
+	if c.DType == Float32 {
+		naiveTranspose(dense32Of(a), dense32Of(c))
+		return
+	}
+	naiveTranspose(denseOf(a), denseOf(c))
+}
+
+// ForEach записывает в c результат применения функции f к каждому элементу a.
+func (naiveBackend) ForEach(f func(float64) float64, a, c RawMatrix) {
+	naiveElementwise(a, a, c, func(x, _ float64) float64 { return f(x) })
+}
+
+// Scale записывает в c матрицу a, умноженную на число k.
+func (naiveBackend) Scale(k float64, a, c RawMatrix) {
+	naiveElementwise(a, a, c, func(x, _ float64) float64 { return x * k })
+}
+
+// AddScaled прибавляет к c матрицу a, умноженную на число k.
+func (naiveBackend) AddScaled(k float64, a, c RawMatrix) {
+	naiveElementwise(a, c, c, func(x, y float64) float64 { return y + x*k })
+}
+
+// naiveGemm прибавляет к c произведение op(a)·op(b) по определению, сумма накапливается в float64.
+func naiveGemm[T float](transA, transB bool, a, b, c dense[T]) {
+	k := a.columns
+	if transA {
+		k = a.rows
+	}
+
+	for i := 0; i < c.rows; i++ {
+		for j := 0; j < c.columns; j++ {
+			sum := float64(0)
+
+			for l := 0; l < k; l++ {
+				var x, y T
+				if transA {
+					x = a.data[l*a.stride+i]
+				} else {
+					x = a.data[i*a.stride+l]
+				}
+
+				if transB {
+					y = b.data[j*b.stride+l]
+				} else {
+					y = b.data[l*b.stride+j]
+				}
+
+				sum += float64(x) * float64(y)
+			}
+
+			c.data[i*c.stride+j] += T(sum)
+		}
+	}
+}
+
+// naiveElementwise записывает в c значения f над соответствующими элементами a и b,
+// вычисленные в float64.
+func naiveElementwise(a, b, c RawMatrix, f func(x, y float64) float64) {
+	if c.DType == Float32 {
+		naiveApply(dense32Of(a), dense32Of(b), dense32Of(c), f)
+		return
+	}
+	naiveApply(denseOf(a), denseOf(b), denseOf(c), f)
+}
+
+// naiveApply записывает в c значения f над соответствующими элементами a и b.
+func naiveApply[T float](a, b, c dense[T], f func(x, y float64) float64) {
+	for i := 0; i < c.rows; i++ {
+		for j := 0; j < c.columns; j++ {
+			c.data[i*c.stride+j] = T(f(float64(a.data[i*a.stride+j]), float64(b.data[i*b.stride+j])))
+		}
+	}
+}
+
+// naiveTranspose записывает в c транспонированную a.
+func naiveTranspose[T float](a, c dense[T]) {
+	for i := 0; i < a.rows; i++ {
+		for j := 0; j < a.columns; j++ {
+			c.data[j*c.stride+i] = a.data[i*a.stride+j]
+		}
+	}
+}
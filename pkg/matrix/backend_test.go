@@ -0,0 +1,122 @@
+package matrix_test
+
+import (
+	"testing"
+
+	"github.com/Davmgiz/GoblinNeuronet/pkg/matrix"
+	"github.com/Davmgiz/GoblinNeuronet/pkg/matrix/backendtest"
+)
+
+// TestBackends прогоняет набор тестов пакета backendtest для каждой зарегистрированной реализации вычислений.
+func TestBackends(t *testing.T) {
+	for _, name := range matrix.Backends() {
+		if err := matrix.SetBackend(name); err != nil {
+			t.Fatal(err)
+		}
+
+		t.Run(name, func(t *testing.T) {
+			backendtest.Run(t, matrix.CurrentBackend())
+		})
+	}
+
+	if err := matrix.SetBackend("blocked"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestSetBackend проверяет выбор реализации вычислений по имени
+// и ошибку при выборе незарегистрированной реализации.
+func TestSetBackend(t *testing.T) {
+	defer matrix.SetBackend("blocked")
+
+	if err := matrix.SetBackend("naive"); err != nil {
+		t.Fatal(err)
+	}
+
+	if matrix.CurrentBackend().Name() != "naive" {
+		t.Errorf("Expected backend naive, got %s", matrix.CurrentBackend().Name())
+	}
+
+	A := matrix.DataToMatrix([][]float64{{1., 2.}, {3., 4.}})
+	expected := matrix.DataToMatrix([][]float64{{7., 10.}, {15., 22.}})
+
+	if !matrix.IsMatrixesEqual(A.Dot(A), expected) {
+		t.Errorf("Matrix multiplication with naive backend error: Result != Expected")
+	}
+
+	if err := matrix.SetBackend("unknown"); err == nil {
+		t.Errorf("Expected error for unregistered backend")
+	}
+
+	if matrix.CurrentBackend().Name() != "naive" {
+		t.Errorf("Backend must not change after error")
+	}
+}
+
+// countingBackend реализация вычислений для тестов, которая считает вызовы по типам элементов
+// и передает вычисления встроенной реализации.
+type countingBackend struct {
+	matrix.Backend
+	calls map[matrix.DType]int
+}
+
+func (b *countingBackend) Name() string { return "test.counting" }
+
+func (b *countingBackend) Gemm(transA, transB bool, a, c, d matrix.RawMatrix) {
+	b.calls[d.DType]++
+	b.Backend.Gemm(transA, transB, a, c, d)
+}
+
+func (b *countingBackend) Add(a, c, d matrix.RawMatrix) {
+	b.calls[d.DType]++
+	b.Backend.Add(a, c, d)
+}
+
+func (b *countingBackend) ForEach(f func(float64) float64, a, c matrix.RawMatrix) {
+	b.calls[c.DType]++
+	b.Backend.ForEach(f, a, c)
+}
+
+func (b *countingBackend) Transpose(a, c matrix.RawMatrix) {
+	b.calls[c.DType]++
+	b.Backend.Transpose(a, c)
+}
+
+func (b *countingBackend) AddScaled(k float64, a, c matrix.RawMatrix) {
+	b.calls[c.DType]++
+	b.Backend.AddScaled(k, a, c)
+}
+
+// counting реализация вычислений для тестов, регистрируется один раз на весь запуск тестов.
+var counting = &countingBackend{Backend: matrix.CurrentBackend(), calls: make(map[matrix.DType]int)}
+
+func init() {
+	matrix.RegisterBackend(counting)
+}
+
+// TestBackendDTypes проверяет, что операции над матрицами обоих типов элементов
+// передаются выбранной реализации вычислений.
+func TestBackendDTypes(t *testing.T) {
+	defer matrix.SetBackend("blocked")
+
+	b := counting
+	b.calls = make(map[matrix.DType]int)
+	if err := matrix.SetBackend(b.Name()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dtype := range []matrix.DType{matrix.Float64, matrix.Float32} {
+		A := matrix.DataToMatrix([][]float64{{1., 2.}, {3., 4.}}).AsType(dtype)
+		expected := matrix.DataToMatrix([][]float64{{7., 10.}, {15., 22.}})
+
+		if !matrix.IsMatrixesEqual(A.Dot(A), expected) {
+			t.Errorf("Matrix multiplication (%v) with registered backend error: Result != Expected", dtype)
+		}
+		A.Add(A).T().ForEach(func(x float64) float64 { return -x })
+		A.AddScaledInPlace(0.5, A)
+
+		if b.calls[dtype] != 5 {
+			t.Errorf("Expected 5 calls of registered backend for %v matrixes, got %d", dtype, b.calls[dtype])
+		}
+	}
+}
@@ -0,0 +1,372 @@
+/*
+Package backendtest содержит набор тестов, который должна проходить любая реализация
+вычислений над матрицами (интерфейс matrix.Backend).
+Тесты сравнивают результаты реализации с вычислениями по определению операций
+на матрицах с элементами float64 и float32 разных размеров, в том числе не кратных размерам блоков,
+с транспонированием операндов, с совпадающими операндами и результатом, а также на матрицах
+с шагом строк больше количества столбцов.
+
+Пример использования в тестах пакета с собственной реализацией:
+
+	func TestMyBackend(t *testing.T) {
+		backendtest.Run(t, MyBackend{})
+	}
+*/
+package backendtest
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/Davmgiz/GoblinNeuronet/pkg/matrix"
+)
+
+// sentinel значение, которым заполняются элементы между строками матриц с шагом больше количества столбцов.
+// Реализация не должна изменять эти элементы.
+const sentinel = 12345.
+
+// epsilon радиус окрестности допущения для вещественных чисел (относительно 1 + |ожидаемое значение|)
+// для матриц с элементами float64.
+const epsilon = 1e-9
+
+// epsilon32 радиус окрестности допущения для матриц с элементами float32:
+// ожидаемый результат вычисляется в float64, реализация может накапливать суммы в float32.
+const epsilon32 = 1e-4
+
+// dtypes типы элементов, на которых проверяется реализация.
+var dtypes = []matrix.DType{matrix.Float64, matrix.Float32}
+
+// shapes размеры (m, k, n) произведений, на которых проверяется реализация.
+var shapes = [][3]int{
+	{1, 1, 1},
+	{3, 2, 4},
+	{30, 784, 1},
+	{30, 1, 784},
+	{1, 50, 30},
+	{70, 300, 65},
+	{130, 17, 260},
+}
+
+// Run запускает полный набор тестов для реализации b.
+func Run(t *testing.T, b matrix.Backend) {
+	for _, dtype := range dtypes {
+		dtype := dtype
+		t.Run(dtype.String(), func(t *testing.T) {
+			t.Run("Gemm", func(t *testing.T) { testGemm(t, b, dtype) })
+			t.Run("Add", func(t *testing.T) {
+				testElementwise(t, b, dtype, "Add", b.Add, func(x, y float64) float64 { return x + y })
+			})
+			t.Run("Sub", func(t *testing.T) {
+				testElementwise(t, b, dtype, "Sub", b.Sub, func(x, y float64) float64 { return x - y })
+			})
+			t.Run("HadamardProduct", func(t *testing.T) {
+				testElementwise(t, b, dtype, "HadamardProduct", b.HadamardProduct, func(x, y float64) float64 { return x * y })
+			})
+			t.Run("Transpose", func(t *testing.T) { testTranspose(t, b, dtype) })
+			t.Run("ForEach", func(t *testing.T) { testForEach(t, b, dtype) })
+			t.Run("Scale", func(t *testing.T) { testScale(t, b, dtype) })
+			t.Run("AddScaled", func(t *testing.T) { testAddScaled(t, b, dtype) })
+		})
+	}
+}
+
+// newRaw возвращает матрицу rows на columns с типом элементов dtype и случайными элементами.
+// Если pad больше 0, то шаг строк матрицы равен columns+pad, а элементы между строками равны sentinel.
+func newRaw(rng *rand.Rand, dtype matrix.DType, rows, columns, pad int) matrix.RawMatrix {
+	stride := columns + pad
+	r := matrix.RawMatrix{
+		Rows:    rows,
+		Columns: columns,
+		Stride:  stride,
+		DType:   dtype,
+	}
+	if dtype == matrix.Float32 {
+		r.Data32 = make([]float32, rows*stride)
+	} else {
+		r.Data = make([]float64, rows*stride)
+	}
+
+	for n := 0; n < rows*stride; n++ {
+		if n%stride < columns {
+			setOffset(r, n, rng.NormFloat64())
+		} else {
+			setOffset(r, n, sentinel)
+		}
+	}
+
+	return r
+}
+
+// clone возвращает копию матрицы r вместе с элементами между строками.
+func clone(r matrix.RawMatrix) matrix.RawMatrix {
+	r.Data = append([]float64(nil), r.Data...)
+	r.Data32 = append([]float32(nil), r.Data32...)
+
+	return r
+}
+
+// length возвращает количество элементов данных матрицы r вместе с элементами между строками.
+func length(r matrix.RawMatrix) int {
+	if r.DType == matrix.Float32 {
+		return len(r.Data32)
+	}
+	return len(r.Data)
+}
+
+// offset возвращает элемент данных матрицы r с номером n.
+func offset(r matrix.RawMatrix, n int) float64 {
+	if r.DType == matrix.Float32 {
+		return float64(r.Data32[n])
+	}
+	return r.Data[n]
+}
+
+// setOffset записывает x в элемент данных матрицы r с номером n (для float32 с округлением).
+func setOffset(r matrix.RawMatrix, n int, x float64) {
+	if r.DType == matrix.Float32 {
+		r.Data32[n] = float32(x)
+		return
+	}
+	r.Data[n] = x
+}
+
+// at возвращает элемент i строки, j столбца матрицы r.
+func at(r matrix.RawMatrix, i, j int) float64 {
+	return offset(r, i*r.Stride+j)
+}
+
+// set записывает x в элемент i строки, j столбца матрицы r.
+func set(r matrix.RawMatrix, i, j int, x float64) {
+	setOffset(r, i*r.Stride+j, x)
+}
+
+// check сравнивает матрицу got с ожидаемой матрицей want и проверяет,
+// что элементы между строками got не изменены.
+func check(t *testing.T, name string, got, want matrix.RawMatrix) {
+	t.Helper()
+
+	eps := epsilon
+	if got.DType == matrix.Float32 {
+		eps = epsilon32
+	}
+
+	for n := 0; n < length(got); n++ {
+		g, w := offset(got, n), offset(want, n)
+		if n%got.Stride >= got.Columns {
+			if g != sentinel {
+				t.Errorf("%s: element outside the matrix at offset %d was modified", name, n)
+				return
+			}
+			continue
+		}
+
+		if math.Abs(g-w) > eps*(1+math.Abs(w)) {
+			t.Errorf("%s: element (%d, %d) = %v, expected %v", name, n/got.Stride, n%got.Stride, g, w)
+			return
+		}
+	}
+}
+
+// testGemm проверяет произведение матриц со всеми вариантами транспонирования операндов.
+// Результат прибавляется к ненулевой матрице c, что проверяет накопление результата.
+func testGemm(t *testing.T, b matrix.Backend, dtype matrix.DType) {
+	rng := rand.New(rand.NewSource(1))
+
+	for _, shape := range shapes {
+		m, k, n := shape[0], shape[1], shape[2]
+
+		for _, transA := range []bool{false, true} {
+			for _, transB := range []bool{false, true} {
+				for _, pad := range []int{0, 3} {
+					var A, B matrix.RawMatrix
+					if transA {
+						A = newRaw(rng, dtype, k, m, pad)
+					} else {
+						A = newRaw(rng, dtype, m, k, pad)
+					}
+					if transB {
+						B = newRaw(rng, dtype, n, k, pad)
+					} else {
+						B = newRaw(rng, dtype, k, n, pad)
+					}
+
+					C := newRaw(rng, dtype, m, n, pad)
+
+					// ожидаемый результат по определению
+					want := clone(C)
+					for i := 0; i < m; i++ {
+						for j := 0; j < n; j++ {
+							sum := 0.
+							for l := 0; l < k; l++ {
+								var x, y float64
+								if transA {
+									x = at(A, l, i)
+								} else {
+									x = at(A, i, l)
+								}
+								if transB {
+									y = at(B, j, l)
+								} else {
+									y = at(B, l, j)
+								}
+								sum += x * y
+							}
+							set(want, i, j, at(want, i, j)+sum)
+						}
+					}
+
+					b.Gemm(transA, transB, A, B, C)
+
+					check(t, "Gemm", C, want)
+				}
+			}
+		}
+	}
+}
+
+// testElementwise проверяет поэлементную операцию op, результат которой для элементов x и y равен f(x, y).
+// Операция проверяется с отдельной матрицей результата и с результатом, совпадающим с каждым из операндов.
+func testElementwise(t *testing.T, b matrix.Backend, dtype matrix.DType, name string, op func(a, b, c matrix.RawMatrix), f func(x, y float64) float64) {
+	rng := rand.New(rand.NewSource(2))
+
+	for _, shape := range shapes {
+		rows, columns := shape[0], shape[2]
+
+		for _, pad := range []int{0, 2} {
+			A := newRaw(rng, dtype, rows, columns, pad)
+			B := newRaw(rng, dtype, rows, columns, pad)
+
+			want := clone(A)
+			for i := 0; i < rows; i++ {
+				for j := 0; j < columns; j++ {
+					set(want, i, j, f(at(A, i, j), at(B, i, j)))
+				}
+			}
+
+			C := newRaw(rng, dtype, rows, columns, pad)
+			op(A, B, C)
+			check(t, name, C, want)
+
+			inA := clone(A)
+			op(inA, B, inA)
+			check(t, name+" into first operand", inA, want)
+
+			inB := clone(B)
+			op(A, inB, inB)
+			check(t, name+" into second operand", inB, want)
+		}
+	}
+}
+
+// testTranspose проверяет транспонирование матриц.
+func testTranspose(t *testing.T, b matrix.Backend, dtype matrix.DType) {
+	rng := rand.New(rand.NewSource(3))
+
+	for _, shape := range shapes {
+		rows, columns := shape[0], shape[1]
+
+		for _, pad := range []int{0, 1} {
+			A := newRaw(rng, dtype, rows, columns, pad)
+			C := newRaw(rng, dtype, columns, rows, pad)
+
+			want := clone(C)
+			for i := 0; i < rows; i++ {
+				for j := 0; j < columns; j++ {
+					set(want, j, i, at(A, i, j))
+				}
+			}
+
+			b.Transpose(A, C)
+			check(t, "Transpose", C, want)
+		}
+	}
+}
+
+// testForEach проверяет поэлементное применение функции с отдельной матрицей результата и на месте.
+func testForEach(t *testing.T, b matrix.Backend, dtype matrix.DType) {
+	rng := rand.New(rand.NewSource(4))
+
+	f := func(x float64) float64 {
+		return 1. / (1. + math.Exp(-x))
+	}
+
+	for _, shape := range shapes {
+		rows, columns := shape[0], shape[2]
+
+		for _, pad := range []int{0, 2} {
+			A := newRaw(rng, dtype, rows, columns, pad)
+
+			want := clone(A)
+			for i := 0; i < rows; i++ {
+				for j := 0; j < columns; j++ {
+					set(want, i, j, f(at(A, i, j)))
+				}
+			}
+
+			C := newRaw(rng, dtype, rows, columns, pad)
+			b.ForEach(f, A, C)
+			check(t, "ForEach", C, want)
+
+			b.ForEach(f, A, A)
+			check(t, "ForEach in place", A, want)
+		}
+	}
+}
+
+// testScale проверяет умножение матрицы на число с отдельной матрицей результата и на месте.
+func testScale(t *testing.T, b matrix.Backend, dtype matrix.DType) {
+	rng := rand.New(rand.NewSource(5))
+
+	for _, shape := range shapes {
+		rows, columns := shape[0], shape[2]
+
+		for _, pad := range []int{0, 2} {
+			A := newRaw(rng, dtype, rows, columns, pad)
+			k := rng.NormFloat64()
+
+			want := clone(A)
+			for i := 0; i < rows; i++ {
+				for j := 0; j < columns; j++ {
+					set(want, i, j, at(A, i, j)*k)
+				}
+			}
+
+			C := newRaw(rng, dtype, rows, columns, pad)
+			b.Scale(k, A, C)
+			check(t, "Scale", C, want)
+
+			b.Scale(k, A, A)
+			check(t, "Scale in place", A, want)
+		}
+	}
+}
+
+// testAddScaled проверяет прибавление матрицы, умноженной на число, к отдельной матрице и к самой себе.
+func testAddScaled(t *testing.T, b matrix.Backend, dtype matrix.DType) {
+	rng := rand.New(rand.NewSource(6))
+
+	for _, shape := range shapes {
+		rows, columns := shape[0], shape[2]
+
+		for _, pad := range []int{0, 2} {
+			A := newRaw(rng, dtype, rows, columns, pad)
+			C := newRaw(rng, dtype, rows, columns, pad)
+			k := rng.NormFloat64()
+
+			want, wantSelf := clone(C), clone(A)
+			for i := 0; i < rows; i++ {
+				for j := 0; j < columns; j++ {
+					set(want, i, j, at(C, i, j)+at(A, i, j)*k)
+					set(wantSelf, i, j, at(A, i, j)+at(A, i, j)*k)
+				}
+			}
+
+			b.AddScaled(k, A, C)
+			check(t, "AddScaled", C, want)
+
+			b.AddScaled(k, A, A)
+			check(t, "AddScaled into operand", A, wantSelf)
+		}
+	}
+}
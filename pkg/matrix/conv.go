@@ -0,0 +1,212 @@
+package matrix
+
+/*
+Свертка и пулинг изображений.
+Изображение с Channels каналами размера Height на Width хранится в матрице размерности Channels на Height*Width:
+i строка матрицы является i каналом, элементы канала хранятся по строкам изображения,
+то есть пиксель y, x канала c находится в элементе c, y*Width+x.
+Результат свертки и пулинга хранится так же, с размером OutHeight на OutWidth.
+Фильтры свертки хранятся в матрице размерности filters на Channels*KernelHeight*KernelWidth,
+i строка которой является i фильтром с элементами в порядке канал, строка окна, столбец окна.
+Функции вызывают панику при некорректных параметрах или размерностях операндов,
+функции с префиксом Try возвращают вместо этого ошибку.
+*/
+
+// Conv2DShape параметры свертки или пулинга изображения.
+type Conv2DShape struct {
+	Channels     int // Количество каналов входного изображения
+	Height       int // Высота входного изображения
+	Width        int // Ширина входного изображения
+	KernelHeight int // Высота окна (ядра свертки)
+	KernelWidth  int // Ширина окна (ядра свертки)
+	StrideH      int // Шаг окна по вертикали
+	StrideW      int // Шаг окна по горизонтали
+	PadH         int // Количество строк нулей, добавляемых сверху и снизу изображения
+	PadW         int // Количество столбцов нулей, добавляемых слева и справа изображения
+}
+
+// OutHeight возвращает высоту результата свертки или пулинга.
+func (s Conv2DShape) OutHeight() int {
+	return outSize(s.Height, s.KernelHeight, s.StrideH, s.PadH)
+}
+
+// OutWidth возвращает ширину результата свертки или пулинга.
+func (s Conv2DShape) OutWidth() int {
+	return outSize(s.Width, s.KernelWidth, s.StrideW, s.PadW)
+}
+
+// Im2Col возвращает развертку изображения X размерности Channels*KernelHeight*KernelWidth на OutHeight*OutWidth:
+// j столбец содержит элементы j положения окна (с нулями дополнения) в том же порядке, что и элементы фильтра,
+// поэтому свертка сводится к произведению матрицы фильтров на развертку.
+func Im2Col(X Matrix, s Conv2DShape) Matrix {
+	must(s.checkImage("Im2Col", X.matrix, s.Channels, s.Height*s.Width))
+
+	return Matrix{
+		matrix: im2col(s, X.matrix),
+	}
+}
+
+// Col2Im выполняет преобразование, сопряженное Im2Col: возвращает изображение размерности Channels на Height*Width,
+// каждый элемент которого равен сумме элементов развертки C, соответствующих этому элементу.
+// Элементы, соответствующие дополнению, отбрасываются.
+func Col2Im(C Matrix, s Conv2DShape) Matrix {
+	must(s.checkImage("Col2Im", C.matrix, s.Channels*s.KernelHeight*s.KernelWidth, s.OutHeight()*s.OutWidth()))
+
+	X := col2im(s, C.matrix)
+	debugCheck("Col2Im", X)
+
+	return Matrix{
+		matrix: X,
+	}
+}
+
+// Conv2D возвращает свертку (взаимную корреляцию, как в сверточных нейронных сетях) изображения X
+// с фильтрами K: матрицу размерности filters на OutHeight*OutWidth, i строка которой является откликом i фильтра.
+// Смещения фильтров можно добавить методом AddBroadcast.
+func Conv2D(X, K Matrix, s Conv2DShape) Matrix {
+	must(checkConv2D("Conv2D", X.matrix, K.matrix, s))
+
+	return K.Dot(Matrix{matrix: im2col(s, X.matrix)})
+}
+
+// Conv2DBackward возвращает градиенты по изображению dX и по фильтрам dK свертки Conv2D(X, K, s)
+// по градиенту dY по ее результату.
+func Conv2DBackward(X, K, dY Matrix, s Conv2DShape) (Matrix, Matrix) {
+	must(checkConv2DBackward(X.matrix, K.matrix, dY.matrix, s))
+
+	cols := Matrix{matrix: im2col(s, X.matrix)}
+	dK := dY.DotT(cols)
+	dX := Matrix{matrix: col2im(s, K.TDot(dY).matrix)}
+	debugCheck("Conv2DBackward", dX.matrix)
+
+	return dX, dK
+}
+
+// MaxPool2D возвращает результат max пулинга изображения X: матрицу размерности Channels на OutHeight*OutWidth
+// наибольших элементов каждого окна, и индексы наибольших элементов в каналах X (y*Width+x)
+// для каждого элемента результата в порядке строк, которые нужны MaxPool2DBackward.
+// Дополнение не участвует в поиске наибольшего элемента.
+func MaxPool2D(X Matrix, s Conv2DShape) (Matrix, []int) {
+	must(s.checkImage("MaxPool2D", X.matrix, s.Channels, s.Height*s.Width))
+
+	Y, argmax := maxPool(s, X.matrix)
+	debugCheck("MaxPool2D", Y)
+
+	return Matrix{matrix: Y}, argmax
+}
+
+// MaxPool2DBackward возвращает градиент по изображению max пулинга по градиенту dY по его результату
+// и индексам наибольших элементов argmax, возвращенным MaxPool2D:
+// градиент передается только наибольшим элементам окон.
+func MaxPool2DBackward(dY Matrix, argmax []int, s Conv2DShape) Matrix {
+	must(checkMaxPoolBackward(dY.matrix, argmax, s))
+
+	return Matrix{
+		matrix: maxPoolBackward(s, dY.matrix, argmax),
+	}
+}
+
+// AvgPool2D возвращает результат усредняющего пулинга изображения X: матрицу размерности
+// Channels на OutHeight*OutWidth сумм элементов каждого окна, деленных на площадь окна.
+// Дополнение считается нулями.
+func AvgPool2D(X Matrix, s Conv2DShape) Matrix {
+	must(s.checkImage("AvgPool2D", X.matrix, s.Channels, s.Height*s.Width))
+
+	Y := avgPool(s, X.matrix)
+	debugCheck("AvgPool2D", Y)
+
+	return Matrix{
+		matrix: Y,
+	}
+}
+
+// AvgPool2DBackward возвращает градиент по изображению усредняющего пулинга по градиенту dY по его результату.
+func AvgPool2DBackward(dY Matrix, s Conv2DShape) Matrix {
+	must(s.checkImage("AvgPool2DBackward", dY.matrix, s.Channels, s.OutHeight()*s.OutWidth()))
+
+	return Matrix{
+		matrix: avgPoolBackward(s, dY.matrix),
+	}
+}
+
+// TryIm2Col работает так же, как Im2Col, но возвращает ошибку при некорректных параметрах
+// и *ShapeError, если размерность X не соответствует параметрам.
+func TryIm2Col(X Matrix, s Conv2DShape) (Matrix, error) {
+	if err := s.checkImage("Im2Col", X.matrix, s.Channels, s.Height*s.Width); err != nil {
+		return Matrix{}, err
+	}
+
+	return Im2Col(X, s), nil
+}
+
+// TryCol2Im работает так же, как Col2Im, но возвращает ошибку при некорректных параметрах
+// и *ShapeError, если размерность C не соответствует параметрам.
+func TryCol2Im(C Matrix, s Conv2DShape) (Matrix, error) {
+	if err := s.checkImage("Col2Im", C.matrix, s.Channels*s.KernelHeight*s.KernelWidth, s.OutHeight()*s.OutWidth()); err != nil {
+		return Matrix{}, err
+	}
+
+	return Col2Im(C, s), nil
+}
+
+// TryConv2D работает так же, как Conv2D, но возвращает ошибку при некорректных параметрах,
+// *ShapeError, если размерности X или K не соответствуют параметрам, и *DTypeError, если различаются типы элементов.
+func TryConv2D(X, K Matrix, s Conv2DShape) (Matrix, error) {
+	if err := checkConv2D("Conv2D", X.matrix, K.matrix, s); err != nil {
+		return Matrix{}, err
+	}
+
+	return Conv2D(X, K, s), nil
+}
+
+// TryConv2DBackward работает так же, как Conv2DBackward, но возвращает ошибку при некорректных параметрах,
+// *ShapeError, если размерности X, K или dY не соответствуют параметрам, и *DTypeError, если различаются типы элементов.
+func TryConv2DBackward(X, K, dY Matrix, s Conv2DShape) (Matrix, Matrix, error) {
+	if err := checkConv2DBackward(X.matrix, K.matrix, dY.matrix, s); err != nil {
+		return Matrix{}, Matrix{}, err
+	}
+
+	dX, dK := Conv2DBackward(X, K, dY, s)
+	return dX, dK, nil
+}
+
+// TryMaxPool2D работает так же, как MaxPool2D, но возвращает ошибку при некорректных параметрах
+// и *ShapeError, если размерность X не соответствует параметрам.
+func TryMaxPool2D(X Matrix, s Conv2DShape) (Matrix, []int, error) {
+	if err := s.checkImage("MaxPool2D", X.matrix, s.Channels, s.Height*s.Width); err != nil {
+		return Matrix{}, nil, err
+	}
+
+	Y, argmax := MaxPool2D(X, s)
+	return Y, argmax, nil
+}
+
+// TryMaxPool2DBackward работает так же, как MaxPool2DBackward, но возвращает ошибку при некорректных параметрах
+// или индексах и *ShapeError, если размерность dY не соответствует параметрам.
+func TryMaxPool2DBackward(dY Matrix, argmax []int, s Conv2DShape) (Matrix, error) {
+	if err := checkMaxPoolBackward(dY.matrix, argmax, s); err != nil {
+		return Matrix{}, err
+	}
+
+	return MaxPool2DBackward(dY, argmax, s), nil
+}
+
+// TryAvgPool2D работает так же, как AvgPool2D, но возвращает ошибку при некорректных параметрах
+// и *ShapeError, если размерность X не соответствует параметрам.
+func TryAvgPool2D(X Matrix, s Conv2DShape) (Matrix, error) {
+	if err := s.checkImage("AvgPool2D", X.matrix, s.Channels, s.Height*s.Width); err != nil {
+		return Matrix{}, err
+	}
+
+	return AvgPool2D(X, s), nil
+}
+
+// TryAvgPool2DBackward работает так же, как AvgPool2DBackward, но возвращает ошибку при некорректных параметрах
+// и *ShapeError, если размерность dY не соответствует параметрам.
+func TryAvgPool2DBackward(dY Matrix, s Conv2DShape) (Matrix, error) {
+	if err := s.checkImage("AvgPool2DBackward", dY.matrix, s.Channels, s.OutHeight()*s.OutWidth()); err != nil {
+		return Matrix{}, err
+	}
+
+	return AvgPool2DBackward(dY, s), nil
+}
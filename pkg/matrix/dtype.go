@@ -0,0 +1,44 @@
+package matrix
+
+import "fmt"
+
+// DType тип элементов матрицы (точность вычислений).
+type DType int
+
+const (
+	Float64 DType = iota // элементы float64, используется по умолчанию
+	Float32              // элементы float32, вдвое меньше памяти при меньшей точности
+)
+
+// String возвращает имя типа элементов, которое используется при записи матриц.
+func (dtype DType) String() string {
+	switch dtype {
+	case Float64:
+		return "float64"
+	case Float32:
+		return "float32"
+	default:
+		return fmt.Sprintf("DType(%d)", int(dtype))
+	}
+}
+
+// Size возвращает размер одного элемента в байтах.
+func (dtype DType) Size() int {
+	if dtype == Float32 {
+		return 4
+	}
+	return 8
+}
+
+// ParseDType возвращает тип элементов по его имени (см. String)
+// и ошибку, если имени не соответствует никакой тип.
+func ParseDType(name string) (DType, error) {
+	switch name {
+	case "float64":
+		return Float64, nil
+	case "float32":
+		return Float32, nil
+	default:
+		return Float64, fmt.Errorf("unknown matrix element type %q", name)
+	}
+}
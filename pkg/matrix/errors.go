@@ -0,0 +1,241 @@
+package matrix
+
+// файл содержит ошибки операций над матрицами и проверки операндов
+
+import "fmt"
+
+// Shape размерность матрицы.
+type Shape struct {
+	Rows    int // Количество строк
+	Columns int // Количество столбцов
+}
+
+// ShapeError ошибка несовместимых размерностей операндов операции над матрицами.
+// Возвращается методами с префиксом Try вместо паники.
+type ShapeError struct {
+	Op string // Имя операции
+	A  Shape  // Размерность первого операнда
+	B  Shape  // Размерность второго операнда, нулевая если операнд один
+}
+
+// Error возвращает описание ошибки.
+func (e *ShapeError) Error() string {
+	if e.B == (Shape{}) {
+		return fmt.Sprintf("incorrect dimension for %s: %d*%d", e.Op, e.A.Rows, e.A.Columns)
+	}
+	return fmt.Sprintf("incorrect dimension for %s: %d*%d and %d*%d", e.Op, e.A.Rows, e.A.Columns, e.B.Rows, e.B.Columns)
+}
+
+// DTypeError ошибка различных типов элементов операндов операции над матрицами.
+// Возвращается методами с префиксом Try вместо паники.
+type DTypeError struct {
+	Op string // Имя операции
+	A  DType  // Тип элементов первого операнда
+	B  DType  // Тип элементов второго операнда
+}
+
+// Error возвращает описание ошибки.
+func (e *DTypeError) Error() string {
+	return fmt.Sprintf("different element types for %s: %v and %v", e.Op, e.A, e.B)
+}
+
+// shape возвращает размерность матрицы (структуры myMatrix).
+func (M *myMatrix) shape() Shape {
+	return Shape{Rows: M.rows, Columns: M.columns}
+}
+
+// shape возвращает размерность разреженной матрицы (структуры mySparse).
+func (S *mySparse) shape() Shape {
+	return Shape{Rows: S.rows, Columns: S.columns}
+}
+
+// checkDims возвращает *ShapeError, если размеры rows и columns не положительны.
+func checkDims(op string, rows, columns int) error {
+	if rows <= 0 || columns <= 0 {
+		return &ShapeError{Op: op, A: Shape{Rows: rows, Columns: columns}}
+	}
+	return nil
+}
+
+// checkDType возвращает *DTypeError, если типы элементов матриц A и B различаются.
+func checkDType(op string, A, B *myMatrix) error {
+	if A.dtype != B.dtype {
+		return &DTypeError{Op: op, A: A.dtype, B: B.dtype}
+	}
+	return nil
+}
+
+// checkSameShape возвращает *ShapeError, если размерности матриц A и B не равны,
+// и *DTypeError, если различаются типы элементов.
+func checkSameShape(op string, A, B *myMatrix) error {
+	if A.rows != B.rows || A.columns != B.columns {
+		return &ShapeError{Op: op, A: A.shape(), B: B.shape()}
+	}
+	return checkDType(op, A, B)
+}
+
+// checkProduct возвращает *ShapeError, если матрицы op(A) и op(B) нельзя перемножить,
+// где op транспонирует матрицу при transA (transB) равном true,
+// и *DTypeError, если различаются типы элементов.
+func checkProduct(op string, transA, transB bool, A, B *myMatrix) error {
+	inner, outer := A.columns, B.rows
+	if transA {
+		inner = A.rows
+	}
+	if transB {
+		outer = B.columns
+	}
+
+	if inner != outer {
+		return &ShapeError{Op: op, A: A.shape(), B: B.shape()}
+	}
+	return checkDType(op, A, B)
+}
+
+// checkData возвращает *ShapeError, если слайс arr пуст или его строки имеют разную длину.
+// В ошибке A размерность первой строки, B размерность первой строки с отличной длиной.
+func checkData(arr [][]float64) error {
+	if len(arr) == 0 || len(arr[0]) == 0 {
+		return &ShapeError{Op: "DataToMatrix", A: Shape{Rows: len(arr)}}
+	}
+
+	for i := 1; i < len(arr); i++ {
+		if len(arr[i]) != len(arr[0]) {
+			return &ShapeError{Op: "DataToMatrix", A: Shape{Rows: 1, Columns: len(arr[0])}, B: Shape{Rows: 1, Columns: len(arr[i])}}
+		}
+	}
+	return nil
+}
+
+// checkSlice возвращает *ShapeError, если количество элементов матрицы M не равно длине слайса slc.
+// В ошибке слайс представлен строкой.
+func (M *myMatrix) checkSlice(slc []float64) error {
+	if M.rows*M.columns != len(slc) {
+		return &ShapeError{Op: "Slice2Matrix", A: M.shape(), B: Shape{Rows: 1, Columns: len(slc)}}
+	}
+	return nil
+}
+
+// checkScaleRows возвращает *ShapeError, если v не является вектором с количеством строк S.
+func (S *mySparse) checkScaleRows(v *myMatrix) error {
+	if v.rows != S.rows || v.columns != 1 {
+		return &ShapeError{Op: "ScaleRows", A: S.shape(), B: v.shape()}
+	}
+	return nil
+}
+
+// checkDot возвращает *ShapeError, если разреженную матрицу S нельзя умножить на матрицу B.
+func (S *mySparse) checkDot(B *myMatrix) error {
+	if S.columns != B.rows {
+		return &ShapeError{Op: "Sparse.Dot", A: S.shape(), B: B.shape()}
+	}
+	return nil
+}
+
+// checkDotSparse возвращает *ShapeError, если матрицу A нельзя умножить на разреженную матрицу S
+// (на транспонированную S при trans равном true).
+func (A *myMatrix) checkDotSparse(S *mySparse, trans bool) error {
+	op, inner := "DotSparse", S.rows
+	if trans {
+		op, inner = "DotTSparse", S.columns
+	}
+
+	if A.columns != inner {
+		return &ShapeError{Op: op, A: A.shape(), B: S.shape()}
+	}
+	return nil
+}
+
+// checkSquare возвращает *ShapeError, если матрица M не является квадратной.
+func checkSquare(op string, M *myMatrix) error {
+	if M.rows != M.columns {
+		return &ShapeError{Op: op, A: M.shape()}
+	}
+	return nil
+}
+
+// checkSolve возвращает *ShapeError, если количество строк матриц A и B не совпадает,
+// и *DTypeError, если различаются типы элементов.
+func checkSolve(op string, A, B *myMatrix) error {
+	if A.rows != B.rows {
+		return &ShapeError{Op: op, A: A.shape(), B: B.shape()}
+	}
+	return checkDType(op, A, B)
+}
+
+// must вызывает панику, если err не равна nil.
+// Используется методами, которые вызывают панику вместо возврата ошибки.
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// IndexError ошибка выхода блока элементов за границы матрицы.
+// Возвращается методами с префиксом Try вместо паники.
+type IndexError struct {
+	Op    string // Имя операции
+	I, J  int    // Индексы первого элемента блока
+	Block Shape  // Размерность блока
+	Shape Shape  // Размерность матрицы
+}
+
+// Error возвращает описание ошибки.
+func (e *IndexError) Error() string {
+	return fmt.Sprintf("index out of range for %s: block %d*%d at (%d, %d) of %d*%d matrix",
+		e.Op, e.Block.Rows, e.Block.Columns, e.I, e.J, e.Shape.Rows, e.Shape.Columns)
+}
+
+// checkBlock возвращает *IndexError, если блок размерности rows на columns,
+// начинающийся с элемента i, j, не лежит внутри матрицы M или имеет не положительные размеры.
+func (M *myMatrix) checkBlock(op string, i, j, rows, columns int) error {
+	if i < 0 || j < 0 || rows <= 0 || columns <= 0 || i+rows > M.rows || j+columns > M.columns {
+		return &IndexError{Op: op, I: i, J: j, Block: Shape{Rows: rows, Columns: columns}, Shape: M.shape()}
+	}
+	return nil
+}
+
+// checkReshape возвращает *ShapeError, если размеры rows и columns не положительны
+// или количество элементов матрицы M не равно rows * columns.
+func (M *myMatrix) checkReshape(rows, columns int) error {
+	if rows <= 0 || columns <= 0 || rows*columns != M.rows*M.columns {
+		return &ShapeError{Op: "Reshape", A: M.shape(), B: Shape{Rows: rows, Columns: columns}}
+	}
+	return nil
+}
+
+// checkStack возвращает ошибку, если матрицы ms нельзя объединить по направлению axis:
+// *ShapeError, если слайс пуст или количество строк (при AxisRows) либо столбцов (при AxisColumns)
+// матриц различается, и *DTypeError, если различаются типы элементов.
+func checkStack(op string, axis Axis, ms []*myMatrix) error {
+	if len(ms) == 0 {
+		return &ShapeError{Op: op}
+	}
+
+	for _, M := range ms[1:] {
+		if axis == AxisRows && M.rows != ms[0].rows || axis == AxisColumns && M.columns != ms[0].columns {
+			return &ShapeError{Op: op, A: ms[0].shape(), B: M.shape()}
+		}
+		if err := checkDType(op, ms[0], M); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseError ошибка чтения некорректной строки текстового файла данных (Matrix Market, LIBSVM).
+type ParseError struct {
+	Format string // Формат файла
+	Line   int    // Номер строки, начиная с 1
+	Err    error  // Описание ошибки
+}
+
+// Error возвращает описание ошибки.
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s line %d: %v", e.Format, e.Line, e.Err)
+}
+
+// Unwrap возвращает описание ошибки.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,148 @@
+package matrix
+
+// Размеры блоков, на которые разбивается произведение матриц.
+// Блок результата blockRows на blockColumns вместе с соответствующими частями
+// операндов длины blockInner помещается в кэш процессора.
+const (
+	blockRows    = 64
+	blockColumns = 256
+	blockInner   = 256
+)
+
+// gemmSerialWork количество умножений m*n*k, ниже которого произведение
+// считается последовательно одним блоком без разбиения.
+const gemmSerialWork = 1 << 15
+
+// gemm прибавляет к матрице C произведение op(A)·op(B), где op(X) равно транспонированной X,
+// если соответствующий флаг transA или transB равен true, и самой X иначе.
+// Транспонированная матрица при этом не создается, элементы читаются из исходной матрицы.
+// Размерности должны быть проверены заранее, C не должна разделять память с A и B.
+// Результат разбивается на блоки, которые считаются исполнителями из набора (см. SetNumWorkers).
+// Каждый элемент C считается одним исполнителем в фиксированном порядке суммирования,
+// поэтому результат не зависит от количества исполнителей.
+func gemm[T float](transA, transB bool, A, B, C dense[T]) {
+	m, n := C.rows, C.columns
+
+	k := A.columns
+	if transA {
+		k = A.rows
+	}
+
+	if m*n*k < gemmSerialWork {
+		gemmBlock(transA, transB, A, B, C, 0, m, 0, n, 0, k)
+		return
+	}
+
+	tilesI := (m + blockRows - 1) / blockRows
+	tilesJ := (n + blockColumns - 1) / blockColumns
+
+	parallelFor(tilesI*tilesJ, m*n*k, func(lo, hi int) {
+		for t := lo; t < hi; t++ {
+			i0 := (t / tilesJ) * blockRows
+			j0 := (t % tilesJ) * blockColumns
+			i1 := minInt(i0+blockRows, m)
+			j1 := minInt(j0+blockColumns, n)
+
+			for k0 := 0; k0 < k; k0 += blockInner {
+				gemmBlock(transA, transB, A, B, C, i0, i1, j0, j1, k0, minInt(k0+blockInner, k))
+			}
+		}
+	})
+}
+
+// gemmBlock прибавляет к блоку C[i0:i1, j0:j1] произведение блоков op(A)[i0:i1, k0:k1] и op(B)[k0:k1, j0:j1].
+func gemmBlock[T float](transA, transB bool, A, B, C dense[T], i0, i1, j0, j1, k0, k1 int) {
+	switch {
+	case !transA && !transB:
+		// произведение матрицы на вектор считается скалярными произведениями строк A на столбец B
+		if j1-j0 == 1 {
+			for i := i0; i < i1; i++ {
+				var sum T
+				for kk, a := range A.row(i)[k0:k1] {
+					sum += a * B.data[(k0+kk)*B.stride+j0]
+				}
+				C.data[i*C.stride+j0] += sum
+			}
+			return
+		}
+
+		for i := i0; i < i1; i++ {
+			rowC := C.row(i)[j0:j1]
+			for kk, a := range A.row(i)[k0:k1] {
+				rowB := B.row(k0 + kk)[j0:j1]
+				for j := range rowC {
+					rowC[j] += a * rowB[j]
+				}
+			}
+		}
+
+	case transA && !transB:
+		// строка kk матрицы A является столбцом kk матрицы op(A)
+		for kk := k0; kk < k1; kk++ {
+			rowA := A.row(kk)[i0:i1]
+			rowB := B.row(kk)[j0:j1]
+
+			if len(rowB) == 1 {
+				b := rowB[0]
+				for ii, a := range rowA {
+					C.data[(i0+ii)*C.stride+j0] += a * b
+				}
+				continue
+			}
+
+			for ii, a := range rowA {
+				rowC := C.row(i0 + ii)[j0:j1]
+				for j := range rowC {
+					rowC[j] += a * rowB[j]
+				}
+			}
+		}
+
+	case !transA && transB:
+		// внешнее произведение векторов (например, ошибки слоя на активации предыдущего слоя)
+		if k1-k0 == 1 {
+			for i := i0; i < i1; i++ {
+				a := A.data[i*A.stride+k0]
+				rowC := C.row(i)[j0:j1]
+				for j := range rowC {
+					rowC[j] += a * B.data[(j0+j)*B.stride+k0]
+				}
+			}
+			return
+		}
+
+		// элемент C[i, j] является скалярным произведением строки i матрицы A и строки j матрицы B
+		for i := i0; i < i1; i++ {
+			rowA := A.row(i)[k0:k1]
+			rowC := C.row(i)[j0:j1]
+
+			for j := range rowC {
+				rowB := B.row(j0 + j)[k0:k1]
+
+				var sum T
+				for kk, a := range rowA {
+					sum += a * rowB[kk]
+				}
+				rowC[j] += sum
+			}
+		}
+
+	default:
+		for kk := k0; kk < k1; kk++ {
+			for ii, a := range A.row(kk)[i0:i1] {
+				rowC := C.row(i0 + ii)[j0:j1]
+				for j := range rowC {
+					rowC[j] += a * B.data[(j0+j)*B.stride+kk]
+				}
+			}
+		}
+	}
+}
+
+// minInt вспомогательная функция, возвращает наименьшее из двух целых чисел.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,180 @@
+package matrix
+
+// float ограничение на типы элементов, над которыми работают внутренние ядра вычислений.
+type float interface {
+	~float32 | ~float64
+}
+
+// dense представляет данные плотной матрицы с элементами типа T для внутренних ядер вычислений.
+// Элемент i строки, j столбца находится в data[i*stride+j].
+type dense[T float] struct {
+	rows    int // Количество строк
+	columns int // Количество столбцов
+	stride  int // Расстояние в элементах между началами соседних строк
+	data    []T // Данные матрицы
+}
+
+// row возвращает слайс элементов i строки, разделяющий память с матрицей.
+func (d dense[T]) row(i int) []T {
+	start := i * d.stride
+	return d.data[start : start+d.columns]
+}
+
+/*
+Поэлементные ядра.
+Каждое ядро записывает результат в C, которая может совпадать с одним из операндов,
+размерности матриц должны быть проверены заранее.
+Функция f поэлементных операций всегда вычисляется в float64,
+для матриц с элементами float32 результат округляется до float32.
+*/
+
+// addRows записывает в C поэлементную сумму матриц A и B.
+func addRows[T float](C, A, B dense[T]) {
+	if serialWork(C.rows, C.rows*C.columns) {
+		addRange(C, A, B, 0, C.rows)
+		return
+	}
+
+	parallelFor(C.rows, C.rows*C.columns, func(lo, hi int) {
+		addRange(C, A, B, lo, hi)
+	})
+}
+
+// addRange записывает в строки lo..hi-1 матрицы C поэлементную сумму матриц A и B.
+func addRange[T float](C, A, B dense[T], lo, hi int) {
+	for i := lo; i < hi; i++ {
+		rowC, rowA, rowB := C.row(i), A.row(i), B.row(i)
+		for j := range rowC {
+			rowC[j] = rowA[j] + rowB[j]
+		}
+	}
+}
+
+// subRows записывает в C поэлементную разность матриц A и B.
+func subRows[T float](C, A, B dense[T]) {
+	if serialWork(C.rows, C.rows*C.columns) {
+		subRange(C, A, B, 0, C.rows)
+		return
+	}
+
+	parallelFor(C.rows, C.rows*C.columns, func(lo, hi int) {
+		subRange(C, A, B, lo, hi)
+	})
+}
+
+// subRange записывает в строки lo..hi-1 матрицы C поэлементную разность матриц A и B.
+func subRange[T float](C, A, B dense[T], lo, hi int) {
+	for i := lo; i < hi; i++ {
+		rowC, rowA, rowB := C.row(i), A.row(i), B.row(i)
+		for j := range rowC {
+			rowC[j] = rowA[j] - rowB[j]
+		}
+	}
+}
+
+// mulRows записывает в C поэлементное (адамарное) произведение матриц A и B.
+func mulRows[T float](C, A, B dense[T]) {
+	if serialWork(C.rows, C.rows*C.columns) {
+		mulRange(C, A, B, 0, C.rows)
+		return
+	}
+
+	parallelFor(C.rows, C.rows*C.columns, func(lo, hi int) {
+		mulRange(C, A, B, lo, hi)
+	})
+}
+
+// mulRange записывает в строки lo..hi-1 матрицы C поэлементное произведение матриц A и B.
+func mulRange[T float](C, A, B dense[T], lo, hi int) {
+	for i := lo; i < hi; i++ {
+		rowC, rowA, rowB := C.row(i), A.row(i), B.row(i)
+		for j := range rowC {
+			rowC[j] = rowA[j] * rowB[j]
+		}
+	}
+}
+
+// applyRows записывает в C результат применения функции f к каждому элементу матрицы A.
+func applyRows[T float](C, A dense[T], f func(float64) float64) {
+	if serialWork(C.rows, C.rows*C.columns) {
+		applyRange(C, A, f, 0, C.rows)
+		return
+	}
+
+	parallelFor(C.rows, C.rows*C.columns, func(lo, hi int) {
+		applyRange(C, A, f, lo, hi)
+	})
+}
+
+// applyRange записывает в строки lo..hi-1 матрицы C результат применения функции f к элементам матрицы A.
+func applyRange[T float](C, A dense[T], f func(float64) float64, lo, hi int) {
+	for i := lo; i < hi; i++ {
+		rowC, rowA := C.row(i), A.row(i)
+		for j := range rowC {
+			rowC[j] = T(f(float64(rowA[j])))
+		}
+	}
+}
+
+// transposeRows записывает в C транспонированную матрицу A.
+// Каждая горутина заполняет свой набор строк результата.
+func transposeRows[T float](C, A dense[T]) {
+	if serialWork(C.rows, A.rows*A.columns) {
+		transposeRange(C, A, 0, C.rows)
+		return
+	}
+
+	parallelFor(C.rows, A.rows*A.columns, func(lo, hi int) {
+		transposeRange(C, A, lo, hi)
+	})
+}
+
+// transposeRange заполняет строки lo..hi-1 матрицы C столбцами матрицы A.
+func transposeRange[T float](C, A dense[T], lo, hi int) {
+	for j := lo; j < hi; j++ {
+		rowT := C.row(j)
+		for i := range rowT {
+			rowT[i] = A.data[i*A.stride+j]
+		}
+	}
+}
+
+// scaleRows записывает в C матрицу A, умноженную на число k.
+// Как и в поэлементных операциях, произведение вычисляется в float64.
+func scaleRows[T float](C, A dense[T], k float64) {
+	for i := 0; i < C.rows; i++ {
+		rowC, rowA := C.row(i), A.row(i)
+		for j := range rowC {
+			rowC[j] = T(float64(rowA[j]) * k)
+		}
+	}
+}
+
+// axpyRows прибавляет к C матрицу A, умноженную на число k.
+// Как и в поэлементных операциях, произведение вычисляется в float64.
+func axpyRows[T float](C, A dense[T], k float64) {
+	for i := 0; i < C.rows; i++ {
+		rowC, rowA := C.row(i), A.row(i)
+		for j := range rowC {
+			rowC[j] += T(float64(rowA[j]) * k)
+		}
+	}
+}
+
+// fillRows записывает во все элементы C число x.
+func fillRows[T float](C dense[T], x float64) {
+	xx := T(x)
+	for i := 0; i < C.rows; i++ {
+		rowC := C.row(i)
+		for j := range rowC {
+			rowC[j] = xx
+		}
+	}
+}
+
+// copyRows копирует элементы A в C.
+func copyRows[T float](C, A dense[T]) {
+	for i := 0; i < C.rows; i++ {
+		copy(C.row(i), A.row(i))
+	}
+}
@@ -0,0 +1,291 @@
+package matrix
+
+import (
+	"errors"
+	"math"
+)
+
+/*
+Разложения матриц и решение систем линейных уравнений.
+Вычисления производятся в float64 для любого типа элементов,
+возвращаемые матрицы имеют тип элементов исходной матрицы.
+Матрица считается вырожденной, если диагональный элемент треугольного множителя разложения
+по модулю не превышает n * машинный эпсилон * наибольший по модулю элемент матрицы.
+*/
+
+// ErrSingular возвращается при решении системы или обращении вырожденной матрицы.
+var ErrSingular = errors.New("matrix is singular")
+
+// ErrNotPositiveDefinite возвращается разложением Холецкого для матрицы,
+// которая не является положительно определенной.
+var ErrNotPositiveDefinite = errors.New("matrix is not positive definite")
+
+// LU хранит LU разложение квадратной матрицы A с выбором ведущего элемента по столбцу: PA = LU,
+// где P матрица перестановки, L нижнетреугольная матрица с единичной диагональю,
+// U верхнетреугольная матрица.
+type LU struct {
+	lu *myLU // Указатель на собственную реализацию разложения
+}
+
+// LU возвращает LU разложение (структуру LU) матрицы A и ошибку.
+// Разложение существует и для вырожденной матрицы, ошибку ErrSingular возвращают методы Solve и Inverse.
+// Метод возвращает *ShapeError, если матрица A не является квадратной.
+func (A Matrix) LU() (LU, error) {
+	if err := checkSquare("LU", A.matrix); err != nil {
+		return LU{}, err
+	}
+
+	return LU{
+		lu: luDecompose(A.matrix),
+	}, nil
+}
+
+// L возвращает нижнетреугольный множитель L с единичной диагональю.
+func (f LU) L() Matrix {
+	n := f.lu.lu.rows
+	L := zeroOf(n, n, f.lu.dtype)
+
+	for i := 0; i < n; i++ {
+		L.setIJ(i, i, 1.)
+		for j := 0; j < i; j++ {
+			L.setIJ(i, j, f.lu.lu.data[i*f.lu.lu.stride+j])
+		}
+	}
+
+	return Matrix{matrix: L}
+}
+
+// U возвращает верхнетреугольный множитель U.
+func (f LU) U() Matrix {
+	n := f.lu.lu.rows
+	U := zeroOf(n, n, f.lu.dtype)
+
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			U.setIJ(i, j, f.lu.lu.data[i*f.lu.lu.stride+j])
+		}
+	}
+
+	return Matrix{matrix: U}
+}
+
+// P возвращает матрицу перестановки P.
+func (f LU) P() Matrix {
+	n := f.lu.lu.rows
+	P := zeroOf(n, n, f.lu.dtype)
+
+	for i, p := range f.lu.piv {
+		P.setIJ(i, p, 1.)
+	}
+
+	return Matrix{matrix: P}
+}
+
+// Det возвращает определитель исходной матрицы.
+func (f LU) Det() float64 {
+	return f.lu.det()
+}
+
+// Solve возвращает решение X системы AX = B и ошибку.
+// Метод возвращает ErrSingular, если матрица A вырождена,
+// и ошибку, если количество строк A и B не совпадает или различаются типы элементов.
+func (f LU) Solve(B Matrix) (Matrix, error) {
+	if B.matrix.rows != f.lu.lu.rows {
+		return Matrix{}, &ShapeError{Op: "LU.Solve", A: Shape{Rows: f.lu.lu.rows, Columns: f.lu.lu.columns}, B: B.matrix.shape()}
+	}
+	if B.matrix.dtype != f.lu.dtype {
+		return Matrix{}, &DTypeError{Op: "LU.Solve", A: f.lu.dtype, B: B.matrix.dtype}
+	}
+	if f.lu.singular {
+		return Matrix{}, ErrSingular
+	}
+
+	return Matrix{
+		matrix: f.lu.solve(B.matrix).asType(f.lu.dtype),
+	}, nil
+}
+
+// Inverse возвращает обратную матрицу и ошибку.
+// Метод возвращает ErrSingular, если матрица вырождена.
+func (f LU) Inverse() (Matrix, error) {
+	return f.Solve(Matrix{matrix: identity(f.lu.lu.rows, f.lu.dtype)})
+}
+
+// QR хранит QR разложение матрицы A размерности m на n (m >= n) отражениями Хаусхолдера: A = QR,
+// где Q матрица размерности m на n с ортонормированными столбцами, R верхнетреугольная матрица n на n.
+type QR struct {
+	qr *myQR // Указатель на собственную реализацию разложения
+}
+
+// QR возвращает QR разложение (структуру QR) матрицы A и ошибку.
+// Метод возвращает *ShapeError, если количество строк A меньше количества столбцов.
+func (A Matrix) QR() (QR, error) {
+	if A.matrix.rows < A.matrix.columns {
+		return QR{}, &ShapeError{Op: "QR", A: A.matrix.shape()}
+	}
+
+	return QR{
+		qr: qrDecompose(A.matrix),
+	}, nil
+}
+
+// Q возвращает матрицу Q с ортонормированными столбцами.
+func (f QR) Q() Matrix {
+	return Matrix{matrix: f.qr.q()}
+}
+
+// R возвращает верхнетреугольную матрицу R.
+func (f QR) R() Matrix {
+	return Matrix{matrix: f.qr.r()}
+}
+
+// Solve возвращает решение X задачи наименьших квадратов: минимум нормы AX - B, и ошибку.
+// Для квадратной матрицы A это решение системы AX = B.
+// Метод возвращает ErrSingular, если столбцы матрицы A линейно зависимы,
+// и ошибку, если количество строк A и B не совпадает или различаются типы элементов.
+func (f QR) Solve(B Matrix) (Matrix, error) {
+	if B.matrix.rows != f.qr.qr.rows {
+		return Matrix{}, &ShapeError{Op: "QR.Solve", A: Shape{Rows: f.qr.qr.rows, Columns: f.qr.qr.columns}, B: B.matrix.shape()}
+	}
+	if B.matrix.dtype != f.qr.dtype {
+		return Matrix{}, &DTypeError{Op: "QR.Solve", A: f.qr.dtype, B: B.matrix.dtype}
+	}
+	if !f.qr.fullRank() {
+		return Matrix{}, ErrSingular
+	}
+
+	return Matrix{
+		matrix: f.qr.solve(B.matrix).asType(f.qr.dtype),
+	}, nil
+}
+
+// Cholesky хранит разложение Холецкого симметричной положительно определенной матрицы A: A = LL^T,
+// где L нижнетреугольная матрица с положительной диагональю.
+type Cholesky struct {
+	chol *myCholesky // Указатель на собственную реализацию разложения
+}
+
+// Cholesky возвращает разложение Холецкого (структуру Cholesky) матрицы A и ошибку.
+// Используются только элементы на и под диагональю A, симметричность не проверяется.
+// Метод возвращает *ShapeError, если матрица A не является квадратной,
+// и ErrNotPositiveDefinite, если матрица не является положительно определенной.
+func (A Matrix) Cholesky() (Cholesky, error) {
+	if err := checkSquare("Cholesky", A.matrix); err != nil {
+		return Cholesky{}, err
+	}
+
+	chol, ok := choleskyDecompose(A.matrix)
+	if !ok {
+		return Cholesky{}, ErrNotPositiveDefinite
+	}
+
+	return Cholesky{
+		chol: chol,
+	}, nil
+}
+
+// L возвращает нижнетреугольный множитель L.
+func (f Cholesky) L() Matrix {
+	L := &myMatrix{
+		rows:    f.chol.l.rows,
+		columns: f.chol.l.columns,
+		stride:  f.chol.l.stride,
+		dtype:   Float64,
+		data:    f.chol.l.data,
+	}
+
+	return Matrix{matrix: L.asType(f.chol.dtype)}
+}
+
+// Det возвращает определитель исходной матрицы: квадрат произведения диагональных элементов L.
+func (f Cholesky) Det() float64 {
+	d := 1.
+	for i := 0; i < f.chol.l.rows; i++ {
+		d *= f.chol.l.data[i*f.chol.l.stride+i]
+	}
+	return d * d
+}
+
+// Solve возвращает решение X системы AX = B и ошибку.
+// Метод возвращает ошибку, если количество строк A и B не совпадает или различаются типы элементов.
+func (f Cholesky) Solve(B Matrix) (Matrix, error) {
+	n := f.chol.l.rows
+	if B.matrix.rows != n {
+		return Matrix{}, &ShapeError{Op: "Cholesky.Solve", A: Shape{Rows: n, Columns: n}, B: B.matrix.shape()}
+	}
+	if B.matrix.dtype != f.chol.dtype {
+		return Matrix{}, &DTypeError{Op: "Cholesky.Solve", A: f.chol.dtype, B: B.matrix.dtype}
+	}
+
+	return Matrix{
+		matrix: f.chol.solve(B.matrix).asType(f.chol.dtype),
+	}, nil
+}
+
+// Inverse возвращает обратную матрицу.
+func (f Cholesky) Inverse() Matrix {
+	inv, _ := f.Solve(Matrix{matrix: identity(f.chol.l.rows, f.chol.dtype)})
+	return inv
+}
+
+// Solve возвращает решение X системы AX = B и ошибку.
+// Для квадратной матрицы A используется LU разложение,
+// для матрицы с количеством строк больше количества столбцов ищется решение задачи наименьших квадратов
+// через QR разложение.
+// Метод возвращает ErrSingular, если матрица A вырождена (имеет линейно зависимые столбцы),
+// *ShapeError, если количество строк A и B не совпадает или количество строк A меньше количества столбцов,
+// и *DTypeError, если различаются типы элементов.
+func (A Matrix) Solve(B Matrix) (Matrix, error) {
+	if err := checkSolve("Solve", A.matrix, B.matrix); err != nil {
+		return Matrix{}, err
+	}
+
+	if A.matrix.rows == A.matrix.columns {
+		f, _ := A.LU()
+		return f.Solve(B)
+	}
+
+	f, err := A.QR()
+	if err != nil {
+		return Matrix{}, err
+	}
+	return f.Solve(B)
+}
+
+// Inverse возвращает обратную матрицу для матрицы A и ошибку.
+// Метод возвращает *ShapeError, если матрица A не является квадратной, и ErrSingular, если она вырождена.
+func (A Matrix) Inverse() (Matrix, error) {
+	f, err := A.LU()
+	if err != nil {
+		return Matrix{}, err
+	}
+	return f.Inverse()
+}
+
+// Det возвращает определитель матрицы A и ошибку.
+// Метод возвращает *ShapeError, если матрица A не является квадратной.
+func (A Matrix) Det() (float64, error) {
+	f, err := A.LU()
+	if err != nil {
+		return 0, err
+	}
+	return f.Det(), nil
+}
+
+// Cond возвращает оценку числа обусловленности матрицы A в 1-норме: ||A|| * ||A^-1||, и ошибку.
+// Норма обратной матрицы оценивается методом Хагера по LU разложению без явного обращения,
+// оценка не превышает точного значения и обычно совпадает с ним.
+// Метод возвращает *ShapeError, если матрица A не является квадратной,
+// и бесконечность с ошибкой ErrSingular, если матрица вырождена.
+func (A Matrix) Cond() (float64, error) {
+	f, err := A.LU()
+	if err != nil {
+		return 0, err
+	}
+
+	if f.lu.singular {
+		return math.Inf(1), ErrSingular
+	}
+
+	return norm1(A.matrix) * f.lu.invNorm1(), nil
+}
@@ -11,12 +11,19 @@ package matrix
 
 import (
 	"bufio"
+	"fmt"
 	"io"
+	"math/rand"
 )
 
 // Matrix является структурой оболочкой над собственной реализацией матриц.
 // Это сделано для того чтобы без труда можно было подменить реализации методов из сторонних ресурсов,
 // не меняя при этом код нейронной сети.
+// Вычисления над элементами выполняет текущая реализация интерфейса Backend (см. SetBackend).
+// Элементы матрицы хранятся как float64 или как float32 (см. DType), операции над двумя матрицами
+// требуют одинакового типа элементов и вызывают панику иначе, результат имеет тот же тип элементов.
+// Операции вызывают панику при несовместимых размерностях или типах элементов операндов,
+// методы с префиксом Try возвращают вместо этого ошибку *ShapeError или *DTypeError.
 type Matrix struct {
 	matrix *myMatrix // Указатель на собственную реализацию структуры матриц myMatrix
 }
@@ -69,6 +76,44 @@ func RandMatrix(rows, columns int) Matrix {
 	}
 }
 
+// ZeroOf работает так же, как Zero, но создает матрицу с типом элементов dtype.
+func ZeroOf(rows, columns int, dtype DType) Matrix {
+	return Matrix{
+		matrix: zeroOf(rows, columns, dtype),
+	}
+}
+
+// RandMatrixOf работает так же, как RandMatrix, но создает матрицу с типом элементов dtype.
+func RandMatrixOf(rows, columns int, dtype DType) Matrix {
+	return Matrix{
+		matrix: randMatrixOf(rows, columns, dtype, nil),
+	}
+}
+
+// RandMatrixFrom работает так же, как RandMatrixOf, но берет случайные значения из генератора rng.
+// Генераторы с одинаковым начальным значением дают одинаковые матрицы.
+// Если rng равен nil, используется глобальный генератор пакета math/rand.
+func RandMatrixFrom(rows, columns int, dtype DType, rng *rand.Rand) Matrix {
+	return Matrix{
+		matrix: randMatrixOf(rows, columns, dtype, rng),
+	}
+}
+
+// DType возвращает тип элементов данной матрицы (структуры Matrix).
+func (M Matrix) DType() DType {
+	return M.matrix.dtype
+}
+
+// AsType возвращает экземпляр Matrix.
+// Возвращаемая матрица является копией матрицы M с типом элементов dtype,
+// при преобразовании в float32 элементы округляются.
+// Возвращаемая матрица не разделяет память с M, даже если тип элементов совпадает.
+func (M Matrix) AsType(dtype DType) Matrix {
+	return Matrix{
+		matrix: M.matrix.asType(dtype),
+	}
+}
+
 // GetColumns возвращает количество строк данной матрицы (структуры Matrix).
 func (M Matrix) GetColumns() int {
 	return M.matrix.getColumns()
@@ -100,6 +145,26 @@ func (A Matrix) Dot(B Matrix) Matrix {
 	}
 }
 
+// TDot возвращает экземпляр Matrix.
+// Возвращаемая матрица является результатом произведения транспонированной матрицы A на матрицу B,
+// то есть совпадает с A.T().Dot(B), но не создает транспонированную копию матрицы A.
+// Метод вызывает панику, если количество строк матриц A и B не совпадает.
+func (A Matrix) TDot(B Matrix) Matrix {
+	return Matrix{
+		matrix: A.matrix.tDot(B.matrix),
+	}
+}
+
+// DotT возвращает экземпляр Matrix.
+// Возвращаемая матрица является результатом произведения матрицы A на транспонированную матрицу B,
+// то есть совпадает с A.Dot(B.T()), но не создает транспонированную копию матрицы B.
+// Метод вызывает панику, если количество столбцов матриц A и B не совпадает.
+func (A Matrix) DotT(B Matrix) Matrix {
+	return Matrix{
+		matrix: A.matrix.dotT(B.matrix),
+	}
+}
+
 // Add возвращает экземпляр Matrix.
 // Возвращаемая матрица является результатом суммы матриц A и B.
 // Метод вызывает панику, если размерности исходных матриц не равны.
@@ -214,6 +279,8 @@ func Matrix2Vector(M Matrix, n int) (Matrix, error) {
 // Vec2Dig возвращает целое число - индекс наибольшего элемента вектора (матрицы M размерности n на 1).
 // функция работает обратно функции num2Vec.
 // Функция вызывает панику, если матрица не является вектором и если матрица имеет размерность 1 на 1.
+//
+// Deprecated: используйте метод ArgMax, который работает с матрицами любой размерности.
 func Vec2Num(M Matrix) int {
 	return vec2Num(M.matrix)
 }
@@ -225,14 +292,14 @@ func Num(M Matrix) float64 {
 }
 
 // Zeros возвращает указатель на массив из матриц (структур Matrix).
-// Функция возвращает массив нулевых матриц той же размерности что и исходный массив матриц.
+// Функция возвращает массив нулевых матриц той же размерности и того же типа элементов что и исходный массив матриц.
 // Предупреждение: функция может вызвать панику, если rows или columns будут не положительными,
 // поскольку внутренне вызывается функция Zero, требующая положительных значений для этих параметров.
 func Zeros(slc *[]Matrix) *[]Matrix {
 	res := make([]Matrix, len(*slc))
 
 	for i := 0; i < len(*slc); i++ {
-		res[i] = Zero((*slc)[i].GetRows(), (*slc)[i].GetColumns())
+		res[i] = ZeroOf((*slc)[i].GetRows(), (*slc)[i].GetColumns(), (*slc)[i].DType())
 	}
 	return &res
 }
@@ -264,18 +331,21 @@ func countUniqueElements(M Matrix) int {
 	return _countUniqueElements(M.matrix)
 }
 
-// Show выводит матрицу (структуру Matrix) в консоль.
+// Show выводит матрицу (структуру Matrix) в консоль в стиле NumPy (см. String).
 func (M Matrix) Show() {
-	M.matrix.show()
+	fmt.Println(M)
 }
 
 // writeMatrix записывает данные нескольких матриц (структур Matrix) в поток вывода, реализующий интерфейс io.Writer,
 // если запись не удалась функция возвращает ошибку.
 // Функция форматирует данные каждой матрицы следующим образом:
 // сначала записывается общее количество матриц в переданном слайсе,
-// с новой строки для каждой матрицы записывается ее размерность (количество строк и столбцов),
+// с новой строки для каждой матрицы записывается ее размерность (количество строк и столбцов)
+// и, если элементы матрицы не float64, через пробел тип элементов (например float32),
 // с новой строки данные самой матрицы.
 // Элементы каждой строки матрицы разделяются пробелом, а строки матрицы - переводами строк.
+// Элементы записываются с шестью знаками после запятой, для записи без потери точности
+// используйте WriteMatrixesBinary.
 // Функция не открывает и не закрывает поток вывода, управление потоком
 // должно осуществляться вне этой функции.
 func WriteMatrixes(writer io.Writer, matrixes []Matrix) error {
@@ -288,7 +358,8 @@ func WriteMatrixes(writer io.Writer, matrixes []Matrix) error {
 // Функция читает данные, начиная с текущей позиции в потоке сканера.
 // Каждый блок матрицы в файле должен быть отформатирован следующим образом:
 // сначала идет число - общее количество матриц,
-// затем с новой строки для каждой матрицы должна быть ее размерность (количество строк и столбцов),
+// затем с новой строки для каждой матрицы должна быть ее размерность (количество строк и столбцов)
+// и необязательный тип элементов (по умолчанию float64),
 // с новой строки данные самой матрицы.
 // Элементы каждой строки матрицы разделяются пробелом, а строки матрицы - переводами строк.
 // Функция принимает уже инициализированный сканер структуры *bufio.Scanner.
@@ -304,3 +375,32 @@ func ReadMatrixes(scanner *bufio.Scanner) ([]Matrix, error) {
 
 	return matrixes, nil
 }
+
+// WriteMatrixesBinary записывает матрицы (структуры Matrix) в поток вывода, реализующий интерфейс io.Writer,
+// в двоичном формате и возвращает ошибку, если запись не удалась.
+// В отличие от WriteMatrixes элементы записываются без потери точности,
+// матрицы записываются по одной строке без копирования всех данных в память.
+// Формат: заголовок с магическими байтами "GBMX", версией формата и количеством матриц,
+// далее для каждой матрицы тип элементов, размерность, элементы little-endian в порядке строк
+// и контрольная сумма CRC-32.
+// Функция не открывает и не закрывает поток вывода.
+func WriteMatrixesBinary(writer io.Writer, matrixes []Matrix) error {
+	return writeMatrixesBinary(writer, convertToMatrixImpSlice(matrixes))
+}
+
+// ReadMatrixesBinary возвращает слайс матриц (структур Matrix), прочитанных из потока
+// в двоичном формате (см. WriteMatrixesBinary), и ошибку, если она возникла.
+// Функция возвращает ошибку ErrChecksum, если данные матрицы повреждены,
+// и ошибку, если поток не является двоичным файлом матриц, имеет неподдерживаемую версию
+// или закончился раньше времени.
+// Поток читается через буфер, поэтому данные после последней матрицы могут быть прочитаны наперед,
+// если reader не является *bufio.Reader (тогда они остаются в его буфере).
+// Функция не открывает и не закрывает поток.
+func ReadMatrixesBinary(reader io.Reader) ([]Matrix, error) {
+	matrixesImp, err := readMatrixesBinary(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertToMatrixSlice(matrixesImp), nil
+}
@@ -0,0 +1,84 @@
+package matrix
+
+/*
+Операции с суффиксом Broadcast выполняют поэлементную операцию над матрицей A
+и вектором v, растянутым на размерность A.
+Вектор-столбец v (размерности rows на 1) повторяется в каждом столбце A,
+например прибавление вектора смещений к каждому наблюдению мини батча, хранящегося по столбцам.
+Вектор-строка v (размерности 1 на columns) повторяется в каждой строке A.
+Операции вызывают панику, если v не является вектором подходящей размерности
+или типы элементов A и v различаются, методы с префиксом Try возвращают вместо этого ошибку.
+*/
+
+// AddBroadcast возвращает экземпляр Matrix, равный сумме матрицы A и растянутого вектора v.
+func (A Matrix) AddBroadcast(v Matrix) Matrix {
+	return Matrix{
+		matrix: A.matrix.broadcast("AddBroadcast", broadcastAdd, v.matrix),
+	}
+}
+
+// SubBroadcast возвращает экземпляр Matrix, равный разности матрицы A и растянутого вектора v.
+func (A Matrix) SubBroadcast(v Matrix) Matrix {
+	return Matrix{
+		matrix: A.matrix.broadcast("SubBroadcast", broadcastSub, v.matrix),
+	}
+}
+
+// HadamardProductBroadcast возвращает экземпляр Matrix, равный адамарному произведению
+// матрицы A и растянутого вектора v.
+func (A Matrix) HadamardProductBroadcast(v Matrix) Matrix {
+	return Matrix{
+		matrix: A.matrix.broadcast("HadamardProductBroadcast", broadcastMul, v.matrix),
+	}
+}
+
+// AddBroadcastInPlace прибавляет к матрице A растянутый вектор v.
+// Результат сохраняется в A, изменяя ее.
+// Метод вызывает панику также, если v частично перекрывает A (например, является строкой A).
+func (A Matrix) AddBroadcastInPlace(v Matrix) {
+	A.matrix.broadcastInPlace("AddBroadcastInPlace", broadcastAdd, v.matrix)
+}
+
+// SubBroadcastInPlace вычитает из матрицы A растянутый вектор v.
+// Результат сохраняется в A, изменяя ее.
+// Метод вызывает панику также, если v частично перекрывает A (например, является строкой A).
+func (A Matrix) SubBroadcastInPlace(v Matrix) {
+	A.matrix.broadcastInPlace("SubBroadcastInPlace", broadcastSub, v.matrix)
+}
+
+// HadamardProductBroadcastInPlace умножает поэлементно матрицу A на растянутый вектор v.
+// Результат сохраняется в A, изменяя ее.
+// Метод вызывает панику также, если v частично перекрывает A (например, является строкой A).
+func (A Matrix) HadamardProductBroadcastInPlace(v Matrix) {
+	A.matrix.broadcastInPlace("HadamardProductBroadcastInPlace", broadcastMul, v.matrix)
+}
+
+// TryAddBroadcast работает так же, как AddBroadcast, но возвращает ошибку,
+// если v нельзя растянуть на размерность A.
+func (A Matrix) TryAddBroadcast(v Matrix) (Matrix, error) {
+	if err := checkBroadcast("AddBroadcast", A.matrix, v.matrix); err != nil {
+		return Matrix{}, err
+	}
+
+	return A.AddBroadcast(v), nil
+}
+
+// TrySubBroadcast работает так же, как SubBroadcast, но возвращает ошибку,
+// если v нельзя растянуть на размерность A.
+func (A Matrix) TrySubBroadcast(v Matrix) (Matrix, error) {
+	if err := checkBroadcast("SubBroadcast", A.matrix, v.matrix); err != nil {
+		return Matrix{}, err
+	}
+
+	return A.SubBroadcast(v), nil
+}
+
+// TryHadamardProductBroadcast работает так же, как HadamardProductBroadcast, но возвращает ошибку,
+// если v нельзя растянуть на размерность A.
+func (A Matrix) TryHadamardProductBroadcast(v Matrix) (Matrix, error) {
+	if err := checkBroadcast("HadamardProductBroadcast", A.matrix, v.matrix); err != nil {
+		return Matrix{}, err
+	}
+
+	return A.HadamardProductBroadcast(v), nil
+}
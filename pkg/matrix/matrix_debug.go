@@ -0,0 +1,67 @@
+package matrix
+
+import "fmt"
+
+/*
+Проверка численной корректности матриц.
+Режим отладки (см. SetDebug) проверяет результат каждой вычислительной операции над матрицами
+и сообщает имя операции, первой получившей NaN или ±Inf, что позволяет найти место расхождения обучения.
+При отключенном режиме проверки не выполняются и не замедляют вычисления.
+*/
+
+// NonFiniteError описание результата операции, содержащего NaN или ±Inf.
+// Передается обработчику режима отладки.
+type NonFiniteError struct {
+	Op    string // Имя операции
+	NaN   int    // Количество элементов NaN в результате
+	Inf   int    // Количество элементов ±Inf в результате
+	Shape Shape  // Размерность результата
+}
+
+// Error возвращает описание ошибки.
+func (e *NonFiniteError) Error() string {
+	return fmt.Sprintf("non-finite result of %s: %d NaN and %d Inf in %d*%d matrix",
+		e.Op, e.NaN, e.Inf, e.Shape.Rows, e.Shape.Columns)
+}
+
+// IsFinite возвращает true, если все элементы матрицы конечны (не NaN и не ±Inf).
+func (M Matrix) IsFinite() bool {
+	nan, inf := M.matrix.countNonFinite()
+	return nan+inf == 0
+}
+
+// CountNaN возвращает количество элементов матрицы, равных NaN.
+func (M Matrix) CountNaN() int {
+	nan, _ := M.matrix.countNonFinite()
+	return nan
+}
+
+// CountInf возвращает количество элементов матрицы, равных +Inf или -Inf.
+func (M Matrix) CountInf() int {
+	_, inf := M.matrix.countNonFinite()
+	return inf
+}
+
+// SetDebug включает режим отладки с обработчиком h: после каждой операции над матрицами,
+// результат которой содержит NaN или ±Inf, вызывается h с описанием операции.
+// h может вызвать панику (см. PanicOnNonFinite), записать сообщение в журнал или сохранить ошибку.
+// SetDebug(nil) отключает режим отладки. Обработчик вызывается в той же горутине, что и операция,
+// поэтому при параллельных вычислениях он должен быть безопасен для одновременного вызова.
+func SetDebug(h func(*NonFiniteError)) {
+	if h == nil {
+		debugHandler.Store(nil)
+		return
+	}
+	debugHandler.Store(&h)
+}
+
+// Debug возвращает true, если режим отладки включен.
+func Debug() bool {
+	return debugHandler.Load() != nil
+}
+
+// PanicOnNonFinite обработчик режима отладки, вызывающий панику с ошибкой err.
+// Используется как SetDebug(PanicOnNonFinite).
+func PanicOnNonFinite(err *NonFiniteError) {
+	panic(err)
+}
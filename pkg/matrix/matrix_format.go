@@ -0,0 +1,117 @@
+package matrix
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+/*
+Вывод матриц в стиле NumPy.
+Matrix реализует fmt.Stringer и fmt.Formatter, поэтому матрицу можно передавать в fmt.Print, fmt.Printf и журналы:
+строки выводятся в квадратных скобках, элементы выровнены по ширине.
+Глаголы %v и %s выводят кратчайшее представление элементов, глаголы %e, %E, %f, %F, %g, %G
+форматируют каждый элемент как число с указанной точностью, например %.3f.
+Флаг + добавляет размерность и тип элементов, флаг # отключает сокращение больших матриц.
+Матрицы, количество элементов которых больше PrintOptions.Threshold, выводятся сокращенно:
+только PrintOptions.EdgeItems первых и последних строк и столбцов.
+*/
+
+// PrintOptions параметры вывода матриц (см. SetPrintOptions).
+type PrintOptions struct {
+	Threshold int // Наибольшее количество элементов матрицы, которая выводится полностью
+	EdgeItems int // Количество первых и последних строк и столбцов, выводимых при сокращении
+}
+
+// DefaultPrintOptions параметры вывода матриц по умолчанию, совпадающие с NumPy.
+var DefaultPrintOptions = PrintOptions{Threshold: 1000, EdgeItems: 3}
+
+// printOptions текущие параметры вывода матриц.
+var printOptions atomic.Pointer[PrintOptions]
+
+func init() {
+	opts := DefaultPrintOptions
+	printOptions.Store(&opts)
+}
+
+// SetPrintOptions устанавливает параметры вывода матриц для всех последующих вызовов String и Format.
+// Функция возвращает ошибку, если Threshold отрицателен или EdgeItems не положителен.
+func SetPrintOptions(opts PrintOptions) error {
+	if opts.Threshold < 0 || opts.EdgeItems <= 0 {
+		return fmt.Errorf("incorrect print options: threshold %d, edge items %d", opts.Threshold, opts.EdgeItems)
+	}
+
+	printOptions.Store(&opts)
+	return nil
+}
+
+// GetPrintOptions возвращает текущие параметры вывода матриц.
+func GetPrintOptions() PrintOptions {
+	return *printOptions.Load()
+}
+
+// String возвращает представление матрицы в стиле NumPy, большие матрицы сокращаются (см. PrintOptions).
+// Для нулевого значения Matrix возвращается "[]".
+func (M Matrix) String() string {
+	if M.matrix == nil {
+		return "[]"
+	}
+	return M.matrix.format('v', -1, true, GetPrintOptions())
+}
+
+// Format реализует интерфейс fmt.Formatter (см. описание вывода матриц выше).
+// Для неподдерживаемых глаголов выводится %!глагол(matrix.Matrix=rows*columns).
+func (M Matrix) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v', 's', 'e', 'E', 'f', 'F', 'g', 'G':
+	default:
+		rows, columns := 0, 0
+		if M.matrix != nil {
+			rows, columns = M.matrix.rows, M.matrix.columns
+		}
+		fmt.Fprintf(f, "%%!%c(matrix.Matrix=%d*%d)", verb, rows, columns)
+		return
+	}
+
+	if M.matrix == nil {
+		fmt.Fprint(f, "[]")
+		return
+	}
+
+	prec, ok := f.Precision()
+	if !ok {
+		prec = -1
+	}
+
+	if f.Flag('+') {
+		fmt.Fprintf(f, "Matrix(%d*%d, %v)\n", M.matrix.rows, M.matrix.columns, M.matrix.dtype)
+	}
+	fmt.Fprint(f, M.matrix.format(verb, prec, !f.Flag('#'), GetPrintOptions()))
+}
+
+// MarshalJSON реализует интерфейс json.Marshaler: матрица записывается как объект
+// {"shape": [rows, columns], "dtype": "float64", "data": [...]} с элементами в порядке строк.
+// Метод возвращает ошибку, если матрица содержит NaN или ±Inf, которые не представимы в JSON.
+func (M Matrix) MarshalJSON() ([]byte, error) {
+	if M.matrix == nil {
+		return []byte("null"), nil
+	}
+	return M.matrix.marshalJSON()
+}
+
+// UnmarshalJSON реализует интерфейс json.Unmarshaler для представления, записанного MarshalJSON.
+// Поле dtype необязательно, по умолчанию элементы float64.
+// Метод возвращает ошибку, если размерность не положительна, количество элементов не соответствует размерности
+// или тип элементов неизвестен. Значение null оставляет матрицу без изменений.
+func (M *Matrix) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+
+	res, err := unmarshalJSON(b)
+	if err != nil {
+		return err
+	}
+
+	M.matrix = res
+	return nil
+}
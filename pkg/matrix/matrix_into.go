@@ -0,0 +1,104 @@
+package matrix
+
+/*
+Операции с суффиксом Into записывают результат в заранее созданную матрицу dst
+и не выделяют память, поэтому подходят для многократно повторяющихся вычислений
+(например, обучения нейронной сети), где матрицы результатов создаются один раз.
+Размерность и тип элементов dst должны совпадать с размерностью и типом элементов результата,
+иначе операции вызывают панику с ошибкой *ShapeError или *DTypeError.
+*/
+
+// DotInto записывает в dst произведение матриц A и B.
+// dst не должна разделять память с A и B.
+// Функция вызывает панику, если исходные матрицы нельзя перемножить по определению,
+// если размерность dst не равна размерности произведения или dst разделяет память с операндом.
+func DotInto(dst, A, B Matrix) {
+	productInto("DotInto", false, false, dst.matrix, A.matrix, B.matrix)
+}
+
+// TDotInto записывает в dst произведение транспонированной матрицы A на матрицу B (см. TDot).
+// dst не должна разделять память с A и B.
+// Функция вызывает панику, если количество строк матриц A и B не совпадает,
+// если размерность dst не равна размерности произведения или dst разделяет память с операндом.
+func TDotInto(dst, A, B Matrix) {
+	productInto("TDotInto", true, false, dst.matrix, A.matrix, B.matrix)
+}
+
+// DotTInto записывает в dst произведение матрицы A на транспонированную матрицу B (см. DotT).
+// dst не должна разделять память с A и B.
+// Функция вызывает панику, если количество столбцов матриц A и B не совпадает,
+// если размерность dst не равна размерности произведения или dst разделяет память с операндом.
+func DotTInto(dst, A, B Matrix) {
+	productInto("DotTInto", false, true, dst.matrix, A.matrix, B.matrix)
+}
+
+// AddInto записывает в dst сумму матриц A и B.
+// dst может совпадать с A или B, но не должна частично перекрывать их (например, как сдвинутое представление SubMatrix).
+// Функция вызывает панику, если размерности исходных матриц и dst не равны или dst частично перекрывает операнд.
+func AddInto(dst, A, B Matrix) {
+	addInto(dst.matrix, A.matrix, B.matrix)
+}
+
+// SubInto записывает в dst разность матриц A и B.
+// dst может совпадать с A или B, но не должна частично перекрывать их (например, как сдвинутое представление SubMatrix).
+// Функция вызывает панику, если размерности исходных матриц и dst не равны или dst частично перекрывает операнд.
+func SubInto(dst, A, B Matrix) {
+	subInto(dst.matrix, A.matrix, B.matrix)
+}
+
+// HadamardProductInto записывает в dst адамарное произведение матриц A и B.
+// dst может совпадать с A или B, но не должна частично перекрывать их (например, как сдвинутое представление SubMatrix).
+// Функция вызывает панику, если размерности исходных матриц и dst не равны или dst частично перекрывает операнд.
+func HadamardProductInto(dst, A, B Matrix) {
+	hadamardProductInto(dst.matrix, A.matrix, B.matrix)
+}
+
+// ForEachInto записывает в dst результат применения к каждому элементу матрицы M функции f func(float64) float64.
+// dst может совпадать с M, но не должна частично перекрывать ее.
+// Функция вызывает панику, если размерности dst и M не равны или dst частично перекрывает M.
+func ForEachInto(dst, M Matrix, f func(float64) float64) {
+	forEachInto(dst.matrix, M.matrix, f)
+}
+
+// TInto записывает в dst транспонированную матрицу M.
+// dst не должна разделять память с M.
+// Функция вызывает панику, если размерность dst не равна транспонированной размерности M
+// или dst разделяет память с M.
+func TInto(dst, M Matrix) {
+	tInto(dst.matrix, M.matrix)
+}
+
+// DotSparseInto записывает в dst произведение матрицы A на разреженную матрицу S (см. DotSparse).
+// dst не должна разделять память с A.
+// Функция вызывает панику, если исходные матрицы нельзя перемножить по определению
+// или размерность dst не равна размерности произведения.
+func DotSparseInto(dst, A Matrix, S Sparse) {
+	dotSparseInto(dst.matrix, A.matrix, S.matrix, false)
+}
+
+// DotTSparseInto записывает в dst произведение матрицы A на транспонированную разреженную матрицу S (см. DotTSparse).
+// dst не должна разделять память с A.
+// Функция вызывает панику, если количество столбцов матриц A и S не совпадает
+// или размерность dst не равна размерности произведения.
+func DotTSparseInto(dst, A Matrix, S Sparse) {
+	dotSparseInto(dst.matrix, A.matrix, S.matrix, true)
+}
+
+// ScaleInPlace умножает каждый элемент матрицы M на число k.
+// Результат сохраняется в M, изменяя ее.
+func (M Matrix) ScaleInPlace(k float64) {
+	M.matrix.scaleInPlace(k)
+}
+
+// AddScaledInPlace прибавляет к матрице A матрицу B, умноженную на число k.
+// Результат сохраняется в A, изменяя ее.
+// Метод вызывает панику, если размерности исходных матриц не равны или B частично перекрывает A.
+func (A Matrix) AddScaledInPlace(k float64, B Matrix) {
+	A.matrix.addScaledInPlace(k, B.matrix)
+}
+
+// Fill записывает во все элементы матрицы M число x.
+// Результат сохраняется в M, изменяя ее.
+func (M Matrix) Fill(x float64) {
+	M.matrix.fill(x)
+}
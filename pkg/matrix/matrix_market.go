@@ -0,0 +1,47 @@
+package matrix
+
+import "io"
+
+// ReadMatrixMarket возвращает плотную матрицу (структуру Matrix) с элементами float64,
+// прочитанную из потока в формате Matrix Market, и ошибку, если она возникла.
+// Поддерживаются форматы coordinate (элементы real, integer или pattern) и array (элементы real или integer)
+// с симметрией general, symmetric и skew-symmetric. Повторяющиеся элементы формата coordinate суммируются.
+// Функция возвращает *ParseError с номером строки, если файл имеет неподдерживаемый заголовок,
+// строка содержит некорректные числа или индексы вне матрицы, количество элементов не совпадает с указанным
+// или матрица имеет больше math.MaxInt32 элементов.
+// Функция не открывает и не закрывает поток.
+func ReadMatrixMarket(reader io.Reader) (Matrix, error) {
+	M, err := readMatrixMarket(reader, true)
+	if err != nil {
+		return Matrix{}, err
+	}
+
+	return Matrix{
+		matrix: M.toMatrix(),
+	}, nil
+}
+
+// ReadSparseMatrixMarket работает так же, как ReadMatrixMarket, но возвращает разреженную матрицу (структуру Sparse),
+// что позволяет читать большие разреженные матрицы формата coordinate без выделения памяти под все элементы.
+func ReadSparseMatrixMarket(reader io.Reader) (Sparse, error) {
+	M, err := readMatrixMarket(reader, false)
+	if err != nil {
+		return Sparse{}, err
+	}
+
+	return SparseFromTriplets(M.rows, M.columns, M.rowIdx, M.colIdx, M.values)
+}
+
+// WriteMatrixMarket записывает матрицу M в поток в формате Matrix Market array real general
+// (элементы по столбцам) без потери точности и возвращает ошибку, если запись не удалась.
+// Функция не открывает и не закрывает поток.
+func WriteMatrixMarket(writer io.Writer, M Matrix) error {
+	return writeMatrixMarket(writer, M.matrix)
+}
+
+// WriteSparseMatrixMarket записывает разреженную матрицу S в поток в формате Matrix Market coordinate real general
+// без потери точности и возвращает ошибку, если запись не удалась.
+// Функция не открывает и не закрывает поток.
+func WriteSparseMatrixMarket(writer io.Writer, S Sparse) error {
+	return writeSparseMatrixMarket(writer, S.matrix)
+}
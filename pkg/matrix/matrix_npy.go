@@ -0,0 +1,96 @@
+package matrix
+
+import (
+	"io"
+	"os"
+)
+
+/*
+Обмен матрицами с NumPy.
+Матрица записывается в формат .npy как двумерный массив в порядке строк
+с элементами little-endian float64 ('<f8') или float32 ('<f4'), numpy.load читает его без преобразований.
+Архив .npz является zip архивом из файлов .npy, как у numpy.savez и numpy.savez_compressed.
+*/
+
+// WriteNpy записывает матрицу M в поток writer в формате .npy и возвращает ошибку, если она возникла при записи.
+// Функция не открывает и не закрывает поток вывода.
+func WriteNpy(writer io.Writer, M Matrix) error {
+	return writeNpy(writer, M.matrix)
+}
+
+// ReadNpy читает матрицу из потока reader в формате .npy версий 1.0, 2.0 и 3.0
+// и возвращает ее и ошибку, если она возникла при чтении.
+// Поддерживаются элементы float64 и float32 (тип элементов матрицы совпадает с типом массива)
+// с любым порядком байтов и порядок элементов по строкам (C order) и по столбцам (Fortran order).
+// Одномерный массив длины n читается как вектор размерности n на 1, скаляр как матрица 1 на 1.
+// Функция возвращает ошибку, если массив имеет больше двух измерений, нулевой размер
+// или другой тип элементов.
+func ReadNpy(reader io.Reader) (Matrix, error) {
+	M, err := readNpy(reader)
+	if err != nil {
+		return Matrix{}, err
+	}
+
+	return Matrix{
+		matrix: M,
+	}, nil
+}
+
+// WriteNpz записывает матрицы arrays в поток writer в формате .npz без сжатия,
+// каждая матрица записывается в файл архива с именем ключа и расширением .npy.
+// Функция возвращает ошибку, если она возникла при записи.
+func WriteNpz(writer io.Writer, arrays map[string]Matrix) error {
+	arraysImp := make(map[string]*myMatrix, len(arrays))
+	for name, M := range arrays {
+		arraysImp[name] = M.matrix
+	}
+
+	return writeNpz(writer, arraysImp)
+}
+
+// ReadNpz читает матрицы из архива .npz размером size байт (см. ReadNpy)
+// и возвращает их по именам файлов архива без расширения .npy и ошибку, если она возникла при чтении.
+func ReadNpz(reader io.ReaderAt, size int64) (map[string]Matrix, error) {
+	arraysImp, err := readNpz(reader, size)
+	if err != nil {
+		return nil, err
+	}
+
+	arrays := make(map[string]Matrix, len(arraysImp))
+	for name, M := range arraysImp {
+		arrays[name] = Matrix{matrix: M}
+	}
+
+	return arrays, nil
+}
+
+// WriteNpzFile записывает матрицы arrays в файл filename в формате .npz (см. WriteNpz).
+func WriteNpzFile(filename string, arrays map[string]Matrix) error {
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+
+	if err := WriteNpz(file, arrays); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// ReadNpzFile читает матрицы из файла filename в формате .npz (см. ReadNpz).
+func ReadNpzFile(filename string) (map[string]Matrix, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return ReadNpz(file, info.Size())
+}
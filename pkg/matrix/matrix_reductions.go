@@ -0,0 +1,121 @@
+package matrix
+
+// Axis направление свертки матрицы.
+type Axis int
+
+const (
+	// AxisRows свертка каждой строки: результат является вектором-столбцом размерности rows на 1,
+	// i элемент которого получен из элементов i строки.
+	AxisRows Axis = iota
+	// AxisColumns свертка каждого столбца: результат является вектором-строкой размерности 1 на columns,
+	// j элемент которого получен из элементов j столбца.
+	AxisColumns
+)
+
+/*
+Свертки элементов матрицы.
+Методы без суффикса Axis сворачивают все элементы матрицы в одно число,
+методы с суффиксом Axis сворачивают каждую строку или каждый столбец (см. Axis)
+и возвращают матрицу с тем же типом элементов, что и исходная.
+Промежуточные вычисления выполняются в float64 для любого типа элементов.
+NaN распространяется, как в NumPy: Max и Min (MaxAxis и MinAxis) возвращают NaN, если среди
+сворачиваемых элементов есть NaN, а ArgMax и ArgMin (ArgMaxAxis и ArgMinAxis) возвращают индекс первого NaN,
+независимо от порядка элементов.
+Методы с суффиксом Axis вызывают панику, если направление axis не определено.
+*/
+
+// Sum возвращает сумму всех элементов матрицы M.
+func (M Matrix) Sum() float64 {
+	return M.matrix.sum()
+}
+
+// SumAxis возвращает матрицу сумм элементов каждой строки или каждого столбца матрицы M.
+func (M Matrix) SumAxis(axis Axis) Matrix {
+	return Matrix{
+		matrix: M.matrix.sumAxis(axis),
+	}
+}
+
+// Mean возвращает среднее арифметическое всех элементов матрицы M.
+func (M Matrix) Mean() float64 {
+	return M.matrix.mean()
+}
+
+// MeanAxis возвращает матрицу средних арифметических элементов каждой строки или каждого столбца матрицы M.
+func (M Matrix) MeanAxis(axis Axis) Matrix {
+	return Matrix{
+		matrix: M.matrix.meanAxis(axis),
+	}
+}
+
+// Max возвращает наибольший элемент матрицы M.
+func (M Matrix) Max() float64 {
+	_, _, x := M.matrix.argBest(greater)
+	return x
+}
+
+// MaxAxis возвращает матрицу наибольших элементов каждой строки или каждого столбца матрицы M.
+func (M Matrix) MaxAxis(axis Axis) Matrix {
+	_, vals := M.matrix.argBestAxis(axis, greater)
+	return Matrix{
+		matrix: axisMatrix(axis, vals, M.matrix.dtype),
+	}
+}
+
+// Min возвращает наименьший элемент матрицы M.
+func (M Matrix) Min() float64 {
+	_, _, x := M.matrix.argBest(less)
+	return x
+}
+
+// MinAxis возвращает матрицу наименьших элементов каждой строки или каждого столбца матрицы M.
+func (M Matrix) MinAxis(axis Axis) Matrix {
+	_, vals := M.matrix.argBestAxis(axis, less)
+	return Matrix{
+		matrix: axisMatrix(axis, vals, M.matrix.dtype),
+	}
+}
+
+// ArgMax возвращает индексы строки и столбца наибольшего элемента матрицы M.
+// Если наибольших элементов несколько, то возвращается первый из них в порядке строк.
+// Для вектора (матрицы размерности n на 1) индекс строки является индексом наибольшего элемента,
+// например номером класса с наибольшей вероятностью на выходе нейронной сети.
+func (M Matrix) ArgMax() (int, int) {
+	i, j, _ := M.matrix.argBest(greater)
+	return i, j
+}
+
+// ArgMaxAxis возвращает индексы наибольших элементов каждой строки (номера столбцов, AxisRows)
+// или каждого столбца (номера строк, AxisColumns) матрицы M.
+// Если наибольших элементов несколько, то возвращается индекс первого из них.
+func (M Matrix) ArgMaxAxis(axis Axis) []int {
+	idx, _ := M.matrix.argBestAxis(axis, greater)
+	return idx
+}
+
+// ArgMin возвращает индексы строки и столбца наименьшего элемента матрицы M.
+// Если наименьших элементов несколько, то возвращается первый из них в порядке строк.
+func (M Matrix) ArgMin() (int, int) {
+	i, j, _ := M.matrix.argBest(less)
+	return i, j
+}
+
+// ArgMinAxis возвращает индексы наименьших элементов каждой строки (номера столбцов, AxisRows)
+// или каждого столбца (номера строк, AxisColumns) матрицы M.
+// Если наименьших элементов несколько, то возвращается индекс первого из них.
+func (M Matrix) ArgMinAxis(axis Axis) []int {
+	idx, _ := M.matrix.argBestAxis(axis, less)
+	return idx
+}
+
+// Norm возвращает евклидову (фробениусову) норму матрицы M: корень из суммы квадратов всех элементов.
+func (M Matrix) Norm() float64 {
+	return M.matrix.norm()
+}
+
+// NormAxis возвращает матрицу евклидовых норм каждой строки или каждого столбца матрицы M.
+func (M Matrix) NormAxis(axis Axis) Matrix {
+	return Matrix{
+		matrix: M.matrix.normAxis(axis),
+	}
+}
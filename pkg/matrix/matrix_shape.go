@@ -0,0 +1,138 @@
+package matrix
+
+/*
+Изменение формы, объединение матриц и представления частей матрицы.
+Представления (Row, Col, SubMatrix и Reshape матрицы со строками подряд в памяти) разделяют память
+с исходной матрицей: изменение элементов представления изменяет исходную матрицу и наоборот.
+Независимую копию можно получить методом AsType с типом элементов исходной матрицы.
+*/
+
+// Reshape возвращает матрицу размерности rows на columns с элементами матрицы M в порядке строк.
+// Если строки M лежат в памяти подряд (M не является представлением части столбцов другой матрицы),
+// результат разделяет память с M, иначе является копией.
+// Метод вызывает панику, если rows * columns не равно количеству элементов M.
+func (M Matrix) Reshape(rows, columns int) Matrix {
+	return Matrix{
+		matrix: M.matrix.reshape(rows, columns),
+	}
+}
+
+// Flatten возвращает вектор размерности n на 1 из элементов матрицы M в порядке строк,
+// где n количество элементов M. Результат всегда является копией.
+func (M Matrix) Flatten() Matrix {
+	return Matrix{
+		matrix: M.matrix.copy().reshape(M.matrix.rows*M.matrix.columns, 1),
+	}
+}
+
+// Row возвращает i строку матрицы M, матрицу размерности 1 на columns, разделяющую память с M.
+// Метод вызывает панику, если строки с индексом i нет.
+func (M Matrix) Row(i int) Matrix {
+	return M.SubMatrix(i, 0, 1, M.matrix.columns)
+}
+
+// Col возвращает j столбец матрицы M, вектор размерности rows на 1, разделяющий память с M.
+// Метод вызывает панику, если столбца с индексом j нет.
+func (M Matrix) Col(j int) Matrix {
+	return M.SubMatrix(0, j, M.matrix.rows, 1)
+}
+
+// SubMatrix возвращает блок матрицы M размерности rows на columns, начинающийся с элемента i, j.
+// Блок разделяет память с M.
+// Метод вызывает панику, если блок не лежит внутри матрицы M.
+func (M Matrix) SubMatrix(i, j, rows, columns int) Matrix {
+	must(M.matrix.checkBlock("SubMatrix", i, j, rows, columns))
+
+	return Matrix{
+		matrix: M.matrix.view(i, j, rows, columns),
+	}
+}
+
+// HStack возвращает матрицу, в которой матрицы ms расположены слева направо.
+// Результат является копией. Количество строк всех матриц должно совпадать.
+// Функция вызывает панику, если матриц нет, количество строк или типы элементов матриц различаются.
+func HStack(ms ...Matrix) Matrix {
+	return Matrix{
+		matrix: stack("HStack", AxisRows, convertToMatrixImpSlice(ms)),
+	}
+}
+
+// VStack возвращает матрицу, в которой матрицы ms расположены сверху вниз.
+// Результат является копией. Количество столбцов всех матриц должно совпадать,
+// например из векторов-строк наблюдений VStack строит матрицу мини батча.
+// Функция вызывает панику, если матриц нет, количество столбцов или типы элементов матриц различаются.
+func VStack(ms ...Matrix) Matrix {
+	return Matrix{
+		matrix: stack("VStack", AxisColumns, convertToMatrixImpSlice(ms)),
+	}
+}
+
+// Concat объединяет матрицы ms по направлению axis:
+// при AxisRows удлиняется каждая строка (как HStack), при AxisColumns удлиняется каждый столбец (как VStack).
+// Функция вызывает панику, если направление axis не определено или матрицы нельзя объединить.
+func Concat(axis Axis, ms ...Matrix) Matrix {
+	return Matrix{
+		matrix: stack("Concat", axis, convertToMatrixImpSlice(ms)),
+	}
+}
+
+// TryReshape работает так же, как Reshape, но возвращает *ShapeError,
+// если rows * columns не равно количеству элементов M.
+func (M Matrix) TryReshape(rows, columns int) (Matrix, error) {
+	if err := M.matrix.checkReshape(rows, columns); err != nil {
+		return Matrix{}, err
+	}
+
+	return M.Reshape(rows, columns), nil
+}
+
+// TrySubMatrix работает так же, как SubMatrix, но возвращает *IndexError,
+// если блок не лежит внутри матрицы M.
+func (M Matrix) TrySubMatrix(i, j, rows, columns int) (Matrix, error) {
+	if err := M.matrix.checkBlock("SubMatrix", i, j, rows, columns); err != nil {
+		return Matrix{}, err
+	}
+
+	return M.SubMatrix(i, j, rows, columns), nil
+}
+
+// TryRow работает так же, как Row, но возвращает *IndexError, если строки с индексом i нет.
+func (M Matrix) TryRow(i int) (Matrix, error) {
+	return M.TrySubMatrix(i, 0, 1, M.matrix.columns)
+}
+
+// TryCol работает так же, как Col, но возвращает *IndexError, если столбца с индексом j нет.
+func (M Matrix) TryCol(j int) (Matrix, error) {
+	return M.TrySubMatrix(0, j, M.matrix.rows, 1)
+}
+
+// TryHStack работает так же, как HStack, но возвращает *ShapeError,
+// если матриц нет или количество строк матриц различается, и *DTypeError, если различаются типы элементов.
+func TryHStack(ms ...Matrix) (Matrix, error) {
+	if err := checkStack("HStack", AxisRows, convertToMatrixImpSlice(ms)); err != nil {
+		return Matrix{}, err
+	}
+
+	return HStack(ms...), nil
+}
+
+// TryVStack работает так же, как VStack, но возвращает *ShapeError,
+// если матриц нет или количество столбцов матриц различается, и *DTypeError, если различаются типы элементов.
+func TryVStack(ms ...Matrix) (Matrix, error) {
+	if err := checkStack("VStack", AxisColumns, convertToMatrixImpSlice(ms)); err != nil {
+		return Matrix{}, err
+	}
+
+	return VStack(ms...), nil
+}
+
+// TryConcat работает так же, как Concat, но возвращает *ShapeError,
+// если матрицы нельзя объединить по направлению axis, и *DTypeError, если различаются типы элементов.
+// Функция вызывает панику, если направление axis не определено.
+func TryConcat(axis Axis, ms ...Matrix) (Matrix, error) {
+	if err := checkStack("Concat", axis, convertToMatrixImpSlice(ms)); err != nil {
+		return Matrix{}, err
+	}
+
+	return Concat(axis, ms...), nil
+}
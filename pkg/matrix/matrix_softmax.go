@@ -0,0 +1,134 @@
+package matrix
+
+/*
+Softmax, log-softmax и log-sum-exp вычисляются по столбцам: каждый столбец является вектором логитов
+одного наблюдения (как векторы размерности n на 1 в нейронной сети).
+Из каждого столбца вычитается его наибольший элемент, поэтому большие логиты не приводят к переполнению.
+Методы с суффиксом JVP возвращают производную функции в точке M по направлению V
+(произведение матрицы Якоби на V для каждого столбца).
+Матрица Якоби softmax симметрична, поэтому SoftmaxJVP также является произведением V на матрицу Якоби,
+которое используется при обратном распространении ошибки.
+*/
+
+// Softmax возвращает матрицу, каждый столбец которой равен softmax соответствующего столбца M:
+// exp(x_i) / sum_k exp(x_k). Элементы каждого столбца результата положительны и в сумме дают 1.
+func (M Matrix) Softmax() Matrix {
+	C := M.matrix.softmax(opSoftmax)
+	debugCheck("Softmax", C)
+
+	return Matrix{
+		matrix: C,
+	}
+}
+
+// SoftmaxInto записывает в dst softmax столбцов матрицы M (см. Softmax).
+// dst может совпадать с M, но не должна частично перекрывать ее. В отличие от остальных операций Into
+// выделяет память под статистики столбцов (два слайса длины columns), но не под результат.
+// Функция вызывает панику, если размерность или тип элементов dst и M различаются или dst частично перекрывает M.
+func SoftmaxInto(dst, M Matrix) {
+	must(checkDst("SoftmaxInto", dst.matrix, M.matrix.rows, M.matrix.columns, M.matrix.dtype))
+	must(checkOverlap("SoftmaxInto", dst.matrix, M.matrix))
+
+	dst.matrix.softmaxInto(opSoftmax, M.matrix)
+	debugCheck("SoftmaxInto", dst.matrix)
+}
+
+// LogSoftmax возвращает матрицу, каждый столбец которой равен логарифму softmax соответствующего столбца M:
+// x_i - log(sum_k exp(x_k)). В отличие от логарифма Softmax не обращается в -Inf при малых вероятностях.
+func (M Matrix) LogSoftmax() Matrix {
+	C := M.matrix.softmax(opLogSoftmax)
+	debugCheck("LogSoftmax", C)
+
+	return Matrix{
+		matrix: C,
+	}
+}
+
+// LogSumExp возвращает вектор-строку размерности 1 на columns, j элемент которой равен
+// log(sum_k exp(x_k)) по элементам j столбца M.
+func (M Matrix) LogSumExp() Matrix {
+	C := M.matrix.logSumExp()
+	debugCheck("LogSumExp", C)
+
+	return Matrix{
+		matrix: C,
+	}
+}
+
+// SoftmaxJVP возвращает производную Softmax в точке M по направлению V: s ⊙ (v - <s, v>) для каждого столбца,
+// где s softmax столбца M, v соответствующий столбец V.
+// Метод вызывает панику, если размерности или типы элементов M и V различаются.
+func (M Matrix) SoftmaxJVP(V Matrix) Matrix {
+	must(checkSameShape("SoftmaxJVP", M.matrix, V.matrix))
+
+	return Matrix{
+		matrix: M.matrix.softmaxJVP(opSoftmax, V.matrix),
+	}
+}
+
+// SoftmaxJVPInto записывает в dst производную Softmax в точке M по направлению V (см. SoftmaxJVP).
+// dst может совпадать с V (но не перекрывать ее частично), но не с M.
+// Как и SoftmaxInto, выделяет память только под статистики столбцов.
+// Функция вызывает панику, если размерности или типы элементов dst, M и V различаются,
+// dst разделяет память с M или частично перекрывает V.
+func SoftmaxJVPInto(dst, M, V Matrix) {
+	must(checkSameShape("SoftmaxJVPInto", M.matrix, V.matrix))
+	must(checkDst("SoftmaxJVPInto", dst.matrix, M.matrix.rows, M.matrix.columns, M.matrix.dtype))
+	must(checkAlias("SoftmaxJVPInto", dst.matrix, M.matrix))
+	must(checkOverlap("SoftmaxJVPInto", dst.matrix, V.matrix))
+
+	dst.matrix.softmaxJVPInto(opSoftmax, M.matrix, V.matrix)
+	debugCheck("SoftmaxJVPInto", dst.matrix)
+}
+
+// LogSoftmaxJVP возвращает производную LogSoftmax в точке M по направлению V: v - <s, v> для каждого столбца,
+// где s softmax столбца M, v соответствующий столбец V.
+// Метод вызывает панику, если размерности или типы элементов M и V различаются.
+func (M Matrix) LogSoftmaxJVP(V Matrix) Matrix {
+	must(checkSameShape("LogSoftmaxJVP", M.matrix, V.matrix))
+
+	return Matrix{
+		matrix: M.matrix.softmaxJVP(opLogSoftmax, V.matrix),
+	}
+}
+
+// LogSumExpJVP возвращает вектор-строку производных LogSumExp в точке M по направлению V:
+// <s, v> для каждого столбца, где s softmax столбца M, v соответствующий столбец V.
+// Метод вызывает панику, если размерности или типы элементов M и V различаются.
+func (M Matrix) LogSumExpJVP(V Matrix) Matrix {
+	must(checkSameShape("LogSumExpJVP", M.matrix, V.matrix))
+
+	return Matrix{
+		matrix: M.matrix.logSumExpJVP(V.matrix),
+	}
+}
+
+// TrySoftmaxJVP работает так же, как SoftmaxJVP, но возвращает ошибку,
+// если размерности или типы элементов M и V различаются.
+func (M Matrix) TrySoftmaxJVP(V Matrix) (Matrix, error) {
+	if err := checkSameShape("SoftmaxJVP", M.matrix, V.matrix); err != nil {
+		return Matrix{}, err
+	}
+
+	return M.SoftmaxJVP(V), nil
+}
+
+// TryLogSoftmaxJVP работает так же, как LogSoftmaxJVP, но возвращает ошибку,
+// если размерности или типы элементов M и V различаются.
+func (M Matrix) TryLogSoftmaxJVP(V Matrix) (Matrix, error) {
+	if err := checkSameShape("LogSoftmaxJVP", M.matrix, V.matrix); err != nil {
+		return Matrix{}, err
+	}
+
+	return M.LogSoftmaxJVP(V), nil
+}
+
+// TryLogSumExpJVP работает так же, как LogSumExpJVP, но возвращает ошибку,
+// если размерности или типы элементов M и V различаются.
+func (M Matrix) TryLogSumExpJVP(V Matrix) (Matrix, error) {
+	if err := checkSameShape("LogSumExpJVP", M.matrix, V.matrix); err != nil {
+		return Matrix{}, err
+	}
+
+	return M.LogSumExpJVP(V), nil
+}
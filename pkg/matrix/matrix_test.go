@@ -1,7 +1,15 @@
 package matrix
 
 import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"math"
+	"math/rand"
 	"testing"
 )
 
@@ -34,6 +42,22 @@ func TestRandMatrix(t *testing.T) {
 
 }
 
+// TestRandMatrixFrom проверяет, что генераторы с одинаковым начальным значением дают одинаковые матрицы.
+func TestRandMatrixFrom(t *testing.T) {
+	for _, dtype := range []DType{Float64, Float32} {
+		A := RandMatrixFrom(5, 7, dtype, rand.New(rand.NewSource(42)))
+		B := RandMatrixFrom(5, 7, dtype, rand.New(rand.NewSource(42)))
+		C := RandMatrixFrom(5, 7, dtype, rand.New(rand.NewSource(43)))
+
+		if !IsMatrixesEqual(A, B) {
+			t.Errorf("RandMatrixFrom (%v): matrices from equal seeds are different", dtype)
+		}
+		if IsMatrixesEqual(A, C) {
+			t.Errorf("RandMatrixFrom (%v): matrices from different seeds are equal", dtype)
+		}
+	}
+}
+
 // TestZero проверяет нулевую матрицу данной размерности,
 // так же функция перехватывает панику при нулевой или отрицательной размерности матрицы
 func TestZero(t *testing.T) {
@@ -92,6 +116,145 @@ func TestDot(t *testing.T) {
 
 }
 
+// TestTDot проверяет произведение транспонированной матрицы на матрицу (структур Matrix)
+func TestTDot(t *testing.T) {
+	A := DataToMatrix([][]float64{
+		{1., 3., 5.},
+		{2., 4., 6.},
+	})
+
+	B := DataToMatrix([][]float64{
+		{1., 2., 3., 10.},
+		{4., 5., 6., 9.},
+	})
+
+	expected := DataToMatrix([][]float64{
+		{9., 12., 15., 28.},
+		{19., 26., 33., 66.},
+		{29., 40., 51., 104.},
+	})
+
+	result := A.TDot(B)
+
+	// проверка на равность
+	if !IsMatrixesEqual(result, expected) {
+		t.Errorf("Matrix transposed multiplication error: Result != Expected")
+	}
+}
+
+// TestDotT проверяет произведение матрицы на транспонированную матрицу (структур Matrix)
+func TestDotT(t *testing.T) {
+	A := DataToMatrix([][]float64{
+		{1., 2.},
+		{3., 4.},
+		{5., 6.},
+	})
+
+	B := DataToMatrix([][]float64{
+		{1., 4.},
+		{2., 5.},
+		{3., 6.},
+		{10., 9.},
+	})
+
+	expected := DataToMatrix([][]float64{
+		{9., 12., 15., 28.},
+		{19., 26., 33., 66.},
+		{29., 40., 51., 104.},
+	})
+
+	result := A.DotT(B)
+
+	// проверка на равность
+	if !IsMatrixesEqual(result, expected) {
+		t.Errorf("Matrix multiplication by transposed error: Result != Expected")
+	}
+}
+
+// TestDotBlocked проверяет блочное параллельное произведение матриц (структур Matrix) размеров,
+// не кратных размерам блоков, сравнивая его с произведением по определению.
+// Произведение проверяется при разном количестве исполнителей и для всех вариантов транспонирования.
+func TestDotBlocked(t *testing.T) {
+	defer SetNumWorkers(0)
+
+	A := RandMatrix(131, 301)
+	B := RandMatrix(301, 263)
+
+	// произведение по определению
+	expected := Zero(A.GetRows(), B.GetColumns())
+	for i := 0; i < A.GetRows(); i++ {
+		for j := 0; j < B.GetColumns(); j++ {
+			sum := 0.
+			for k := 0; k < A.GetColumns(); k++ {
+				sum += A.GetIJ(i, k) * B.GetIJ(k, j)
+			}
+			expected.SetIJ(i, j, sum)
+		}
+	}
+
+	for _, workers := range []int{1, 3, 8} {
+		SetNumWorkers(workers)
+
+		if NumWorkers() != workers {
+			t.Errorf("Expected %d workers, got %d", workers, NumWorkers())
+		}
+
+		if !IsMatrixesEqual(A.Dot(B), expected) {
+			t.Errorf("Blocked multiplication error with %d workers: Result != Expected", workers)
+		}
+
+		if !IsMatrixesEqual(A.T().TDot(B), expected) {
+			t.Errorf("Blocked transposed multiplication error with %d workers: Result != Expected", workers)
+		}
+
+		if !IsMatrixesEqual(A.DotT(B.T()), expected) {
+			t.Errorf("Blocked multiplication by transposed error with %d workers: Result != Expected", workers)
+		}
+	}
+}
+
+// TestDotNonFinite проверяет, что блочное произведение совпадает с произведением по определению (реализация "naive")
+// при нулевых элементах A и бесконечных или NaN элементах B: 0·Inf и 0·NaN дают NaN по IEEE 754.
+func TestDotNonFinite(t *testing.T) {
+	defer SetBackend(CurrentBackend().Name())
+
+	A := RandMatrix(5, 7)
+	for i := 0; i < A.GetRows(); i++ {
+		A.SetIJ(i, 2, 0)
+	}
+	A.SetIJ(1, 4, 0)
+	B := RandMatrix(7, 6)
+	B.SetIJ(2, 1, math.Inf(1))
+	B.SetIJ(4, 3, math.NaN())
+	B.SetIJ(2, 5, math.Inf(-1))
+
+	products := func() []Matrix {
+		return []Matrix{A.Dot(B), A.T().TDot(B), A.DotT(B.T()), A.T().TDot(B.T().T())}
+	}
+
+	if err := SetBackend("naive"); err != nil {
+		t.Fatal(err)
+	}
+	expected := products()
+	if err := SetBackend("blocked"); err != nil {
+		t.Fatal(err)
+	}
+
+	for n, C := range products() {
+		if expected[n].CountNaN() == 0 {
+			t.Fatalf("Expected NaN in naive product %d", n)
+		}
+		for i := 0; i < C.GetRows(); i++ {
+			for j := 0; j < C.GetColumns(); j++ {
+				got, want := C.GetIJ(i, j), expected[n].GetIJ(i, j)
+				if math.IsNaN(got) != math.IsNaN(want) || !math.IsNaN(want) && math.Abs(got-want) > 1e-12 {
+					t.Errorf("Product %d: element (%d, %d): blocked %v != naive %v", n, i, j, got, want)
+				}
+			}
+		}
+	}
+}
+
 // TestAdd проверяет сложение матриц (структур Matrix)
 func TestAdd(t *testing.T) {
 	A := DataToMatrix([][]float64{
@@ -399,3 +562,1624 @@ func TestVec2Dig(t *testing.T) {
 	}
 
 }
+
+// TestFloat32 проверяет операции над матрицами (структурами Matrix) с элементами float32,
+// сравнивая их с теми же операциями над матрицами с элементами float64.
+func TestFloat32(t *testing.T) {
+	A := RandMatrix(70, 300)
+	B := RandMatrix(300, 65)
+	C := RandMatrix(70, 300)
+
+	A32, B32, C32 := A.AsType(Float32), B.AsType(Float32), C.AsType(Float32)
+
+	if A32.DType() != Float32 || A.DType() != Float64 {
+		t.Fatalf("Incorrect element types after conversion")
+	}
+
+	pairs := []struct {
+		name           string
+		result, expect Matrix
+	}{
+		{"Dot", A32.Dot(B32), A.Dot(B)},
+		{"TDot", A32.TDot(C32), A.TDot(C)},
+		{"DotT", A32.DotT(C32), A.DotT(C)},
+		{"Add", A32.Add(C32), A.Add(C)},
+		{"Sub", A32.Sub(C32), A.Sub(C)},
+		{"HadamardProduct", A32.HadamardProduct(C32), A.HadamardProduct(C)},
+		{"T", A32.T(), A.T()},
+		{"ForEach", A32.ForEach(square), A.ForEach(square)},
+	}
+
+	for _, p := range pairs {
+		if p.result.DType() != Float32 {
+			t.Errorf("%s: expected float32 result, got %v", p.name, p.result.DType())
+		}
+
+		if !isMatrixesClose(p.result, p.expect, 1e-5) {
+			t.Errorf("%s with float32 elements error: Result != Expected", p.name)
+		}
+	}
+
+	// операции над матрицами разных типов элементов запрещены
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("The code did not panic on different element types")
+		}
+	}()
+
+	_ = A32.Add(C)
+}
+
+// TestWriteReadFloat32 проверяет запись и чтение матриц (структур Matrix) с разными типами элементов.
+func TestWriteReadFloat32(t *testing.T) {
+	matrixes := []Matrix{
+		DataToMatrix([][]float64{{1.5, -2.25}, {3., 4.125}}).AsType(Float32),
+		DataToMatrix([][]float64{{0.5}, {7.}}),
+	}
+
+	buf := new(bytes.Buffer)
+	if err := WriteMatrixes(buf, matrixes); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ReadMatrixes(bufio.NewScanner(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result) != len(matrixes) {
+		t.Fatalf("Expected %d matrixes, got %d", len(matrixes), len(result))
+	}
+
+	for i := range matrixes {
+		if result[i].DType() != matrixes[i].DType() {
+			t.Errorf("Matrix %d: expected element type %v, got %v", i, matrixes[i].DType(), result[i].DType())
+		}
+
+		if !IsMatrixesEqual(result[i], matrixes[i]) {
+			t.Errorf("Matrix %d: Result != Expected", i)
+		}
+	}
+}
+
+// isMatrixesClose вспомогательная функция для тестов.
+// Возвращает true, если размерности матриц равны, а элементы отличаются не более чем на tol
+// относительно величины элементов.
+func isMatrixesClose(A, B Matrix, tol float64) bool {
+	if A.GetRows() != B.GetRows() || A.GetColumns() != B.GetColumns() {
+		return false
+	}
+
+	for i := 0; i < A.GetRows(); i++ {
+		for j := 0; j < A.GetColumns(); j++ {
+			a, b := A.GetIJ(i, j), B.GetIJ(i, j)
+			if math.Abs(a-b) > tol*(1+math.Abs(b)) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// BenchmarkDotLayer измеряет произведение матрицы весов на вектор активаций
+// размерности скрытого слоя сети для MNIST (30 на 784 умножить на 784 на 1).
+func BenchmarkDotLayer(b *testing.B) {
+	W := RandMatrix(30, 784)
+	x := RandMatrix(784, 1)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = W.Dot(x)
+	}
+}
+
+// BenchmarkDotSquare измеряет произведение двух квадратных матриц 256 на 256.
+func BenchmarkDotSquare(b *testing.B) {
+	A := RandMatrix(256, 256)
+	B := RandMatrix(256, 256)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = A.Dot(B)
+	}
+}
+
+// BenchmarkTDotLayer измеряет произведение транспонированной матрицы весов на вектор ошибки
+// (30 на 784 транспонированная умножить на 30 на 1), как при обратном распространении.
+func BenchmarkTDotLayer(b *testing.B) {
+	W := RandMatrix(30, 784)
+	delta := RandMatrix(30, 1)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = W.TDot(delta)
+	}
+}
+
+// BenchmarkDotTLayer измеряет внешнее произведение вектора ошибки на вектор активаций
+// (30 на 1 умножить на транспонированную 784 на 1), как при обратном распространении.
+func BenchmarkDotTLayer(b *testing.B) {
+	delta := RandMatrix(30, 1)
+	a := RandMatrix(784, 1)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = delta.DotT(a)
+	}
+}
+
+// BenchmarkForEachInPlace измеряет поэлементное применение функции к матрице весов 30 на 784.
+func BenchmarkForEachInPlace(b *testing.B) {
+	W := RandMatrix(30, 784)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		W.ForEachInPlace(square)
+	}
+}
+
+// TestSparse проверяет создание разреженных матриц (структур Sparse) и произведения с плотными матрицами.
+func TestSparse(t *testing.T) {
+	// повторяющиеся индексы суммируются
+	S, err := SparseFromTriplets(3, 4, []int{2, 0, 2, 1}, []int{1, 3, 1, 0}, []float64{1., 2., 3., -1.})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := DataToMatrix([][]float64{
+		{0., 0., 0., 2.},
+		{-1., 0., 0., 0.},
+		{0., 4., 0., 0.},
+	})
+
+	if S.NNZ() != 3 {
+		t.Errorf("Expected 3 stored elements, got %d", S.NNZ())
+	}
+
+	if !IsMatrixesEqual(S.ToMatrix(), expected) {
+		t.Errorf("ToMatrix error: Result != Expected")
+	}
+
+	if !IsMatrixesEqual(S.T().ToMatrix(), expected.T()) {
+		t.Errorf("T error: Result != Expected")
+	}
+
+	if S.GetIJ(2, 1) != 4. || S.GetIJ(1, 1) != 0. {
+		t.Errorf("GetIJ error: Result != Expected")
+	}
+
+	invalid := []struct {
+		rows, columns  int
+		rowIdx, colIdx []int
+		values         []float64
+	}{
+		{0, 4, nil, nil, nil},
+		{3, 4, []int{0}, []int{0, 1}, []float64{1.}},
+		{3, 4, []int{3}, []int{0}, []float64{1.}},
+		{3, 4, []int{0}, []int{-1}, []float64{1.}},
+	}
+
+	for n, c := range invalid {
+		if _, err := SparseFromTriplets(c.rows, c.columns, c.rowIdx, c.colIdx, c.values); err == nil {
+			t.Errorf("Case %d: expected error for invalid triplets", n)
+		}
+	}
+
+	// произведения сравниваются с произведениями плотных матриц
+	D := RandMatrix(60, 300)
+	for i := 0; i < D.GetRows(); i++ {
+		for j := 0; j < D.GetColumns(); j++ {
+			if (i+j)%7 != 0 {
+				D.SetIJ(i, j, 0.)
+			}
+		}
+	}
+
+	SD := SparseFromMatrix(D)
+	if !IsMatrixesEqual(SD.ToMatrix(), D) {
+		t.Fatalf("SparseFromMatrix error: Result != Expected")
+	}
+
+	B := RandMatrix(300, 5)
+	A := RandMatrix(8, 60)
+	C := RandMatrix(8, 300)
+
+	if !isMatrixesClose(SD.Dot(B), D.Dot(B), 1e-12) {
+		t.Errorf("Dot error: Result != Expected")
+	}
+
+	if !isMatrixesClose(A.DotSparse(SD), A.Dot(D), 1e-12) {
+		t.Errorf("DotSparse error: Result != Expected")
+	}
+
+	if !isMatrixesClose(C.DotTSparse(SD), C.DotT(D), 1e-12) {
+		t.Errorf("DotTSparse error: Result != Expected")
+	}
+
+	v := RandMatrix(60, 1)
+	scaled := Zero(D.GetRows(), D.GetColumns())
+	for i := 0; i < D.GetRows(); i++ {
+		for j := 0; j < D.GetColumns(); j++ {
+			scaled.SetIJ(i, j, D.GetIJ(i, j)*v.GetIJ(i, 0))
+		}
+	}
+
+	if !isMatrixesClose(SD.ScaleRows(v).ToMatrix(), scaled, 1e-12) {
+		t.Errorf("ScaleRows error: Result != Expected")
+	}
+}
+
+// TestTryOperations проверяет, что методы с префиксом Try возвращают ошибку вместо паники
+// и не изменяют операнды, а методы без префикса вызывают панику с той же ошибкой.
+func TestTryOperations(t *testing.T) {
+	A := RandMatrix(2, 3)
+	B := RandMatrix(4, 5)
+	ACopy := A.AsType(Float64)
+
+	checks := []struct {
+		name string
+		err  error
+		op   string
+	}{
+		{"TryDot", second(A.TryDot(B)), "Dot"},
+		{"TryTDot", second(A.TryTDot(B)), "TDot"},
+		{"TryDotT", second(A.TryDotT(B)), "DotT"},
+		{"TryAdd", second(A.TryAdd(B)), "Add"},
+		{"TrySub", second(A.TrySub(B)), "Sub"},
+		{"TryHadamardProduct", second(A.TryHadamardProduct(B)), "HadamardProduct"},
+		{"TryAddInPlace", A.TryAddInPlace(B), "AddInPlace"},
+		{"TrySubInPlace", A.TrySubInPlace(B), "SubInPlace"},
+		{"TryHadamardProductInPlace", A.TryHadamardProductInPlace(B), "HadamardProductInPlace"},
+		{"TrySlice2Matrix", A.TrySlice2Matrix([]float64{1., 2.}), "Slice2Matrix"},
+		{"TryZero", second(TryZero(0, 3)), "Zero"},
+		{"TryDataToMatrix", second(TryDataToMatrix([][]float64{{1., 2.}, {3.}})), "DataToMatrix"},
+	}
+
+	for _, c := range checks {
+		var shapeErr *ShapeError
+		if !errors.As(c.err, &shapeErr) {
+			t.Errorf("%s: expected *ShapeError, got %v", c.name, c.err)
+			continue
+		}
+
+		if shapeErr.Op != c.op {
+			t.Errorf("%s: expected operation %q, got %q", c.name, c.op, shapeErr.Op)
+		}
+	}
+
+	var shapeErr *ShapeError
+	if errors.As(second(A.TryDot(B)), &shapeErr) && (shapeErr.A != Shape{2, 3} || shapeErr.B != Shape{4, 5}) {
+		t.Errorf("Incorrect operand shapes in error: %v", shapeErr)
+	}
+
+	if !IsMatrixesEqual(A, ACopy) {
+		t.Errorf("Operands must not change on error")
+	}
+
+	var dtypeErr *DTypeError
+	if !errors.As(second(A.TryAdd(ACopy.AsType(Float32))), &dtypeErr) {
+		t.Errorf("Expected *DTypeError for different element types")
+	}
+
+	if C, err := A.TryDotT(ACopy); err != nil || !IsMatrixesEqual(C, A.DotT(ACopy)) {
+		t.Errorf("TryDotT error on correct operands: %v", err)
+	}
+
+	// метод без префикса вызывает панику с той же ошибкой
+	defer func() {
+		err, ok := recover().(error)
+		if !ok || !errors.As(err, &shapeErr) {
+			t.Errorf("The code did not panic with *ShapeError")
+		}
+	}()
+
+	_ = A.Dot(B)
+}
+
+// second вспомогательная функция для тестов, возвращающая ошибку из пары результат, ошибка.
+func second[T any](_ T, err error) error {
+	return err
+}
+
+// TestInto проверяет операции, записывающие результат в заранее созданную матрицу,
+// сравнивая их с операциями, возвращающими новую матрицу.
+func TestInto(t *testing.T) {
+	for _, dtype := range []DType{Float64, Float32} {
+		A := RandMatrixOf(30, 40, dtype)
+		B := RandMatrixOf(40, 20, dtype)
+		C := RandMatrixOf(30, 40, dtype)
+		S := SparseFromMatrix(RandMatrix(40, 20))
+
+		dst := func(rows, columns int) Matrix {
+			M := ZeroOf(rows, columns, dtype)
+			M.Fill(7.)
+			return M
+		}
+
+		into := func(M Matrix, f func(Matrix)) Matrix {
+			f(M)
+			return M
+		}
+
+		pairs := []struct {
+			name           string
+			result, expect Matrix
+		}{
+			{"DotInto", into(dst(30, 20), func(M Matrix) { DotInto(M, A, B) }), A.Dot(B)},
+			{"TDotInto", into(dst(40, 40), func(M Matrix) { TDotInto(M, A, C) }), A.TDot(C)},
+			{"DotTInto", into(dst(30, 30), func(M Matrix) { DotTInto(M, A, C) }), A.DotT(C)},
+			{"AddInto", into(dst(30, 40), func(M Matrix) { AddInto(M, A, C) }), A.Add(C)},
+			{"SubInto", into(dst(30, 40), func(M Matrix) { SubInto(M, A, C) }), A.Sub(C)},
+			{"HadamardProductInto", into(dst(30, 40), func(M Matrix) { HadamardProductInto(M, A, C) }), A.HadamardProduct(C)},
+			{"ForEachInto", into(dst(30, 40), func(M Matrix) { ForEachInto(M, A, square) }), A.ForEach(square)},
+			{"TInto", into(dst(40, 30), func(M Matrix) { TInto(M, A) }), A.T()},
+			{"SoftmaxInto", into(dst(30, 40), func(M Matrix) { SoftmaxInto(M, A) }), A.Softmax()},
+			{"SoftmaxJVPInto", into(dst(30, 40), func(M Matrix) { SoftmaxJVPInto(M, A, C) }), A.SoftmaxJVP(C)},
+			{"DotSparseInto", into(dst(30, 20), func(M Matrix) { DotSparseInto(M, A, S) }), A.DotSparse(S)},
+			{"DotTSparseInto", into(dst(30, 40), func(M Matrix) { DotTSparseInto(M, A.Dot(B), S) }), A.Dot(B).DotTSparse(S)},
+			{"ScaleInPlace", into(A.AsType(dtype), func(M Matrix) { M.ScaleInPlace(-0.5) }), A.ForEach(func(x float64) float64 { return x * -0.5 })},
+			{"AddScaledInPlace", into(A.AsType(dtype), func(M Matrix) { M.AddScaledInPlace(2., C) }), A.Add(C.Add(C))},
+			// результат может совпадать с операндом поэлементной операции
+			{"AddInto aliasing", into(A.AsType(dtype), func(M Matrix) { AddInto(M, M, C) }), A.Add(C)},
+			{"SoftmaxInto aliasing", into(A.AsType(dtype), func(M Matrix) { SoftmaxInto(M, M) }), A.Softmax()},
+			{"SoftmaxJVPInto aliasing", into(C.AsType(dtype), func(M Matrix) { SoftmaxJVPInto(M, A, M) }), A.SoftmaxJVP(C)},
+		}
+
+		for _, p := range pairs {
+			if p.result.DType() != dtype {
+				t.Errorf("%s (%v): expected %v result, got %v", p.name, dtype, dtype, p.result.DType())
+			}
+
+			if !isMatrixesClose(p.result, p.expect, 1e-6) {
+				t.Errorf("%s (%v) error: Result != Expected", p.name, dtype)
+			}
+		}
+	}
+
+	A := RandMatrix(3, 3)
+
+	panics := map[string]func(){
+		"DotInto with wrong destination": func() { DotInto(Zero(2, 3), A, A) },
+		"DotInto with aliasing":          func() { DotInto(A, A, RandMatrix(3, 3)) },
+		"AddInto with wrong type":        func() { AddInto(ZeroOf(3, 3, Float32), A, A) },
+	}
+
+	for name, f := range panics {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("%s: the code did not panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+// TestIntoOverlap проверяет, что поэлементные операции допускают результат, совпадающий с операндом
+// или не пересекающийся с ним представлением той же матрицы, и вызывают панику при частичном перекрытии.
+func TestIntoOverlap(t *testing.T) {
+	for _, dtype := range []DType{Float64, Float32} {
+		M := DataToMatrix([][]float64{{1., 2., 3., 4.}}).AsType(dtype)
+		A, D := M.SubMatrix(0, 0, 1, 3), M.SubMatrix(0, 1, 1, 3)
+
+		// то же представление, полученное заново
+		AddInto(M.SubMatrix(0, 0, 1, 3), A, A)
+		if !isMatrixesClose(M, DataToMatrix([][]float64{{2., 4., 6., 4.}}), 1e-6) {
+			t.Errorf("AddInto (%v) into the same view: got %v", dtype, M)
+		}
+
+		// непересекающиеся строки одной матрицы
+		N := DataToMatrix([][]float64{{1., 2.}, {3., 4.}, {5., 6.}}).AsType(dtype)
+		SubInto(N.Row(2), N.Row(0), N.Row(1))
+		if !isMatrixesClose(N.Row(2), DataToMatrix([][]float64{{-2., -2.}}), 1e-6) {
+			t.Errorf("SubInto (%v) into disjoint row: got %v", dtype, N.Row(2))
+		}
+
+		panics := map[string]func(){
+			"AddInto":             func() { AddInto(D, A, A) },
+			"SubInto":             func() { SubInto(A, M.SubMatrix(0, 0, 1, 3), D) },
+			"HadamardProductInto": func() { HadamardProductInto(D, A, D) },
+			"ForEachInto":         func() { ForEachInto(D, A, square) },
+			"AddScaledInPlace":    func() { D.AddScaledInPlace(1., A) },
+			"SoftmaxInto":         func() { SoftmaxInto(D, A) },
+			"SoftmaxJVPInto":      func() { SoftmaxJVPInto(D, RandMatrixOf(1, 3, dtype), A) },
+			"AddBroadcastInPlace": func() { N.AddBroadcastInPlace(N.Col(1)) },
+		}
+
+		for name, f := range panics {
+			func() {
+				defer func() {
+					if r := recover(); r == nil {
+						t.Errorf("%s (%v) with partially overlapping destination: the code did not panic", name, dtype)
+					}
+				}()
+				f()
+			}()
+		}
+	}
+}
+
+// TestWorkspace проверяет повторное использование матриц набора (структуры Workspace)
+// и отсутствие выделений памяти в операциях Into над матрицами набора.
+func TestWorkspace(t *testing.T) {
+	ws := NewWorkspace()
+
+	A := ws.Get(4, 5, Float64)
+	B := ws.Get(4, 5, Float64)
+	C := ws.Get(4, 5, Float32)
+	if A.matrix == B.matrix {
+		t.Fatalf("Workspace returned the same matrix twice before Reset")
+	}
+
+	ws.Reset()
+
+	// после Reset выдаются те же матрицы
+	D, E := ws.Get(4, 5, Float64), ws.Get(4, 5, Float32)
+	if (D.matrix != A.matrix && D.matrix != B.matrix) || E.matrix != C.matrix {
+		t.Errorf("Workspace did not reuse released matrices")
+	}
+	ws.Reset()
+
+	X := RandMatrix(20, 30)
+	Y := RandMatrix(30, 10)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		Z := ws.Get(20, 10, Float64)
+		DotInto(Z, X, Y)
+		ForEachInto(Z, Z, square)
+		Z.ScaleInPlace(0.5)
+		ws.Reset()
+	})
+
+	if allocs != 0 {
+		t.Errorf("Expected no allocations, got %v", allocs)
+	}
+}
+
+// TestReductions проверяет свертки элементов матрицы (структуры Matrix) по всей матрице, строкам и столбцам.
+func TestReductions(t *testing.T) {
+	for _, dtype := range []DType{Float64, Float32} {
+		M := DataToMatrix([][]float64{
+			{1., -2., 3.},
+			{4., 5., -6.},
+		}).AsType(dtype)
+
+		scalars := []struct {
+			name           string
+			result, expect float64
+		}{
+			{"Sum", M.Sum(), 5.},
+			{"Mean", M.Mean(), 5. / 6.},
+			{"Max", M.Max(), 5.},
+			{"Min", M.Min(), -6.},
+			{"Norm", M.Norm(), math.Sqrt(91.)},
+		}
+
+		for _, s := range scalars {
+			if math.Abs(s.result-s.expect) > 1e-6 {
+				t.Errorf("%s (%v): expected %v, got %v", s.name, dtype, s.expect, s.result)
+			}
+		}
+
+		matrixes := []struct {
+			name           string
+			result, expect Matrix
+		}{
+			{"SumAxis rows", M.SumAxis(AxisRows), DataToMatrix([][]float64{{2.}, {3.}})},
+			{"SumAxis columns", M.SumAxis(AxisColumns), DataToMatrix([][]float64{{5., 3., -3.}})},
+			{"MeanAxis rows", M.MeanAxis(AxisRows), DataToMatrix([][]float64{{2. / 3.}, {1.}})},
+			{"MeanAxis columns", M.MeanAxis(AxisColumns), DataToMatrix([][]float64{{2.5, 1.5, -1.5}})},
+			{"MaxAxis rows", M.MaxAxis(AxisRows), DataToMatrix([][]float64{{3.}, {5.}})},
+			{"MaxAxis columns", M.MaxAxis(AxisColumns), DataToMatrix([][]float64{{4., 5., 3.}})},
+			{"MinAxis rows", M.MinAxis(AxisRows), DataToMatrix([][]float64{{-2.}, {-6.}})},
+			{"MinAxis columns", M.MinAxis(AxisColumns), DataToMatrix([][]float64{{1., -2., -6.}})},
+			{"NormAxis rows", M.NormAxis(AxisRows), DataToMatrix([][]float64{{math.Sqrt(14.)}, {math.Sqrt(77.)}})},
+			{"NormAxis columns", M.NormAxis(AxisColumns), DataToMatrix([][]float64{{math.Sqrt(17.), math.Sqrt(29.), math.Sqrt(45.)}})},
+		}
+
+		for _, m := range matrixes {
+			if m.result.DType() != dtype {
+				t.Errorf("%s (%v): expected %v result, got %v", m.name, dtype, dtype, m.result.DType())
+			}
+
+			if !isMatrixesClose(m.result, m.expect, 1e-6) {
+				t.Errorf("%s (%v) error: Result != Expected", m.name, dtype)
+			}
+		}
+
+		if i, j := M.ArgMax(); i != 1 || j != 1 {
+			t.Errorf("ArgMax (%v): expected 1 1, got %d %d", dtype, i, j)
+		}
+
+		if i, j := M.ArgMin(); i != 1 || j != 2 {
+			t.Errorf("ArgMin (%v): expected 1 2, got %d %d", dtype, i, j)
+		}
+
+		indexes := []struct {
+			name           string
+			result, expect []int
+		}{
+			{"ArgMaxAxis rows", M.ArgMaxAxis(AxisRows), []int{2, 1}},
+			{"ArgMaxAxis columns", M.ArgMaxAxis(AxisColumns), []int{1, 1, 0}},
+			{"ArgMinAxis rows", M.ArgMinAxis(AxisRows), []int{1, 2}},
+			{"ArgMinAxis columns", M.ArgMinAxis(AxisColumns), []int{0, 0, 1}},
+		}
+
+		for _, ind := range indexes {
+			if len(ind.result) != len(ind.expect) {
+				t.Errorf("%s (%v): expected %v, got %v", ind.name, dtype, ind.expect, ind.result)
+				continue
+			}
+
+			for k := range ind.expect {
+				if ind.result[k] != ind.expect[k] {
+					t.Errorf("%s (%v): expected %v, got %v", ind.name, dtype, ind.expect, ind.result)
+					break
+				}
+			}
+		}
+	}
+
+	// при равенстве выбирается первый элемент
+	if i, _ := DataToMatrix([][]float64{{1.}, {3.}, {3.}}).ArgMax(); i != 1 {
+		t.Errorf("ArgMax must return the first of equal elements, got %d", i)
+	}
+}
+
+// TestReductionsNaN проверяет, что NaN распространяется в свертках Max и Min независимо от порядка элементов,
+// а ArgMax и ArgMin возвращают индекс первого NaN.
+func TestReductionsNaN(t *testing.T) {
+	nan := math.NaN()
+	for _, dtype := range []DType{Float64, Float32} {
+		for _, v := range [][]float64{{1., nan, 3.}, {nan, 1., 3.}, {3., 1., nan}} {
+			M := DataToMatrix([][]float64{v}).AsType(dtype)
+			first := 0
+			for !math.IsNaN(v[first]) {
+				first++
+			}
+
+			if !math.IsNaN(M.Max()) || !math.IsNaN(M.Min()) {
+				t.Errorf("Max and Min (%v) of %v: expected NaN, got %v and %v", dtype, v, M.Max(), M.Min())
+			}
+			if _, j := M.ArgMax(); j != first {
+				t.Errorf("ArgMax (%v) of %v: expected %d, got %d", dtype, v, first, j)
+			}
+			if _, j := M.ArgMin(); j != first {
+				t.Errorf("ArgMin (%v) of %v: expected %d, got %d", dtype, v, first, j)
+			}
+
+			if x := M.MaxAxis(AxisRows).GetIJ(0, 0); !math.IsNaN(x) {
+				t.Errorf("MaxAxis (%v) of %v: expected NaN, got %v", dtype, v, x)
+			}
+			if x := M.MinAxis(AxisRows).GetIJ(0, 0); !math.IsNaN(x) {
+				t.Errorf("MinAxis (%v) of %v: expected NaN, got %v", dtype, v, x)
+			}
+			if idx := M.ArgMaxAxis(AxisRows); idx[0] != first {
+				t.Errorf("ArgMaxAxis (%v) of %v: expected %d, got %d", dtype, v, first, idx[0])
+			}
+			if idx := M.T().ArgMinAxis(AxisColumns); idx[0] != first {
+				t.Errorf("ArgMinAxis (%v) of %v: expected %d, got %d", dtype, v, first, idx[0])
+			}
+		}
+	}
+}
+
+// TestBroadcast проверяет поэлементные операции матрицы (структуры Matrix) и растянутого вектора.
+func TestBroadcast(t *testing.T) {
+	M := DataToMatrix([][]float64{
+		{1., 2., 3.},
+		{4., 5., 6.},
+	})
+
+	column := DataToMatrix([][]float64{{10.}, {20.}})
+	row := DataToMatrix([][]float64{{1., 0., -1.}})
+
+	inPlace := func(f func(Matrix)) Matrix {
+		R := M.AsType(Float64)
+		f(R)
+		return R
+	}
+
+	pairs := []struct {
+		name           string
+		result, expect Matrix
+	}{
+		{"AddBroadcast column", M.AddBroadcast(column), DataToMatrix([][]float64{{11., 12., 13.}, {24., 25., 26.}})},
+		{"AddBroadcast row", M.AddBroadcast(row), DataToMatrix([][]float64{{2., 2., 2.}, {5., 5., 5.}})},
+		{"SubBroadcast column", M.SubBroadcast(column), DataToMatrix([][]float64{{-9., -8., -7.}, {-16., -15., -14.}})},
+		{"SubBroadcast row", M.SubBroadcast(row), DataToMatrix([][]float64{{0., 2., 4.}, {3., 5., 7.}})},
+		{"HadamardProductBroadcast column", M.HadamardProductBroadcast(column), DataToMatrix([][]float64{{10., 20., 30.}, {80., 100., 120.}})},
+		{"HadamardProductBroadcast row", M.HadamardProductBroadcast(row), DataToMatrix([][]float64{{1., 0., -3.}, {4., 0., -6.}})},
+		{"AddBroadcastInPlace", inPlace(func(R Matrix) { R.AddBroadcastInPlace(column) }), M.AddBroadcast(column)},
+		{"SubBroadcastInPlace", inPlace(func(R Matrix) { R.SubBroadcastInPlace(row) }), M.SubBroadcast(row)},
+		{"HadamardProductBroadcastInPlace", inPlace(func(R Matrix) { R.HadamardProductBroadcastInPlace(row) }), M.HadamardProductBroadcast(row)},
+		{"AddBroadcast float32", M.AsType(Float32).AddBroadcast(column.AsType(Float32)), M.AddBroadcast(column)},
+	}
+
+	for _, p := range pairs {
+		if !IsMatrixesEqual(p.result.AsType(Float64), p.expect) {
+			t.Errorf("%s error: Result != Expected", p.name)
+		}
+	}
+
+	var shapeErr *ShapeError
+	if _, err := M.TryAddBroadcast(DataToMatrix([][]float64{{1.}, {2.}, {3.}})); !errors.As(err, &shapeErr) {
+		t.Errorf("Expected *ShapeError for vector of incorrect length, got %v", err)
+	}
+
+	if _, err := M.TrySubBroadcast(M); !errors.As(err, &shapeErr) {
+		t.Errorf("Expected *ShapeError for matrix operand, got %v", err)
+	}
+}
+
+// TestLinalg проверяет разложения матрицы (структуры Matrix) и решение систем линейных уравнений.
+func TestLinalg(t *testing.T) {
+	A := DataToMatrix([][]float64{
+		{2., 1., 1.},
+		{4., -6., 0.},
+		{-2., 7., 2.},
+	})
+	x := DataToMatrix([][]float64{{1., 2.}, {2., -1.}, {3., 0.}})
+	B := A.Dot(x)
+
+	lu, err := A.LU()
+	if err != nil {
+		t.Fatalf("LU error: %v", err)
+	}
+	if !isMatrixesClose(lu.P().Dot(A), lu.L().Dot(lu.U()), 1e-12) {
+		t.Errorf("LU error: PA != LU")
+	}
+	if det := lu.Det(); math.Abs(det+16.) > 1e-12 {
+		t.Errorf("Det error: expected -16, got %v", det)
+	}
+
+	for _, dtype := range []DType{Float64, Float32} {
+		tol := 1e-12
+		if dtype == Float32 {
+			tol = 1e-5
+		}
+
+		X, err := A.AsType(dtype).Solve(B.AsType(dtype))
+		if err != nil || X.DType() != dtype || !isMatrixesClose(X.AsType(Float64), x, tol) {
+			t.Errorf("Solve (%v) error: %v", dtype, err)
+		}
+
+		inv, err := A.AsType(dtype).Inverse()
+		if err != nil || !isMatrixesClose(inv.AsType(Float64).Dot(A), identityOf(3), tol) {
+			t.Errorf("Inverse (%v) error: %v", dtype, err)
+		}
+	}
+
+	// симметричная положительно определенная матрица
+	S := DataToMatrix([][]float64{
+		{4., 12., -16.},
+		{12., 37., -43.},
+		{-16., -43., 98.},
+	})
+	chol, err := S.Cholesky()
+	if err != nil {
+		t.Fatalf("Cholesky error: %v", err)
+	}
+	if !isMatrixesClose(chol.L().DotT(chol.L()), S, 1e-12) {
+		t.Errorf("Cholesky error: LL^T != A")
+	}
+	if X, err := chol.Solve(S.Dot(x)); err != nil || !isMatrixesClose(X, x, 1e-9) {
+		t.Errorf("Cholesky.Solve error: %v", err)
+	}
+	if det := chol.Det(); math.Abs(det-36.) > 1e-9 {
+		t.Errorf("Cholesky.Det error: expected 36, got %v", det)
+	}
+	if _, err := A.Cholesky(); !errors.Is(err, ErrNotPositiveDefinite) {
+		t.Errorf("Expected ErrNotPositiveDefinite, got %v", err)
+	}
+
+	// переопределенная система: прямая y = 1 + 2t по точкам с симметричным шумом
+	T := DataToMatrix([][]float64{{1., 0.}, {1., 1.}, {1., 2.}, {1., 3.}})
+	y := DataToMatrix([][]float64{{1.1}, {2.9}, {5.1}, {6.9}})
+	qr, err := T.QR()
+	if err != nil {
+		t.Fatalf("QR error: %v", err)
+	}
+	if !isMatrixesClose(qr.Q().Dot(qr.R()), T, 1e-12) || !isMatrixesClose(qr.Q().TDot(qr.Q()), identityOf(2), 1e-12) {
+		t.Errorf("QR error: QR != A or Q^TQ != I")
+	}
+	if coef, err := T.Solve(y); err != nil || !isMatrixesClose(coef, DataToMatrix([][]float64{{1.06}, {1.96}}), 1e-12) {
+		t.Errorf("Least squares error: %v", err)
+	}
+
+	// вырожденные матрицы
+	for _, M := range []Matrix{
+		DataToMatrix([][]float64{{1., 2.}, {2., 4.}}),
+		DataToMatrix([][]float64{{1., 2., 3.}, {4., 5., 6.}, {7., 8., 9.}}),
+	} {
+		if _, err := M.Inverse(); !errors.Is(err, ErrSingular) {
+			t.Errorf("Inverse: expected ErrSingular, got %v", err)
+		}
+		if _, err := M.Solve(identityOf(M.GetRows())); !errors.Is(err, ErrSingular) {
+			t.Errorf("Solve: expected ErrSingular, got %v", err)
+		}
+		if c, err := M.Cond(); !errors.Is(err, ErrSingular) || !math.IsInf(c, 1) {
+			t.Errorf("Cond: expected +Inf and ErrSingular, got %v, %v", c, err)
+		}
+	}
+	if _, err := DataToMatrix([][]float64{{1., 2.}, {2., 4.}, {3., 6.}}).Solve(identityOf(3)); !errors.Is(err, ErrSingular) {
+		t.Errorf("Least squares: expected ErrSingular, got %v", err)
+	}
+
+	if c, err := identityOf(4).Cond(); err != nil || math.Abs(c-1.) > 1e-12 {
+		t.Errorf("Cond of identity: expected 1, got %v, %v", c, err)
+	}
+	if c, _ := DataToMatrix([][]float64{{1., 1.}, {1., 1. + 1e-10}}).Cond(); c < 1e9 {
+		t.Errorf("Cond of ill-conditioned matrix is too small: %v", c)
+	}
+	if c, _ := A.Cond(); math.Abs(c-normOneOf(A)*normOneOf(first(A.Inverse()))) > 1e-9 {
+		t.Errorf("Cond error: got %v", c)
+	}
+
+	var shapeErr *ShapeError
+	if _, err := T.LU(); !errors.As(err, &shapeErr) {
+		t.Errorf("Expected *ShapeError for non-square LU, got %v", err)
+	}
+	if _, err := T.Inverse(); !errors.As(err, &shapeErr) {
+		t.Errorf("Expected *ShapeError for non-square Inverse, got %v", err)
+	}
+	if _, err := T.T().QR(); !errors.As(err, &shapeErr) {
+		t.Errorf("Expected *ShapeError for wide QR, got %v", err)
+	}
+	if _, err := A.Solve(y); !errors.As(err, &shapeErr) {
+		t.Errorf("Expected *ShapeError for Solve, got %v", err)
+	}
+}
+
+// identityOf вспомогательная функция для тестов, возвращает единичную матрицу n на n.
+func identityOf(n int) Matrix {
+	return Matrix{matrix: identity(n, Float64)}
+}
+
+// normOneOf вспомогательная функция для тестов, возвращает 1-норму матрицы.
+func normOneOf(M Matrix) float64 {
+	return norm1(M.matrix)
+}
+
+// first вспомогательная функция для тестов, возвращает первое значение, игнорируя ошибку.
+func first(M Matrix, _ error) Matrix {
+	return M
+}
+
+// TestSpectral проверяет сингулярное разложение, разложение по собственным векторам,
+// псевдообратную матрицу и ранг матрицы (структуры Matrix).
+func TestSpectral(t *testing.T) {
+	// сингулярные числа матрицы равны 5 и 3
+	A := DataToMatrix([][]float64{
+		{3., 2., 2.},
+		{2., 3., -2.},
+	})
+
+	for _, M := range []Matrix{A, A.T()} {
+		for _, full := range []bool{false, true} {
+			f, err := M.SVD()
+			if full {
+				f, err = M.FullSVD()
+			}
+			if err != nil {
+				t.Fatalf("SVD error: %v", err)
+			}
+
+			U, V := f.U(), f.V()
+			if !isMatrixesClose(U.Dot(f.Sigma()).DotT(V), M, 1e-12) {
+				t.Errorf("SVD (full %v) error: U Sigma V^T != A", full)
+			}
+			if !isMatrixesClose(U.TDot(U), identityOf(U.GetColumns()), 1e-12) ||
+				!isMatrixesClose(V.TDot(V), identityOf(V.GetColumns()), 1e-12) {
+				t.Errorf("SVD (full %v) error: columns of U or V are not orthonormal", full)
+			}
+			if !isMatrixesClose(f.Values(), DataToMatrix([][]float64{{5.}, {3.}}), 1e-12) {
+				t.Errorf("SVD error: singular values must be 5 and 3")
+			}
+
+			k := 2
+			if full {
+				k = M.GetRows()
+			}
+			if U.GetRows() != M.GetRows() || U.GetColumns() != k || V.GetRows() != M.GetColumns() {
+				t.Errorf("SVD (full %v) error: incorrect shapes of U and V", full)
+			}
+		}
+	}
+
+	// матрица ранга 2 и ее псевдообратная
+	R := DataToMatrix([][]float64{
+		{1., 2., 3.},
+		{4., 5., 6.},
+		{7., 8., 9.},
+		{2., 4., 6.},
+	})
+	if r, err := R.Rank(); err != nil || r != 2 {
+		t.Errorf("Rank error: expected 2, got %v, %v", r, err)
+	}
+	if r, _ := A.Rank(); r != 2 {
+		t.Errorf("Rank error: expected 2, got %v", r)
+	}
+
+	P, err := R.Pinv()
+	if err != nil {
+		t.Fatalf("Pinv error: %v", err)
+	}
+	if !isMatrixesClose(R.Dot(P).Dot(R), R, 1e-10) || !isMatrixesClose(P.Dot(R).Dot(P), P, 1e-10) {
+		t.Errorf("Pinv error: Moore-Penrose conditions are not satisfied")
+	}
+
+	B := DataToMatrix([][]float64{{2., 1.}, {1., 3.}})
+	inv, _ := B.Inverse()
+	if P, _ := B.Pinv(); !isMatrixesClose(P, inv, 1e-12) {
+		t.Errorf("Pinv error: Pinv of invertible matrix != Inverse")
+	}
+
+	// собственные значения 2, 2 и 4
+	S := DataToMatrix([][]float64{
+		{2., 0., 0.},
+		{0., 3., 1.},
+		{0., 1., 3.},
+	})
+	for _, dtype := range []DType{Float64, Float32} {
+		tol := 1e-12
+		if dtype == Float32 {
+			tol = 1e-6
+		}
+
+		eig, err := S.AsType(dtype).EigenSym()
+		if err != nil {
+			t.Fatalf("EigenSym error: %v", err)
+		}
+
+		values, V := eig.Values().AsType(Float64), eig.Vectors().AsType(Float64)
+		if !isMatrixesClose(values, DataToMatrix([][]float64{{2.}, {2.}, {4.}}), tol) {
+			t.Errorf("EigenSym (%v) error: incorrect eigenvalues %v", dtype, values)
+		}
+
+		D := Zero(3, 3)
+		for i := 0; i < 3; i++ {
+			D.SetIJ(i, i, values.GetIJ(i, 0))
+		}
+		if !isMatrixesClose(V.Dot(D).DotT(V), S, tol) || !isMatrixesClose(V.TDot(V), identityOf(3), tol) {
+			t.Errorf("EigenSym (%v) error: V D V^T != A or V is not orthogonal", dtype)
+		}
+	}
+
+	if _, err := DataToMatrix([][]float64{{1., math.NaN()}, {2., 3.}}).SVD(); !errors.Is(err, ErrNoConvergence) {
+		t.Errorf("Expected ErrNoConvergence, got %v", err)
+	}
+
+	var shapeErr *ShapeError
+	if _, err := A.EigenSym(); !errors.As(err, &shapeErr) {
+		t.Errorf("Expected *ShapeError for non-square EigenSym, got %v", err)
+	}
+}
+
+// TestShape проверяет изменение формы, объединение матриц (структур Matrix) и представления частей матрицы.
+func TestShape(t *testing.T) {
+	for _, dtype := range []DType{Float64, Float32} {
+		M := DataToMatrix([][]float64{
+			{1., 2., 3., 4.},
+			{5., 6., 7., 8.},
+			{9., 10., 11., 12.},
+		}).AsType(dtype)
+
+		sub := M.SubMatrix(1, 1, 2, 2)
+		pairs := []struct {
+			name           string
+			result, expect Matrix
+		}{
+			{"Row", M.Row(1), DataToMatrix([][]float64{{5., 6., 7., 8.}})},
+			{"Col", M.Col(2), DataToMatrix([][]float64{{3.}, {7.}, {11.}})},
+			{"SubMatrix", sub, DataToMatrix([][]float64{{6., 7.}, {10., 11.}})},
+			{"Reshape", M.Reshape(2, 6), DataToMatrix([][]float64{{1., 2., 3., 4., 5., 6.}, {7., 8., 9., 10., 11., 12.}})},
+			{"Reshape of view", sub.Reshape(1, 4), DataToMatrix([][]float64{{6., 7., 10., 11.}})},
+			{"Flatten", sub.Flatten(), DataToMatrix([][]float64{{6.}, {7.}, {10.}, {11.}})},
+			{"HStack", HStack(M.Col(0), M.Col(3)), DataToMatrix([][]float64{{1., 4.}, {5., 8.}, {9., 12.}})},
+			{"VStack", VStack(M.Row(2), M.Row(0)), DataToMatrix([][]float64{{9., 10., 11., 12.}, {1., 2., 3., 4.}})},
+			{"Concat AxisRows", Concat(AxisRows, sub, sub), DataToMatrix([][]float64{{6., 7., 6., 7.}, {10., 11., 10., 11.}})},
+			{"Concat AxisColumns", Concat(AxisColumns, sub, M.SubMatrix(0, 0, 1, 2)), DataToMatrix([][]float64{{6., 7.}, {10., 11.}, {1., 2.}})},
+			{"Dot of views", sub.Dot(M.SubMatrix(0, 2, 2, 2)), DataToMatrix([][]float64{{67., 80.}, {107., 128.}})},
+			{"T of view", sub.T(), DataToMatrix([][]float64{{6., 10.}, {7., 11.}})},
+			{"Add of views", sub.Add(sub), DataToMatrix([][]float64{{12., 14.}, {20., 22.}})},
+		}
+
+		for _, p := range pairs {
+			if p.result.DType() != dtype || !IsMatrixesEqual(p.result.AsType(Float64), p.expect) {
+				t.Errorf("%s (%v) error: Result != Expected", p.name, dtype)
+			}
+		}
+
+		// представления разделяют память с исходной матрицей
+		sub.ScaleInPlace(-1.)
+		M.Row(0).Fill(0.)
+		M.Reshape(4, 3).SetIJ(3, 2, 100.)
+		expect := DataToMatrix([][]float64{
+			{0., 0., 0., 0.},
+			{5., -6., -7., 8.},
+			{9., -10., -11., 100.},
+		})
+		if !IsMatrixesEqual(M.AsType(Float64), expect) {
+			t.Errorf("Views (%v) error: changes of views are not visible in matrix", dtype)
+		}
+
+		// Flatten возвращает копию
+		M.Flatten().Fill(1.)
+		if M.GetIJ(1, 0) != 5. {
+			t.Errorf("Flatten (%v) error: result shares memory with matrix", dtype)
+		}
+	}
+
+	M := RandMatrix(3, 4)
+	var shapeErr *ShapeError
+	var indexErr *IndexError
+	var dtypeErr *DTypeError
+
+	if _, err := M.TryReshape(5, 2); !errors.As(err, &shapeErr) {
+		t.Errorf("Expected *ShapeError for Reshape, got %v", err)
+	}
+	if _, err := M.TrySubMatrix(2, 2, 2, 2); !errors.As(err, &indexErr) {
+		t.Errorf("Expected *IndexError for SubMatrix, got %v", err)
+	}
+	if _, err := M.TryRow(3); !errors.As(err, &indexErr) {
+		t.Errorf("Expected *IndexError for Row, got %v", err)
+	}
+	if _, err := M.TryCol(-1); !errors.As(err, &indexErr) {
+		t.Errorf("Expected *IndexError for Col, got %v", err)
+	}
+	if _, err := TryHStack(M, M.T()); !errors.As(err, &shapeErr) {
+		t.Errorf("Expected *ShapeError for HStack, got %v", err)
+	}
+	if _, err := TryVStack(); !errors.As(err, &shapeErr) {
+		t.Errorf("Expected *ShapeError for empty VStack, got %v", err)
+	}
+	if _, err := TryConcat(AxisColumns, M, M.AsType(Float32)); !errors.As(err, &dtypeErr) {
+		t.Errorf("Expected *DTypeError for Concat, got %v", err)
+	}
+}
+
+// TestNpy проверяет запись и чтение матриц (структур Matrix) в форматах NumPy .npy и .npz.
+func TestNpy(t *testing.T) {
+	M := DataToMatrix([][]float64{
+		{1., 2., 3.},
+		{4., 5., 6.},
+	})
+
+	for _, dtype := range []DType{Float64, Float32} {
+		var buf bytes.Buffer
+		if err := WriteNpy(&buf, M.AsType(dtype).SubMatrix(0, 0, 2, 3)); err != nil {
+			t.Fatal(err)
+		}
+
+		// заголовок вместе с префиксом выровнен на 64 байта, как у numpy.save
+		headerLen := int(buf.Bytes()[8]) | int(buf.Bytes()[9])<<8
+		if (10+headerLen)%64 != 0 || buf.Len() != 10+headerLen+6*dtype.Size() {
+			t.Errorf("WriteNpy (%v): incorrect header length %d", dtype, headerLen)
+		}
+
+		R, err := ReadNpy(&buf)
+		if err != nil || R.DType() != dtype || !IsMatrixesEqual(R.AsType(Float64), M) {
+			t.Errorf("ReadNpy (%v) error: %v", dtype, err)
+		}
+	}
+
+	// массивы, записанные NumPy в других вариантах формата
+	le64 := func(xs ...float64) []byte {
+		var b []byte
+		for _, x := range xs {
+			b = binary.LittleEndian.AppendUint64(b, math.Float64bits(x))
+		}
+		return b
+	}
+	be32 := func(xs ...float64) []byte {
+		var b []byte
+		for _, x := range xs {
+			b = binary.BigEndian.AppendUint32(b, math.Float32bits(float32(x)))
+		}
+		return b
+	}
+
+	cases := []struct {
+		name   string
+		file   []byte
+		expect Matrix
+	}{
+		{"Fortran order", npyFile(1, "{'descr': '<f8', 'fortran_order': True, 'shape': (2, 3), }", le64(1., 4., 2., 5., 3., 6.)), M},
+		{"big-endian float32", npyFile(1, "{'descr': '>f4', 'fortran_order': False, 'shape': (2, 3), }", be32(1., 2., 3., 4., 5., 6.)), M},
+		{"one-dimensional", npyFile(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (3,), }", le64(1., 2., 3.)), DataToMatrix([][]float64{{1.}, {2.}, {3.}})},
+		{"scalar", npyFile(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (), }", le64(7.)), DataToMatrix([][]float64{{7.}})},
+		{"version 2.0", npyFile(2, "{'descr': '<f8', 'fortran_order': False, 'shape': (1, 2), }", le64(1., 2.)), DataToMatrix([][]float64{{1., 2.}})},
+	}
+
+	for _, c := range cases {
+		R, err := ReadNpy(bytes.NewReader(c.file))
+		if err != nil || !IsMatrixesEqual(R.AsType(Float64), c.expect) {
+			t.Errorf("ReadNpy %s error: %v", c.name, err)
+		}
+	}
+
+	invalid := map[string][]byte{
+		"three dimensions": npyFile(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (1, 1, 1), }", le64(1.)),
+		"integer elements": npyFile(1, "{'descr': '<i8', 'fortran_order': False, 'shape': (1, 1), }", le64(1.)),
+		"truncated data":   npyFile(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (2, 2), }", le64(1.)),
+		"too large shape":  npyFile(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (100000000000, 100000), }", le64(1.)),
+		"overflowed shape": npyFile(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (4611686018427387904, 4), }", le64(1.)),
+		"huge shape":       npyFile(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (2147483647, 1), }", le64(1.)),
+		"incorrect magic":  []byte("NUMPY\x01\x00"),
+	}
+	for name, file := range invalid {
+		if _, err := ReadNpy(bytes.NewReader(file)); err == nil {
+			t.Errorf("ReadNpy %s: expected error", name)
+		}
+	}
+
+	// архив .npz
+	arrays := map[string]Matrix{"a": M, "b": M.T().AsType(Float32)}
+	var buf bytes.Buffer
+	if err := WriteNpz(&buf, arrays); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := ReadNpz(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil || len(read) != 2 {
+		t.Fatalf("ReadNpz error: %v", err)
+	}
+	for name, A := range arrays {
+		if read[name].DType() != A.DType() || !IsMatrixesEqual(read[name].AsType(Float64), A.AsType(Float64)) {
+			t.Errorf("ReadNpz error: array %s differs", name)
+		}
+	}
+
+	// архив numpy.savez_compressed сжат методом Deflate
+	buf.Reset()
+	zw := zip.NewWriter(&buf)
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: "x.npy", Method: zip.Deflate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(cases[0].file)
+	zw.Close()
+
+	read, err = ReadNpz(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil || !IsMatrixesEqual(read["x"], M) {
+		t.Errorf("ReadNpz compressed error: %v", err)
+	}
+}
+
+// npyFile вспомогательная функция для тестов, возвращает файл .npy версии version
+// с заголовком header и данными payload.
+func npyFile(version byte, header string, payload []byte) []byte {
+	header += "\n"
+	b := append([]byte("\x93NUMPY"), version, 0)
+	if version == 1 {
+		b = binary.LittleEndian.AppendUint16(b, uint16(len(header)))
+	} else {
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(header)))
+	}
+	b = append(b, header...)
+	return append(b, payload...)
+}
+
+// TestWriteReadBinary проверяет запись и чтение матриц (структур Matrix) в двоичном формате без потери точности.
+func TestWriteReadBinary(t *testing.T) {
+	A := RandMatrix(7, 5)
+	A.SetIJ(0, 0, math.Pi)
+	A.SetIJ(0, 1, math.Inf(-1))
+	A.SetIJ(0, 2, math.SmallestNonzeroFloat64)
+	matrixes := []Matrix{A, RandMatrixOf(3, 9, Float32), A.SubMatrix(1, 1, 3, 2)}
+
+	var buf bytes.Buffer
+	if err := WriteMatrixesBinary(&buf, matrixes); err != nil {
+		t.Fatal(err)
+	}
+	data := append([]byte(nil), buf.Bytes()...)
+
+	// два блока подряд в одном потоке
+	buf.Write(data)
+	r := bufio.NewReader(&buf)
+	for block := 0; block < 2; block++ {
+		read, err := ReadMatrixesBinary(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(read) != len(matrixes) {
+			t.Fatalf("Expected %d matrixes, got %d", len(matrixes), len(read))
+		}
+
+		for k, M := range matrixes {
+			R := read[k]
+			if R.DType() != M.DType() || R.GetRows() != M.GetRows() || R.GetColumns() != M.GetColumns() {
+				t.Fatalf("Matrix %d: incorrect element type or dimension", k)
+			}
+			for i := 0; i < M.GetRows(); i++ {
+				for j := 0; j < M.GetColumns(); j++ {
+					if R.GetIJ(i, j) != M.GetIJ(i, j) {
+						t.Fatalf("Matrix %d: element %d, %d is not exact after reading", k, i, j)
+					}
+				}
+			}
+		}
+	}
+
+	corrupted := append([]byte(nil), data...)
+	corrupted[40] ^= 1
+	if _, err := ReadMatrixesBinary(bytes.NewReader(corrupted)); !errors.Is(err, ErrChecksum) {
+		t.Errorf("Expected ErrChecksum for corrupted data, got %v", err)
+	}
+
+	if _, err := ReadMatrixesBinary(bytes.NewReader(data[:len(data)-10])); err == nil {
+		t.Errorf("Expected error for truncated data")
+	}
+
+	// поврежденный заголовок с огромным количеством матриц или размерностью матрицы
+	// приводит к ошибке конца потока, а не к выделению памяти по заголовку
+	for name, corrupt := range map[string]func(b []byte){
+		"count": func(b []byte) { b[11] = 0xff },
+		"rows":  func(b []byte) { binary.LittleEndian.PutUint32(b[16:], math.MaxInt32) },
+		"dimension": func(b []byte) {
+			binary.LittleEndian.PutUint32(b[16:], 1<<16)
+			binary.LittleEndian.PutUint32(b[20:], 1<<16)
+		},
+	} {
+		header := append([]byte(nil), data...)
+		corrupt(header)
+		if _, err := ReadMatrixesBinary(bytes.NewReader(header)); err == nil {
+			t.Errorf("Expected error for corrupted header %s", name)
+		}
+	}
+
+	version := append([]byte(nil), data...)
+	version[4] = 2
+	if _, err := ReadMatrixesBinary(bytes.NewReader(version)); err == nil {
+		t.Errorf("Expected error for unsupported version")
+	}
+
+	if _, err := ReadMatrixesBinary(bytes.NewReader([]byte("1\n1 1\n0.5\n"))); err == nil {
+		t.Errorf("Expected error for text format")
+	}
+}
+
+// TestSoftmax проверяет softmax, log-softmax, log-sum-exp столбцов матрицы (структуры Matrix)
+// и их производные по направлению.
+func TestSoftmax(t *testing.T) {
+	// второй столбец отличается от первого сдвигом, который переполнил бы exp без стабилизации
+	X := DataToMatrix([][]float64{
+		{1., 1001., -1000.},
+		{2., 1002., math.Inf(-1)},
+		{3., 1003., -1000.},
+	})
+	p := []float64{0.09003057317038046, 0.24472847105479767, 0.6652409557748219}
+
+	expectSoftmax := DataToMatrix([][]float64{{p[0], p[0], 0.5}, {p[1], p[1], 0.}, {p[2], p[2], 0.5}})
+	expectLSE := DataToMatrix([][]float64{{3.40760596444438, 1003.40760596444438, -1000. + math.Ln2}})
+
+	for _, dtype := range []DType{Float64, Float32} {
+		tol := 1e-12
+		if dtype == Float32 {
+			tol = 1e-6
+		}
+		A := X.AsType(dtype)
+
+		S := A.Softmax().AsType(Float64)
+		if !isMatrixesClose(S, expectSoftmax, tol) {
+			t.Errorf("Softmax (%v) error: Result != Expected", dtype)
+		}
+
+		L := A.LogSoftmax().AsType(Float64)
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 2; j++ {
+				if math.Abs(L.GetIJ(i, j)-math.Log(p[i])) > tol {
+					t.Errorf("LogSoftmax (%v) error: Result != Expected", dtype)
+				}
+			}
+		}
+		if !math.IsInf(L.GetIJ(1, 2), -1) || math.Abs(L.GetIJ(0, 2)+math.Ln2) > tol {
+			t.Errorf("LogSoftmax (%v) error: incorrect result for -Inf logit", dtype)
+		}
+
+		if !isMatrixesClose(A.LogSumExp().AsType(Float64), expectLSE, tol) {
+			t.Errorf("LogSumExp (%v) error: Result != Expected", dtype)
+		}
+	}
+
+	// производные по направлению сравниваются с центральными разностями
+	A := DataToMatrix([][]float64{{0.5, -1.}, {-0.3, 2.}, {1.2, 0.1}, {0., -0.7}})
+	V := DataToMatrix([][]float64{{1., 0.2}, {-0.5, 0.3}, {0.25, -1.}, {2., 0.}})
+	h := 1e-6
+	diff := func(f func(Matrix) Matrix) Matrix {
+		Ah := A.AsType(Float64)
+		Ah.AddScaledInPlace(h, V)
+		plus := f(Ah)
+		Ah.AddScaledInPlace(-2*h, V)
+		D := plus.Sub(f(Ah))
+		D.ScaleInPlace(1. / (2 * h))
+		return D
+	}
+
+	pairs := []struct {
+		name           string
+		result, expect Matrix
+	}{
+		{"SoftmaxJVP", A.SoftmaxJVP(V), diff(Matrix.Softmax)},
+		{"LogSoftmaxJVP", A.LogSoftmaxJVP(V), diff(Matrix.LogSoftmax)},
+		{"LogSumExpJVP", A.LogSumExpJVP(V), diff(Matrix.LogSumExp)},
+	}
+	for _, p := range pairs {
+		if !isMatrixesClose(p.result, p.expect, 1e-8) {
+			t.Errorf("%s error: Result != finite differences", p.name)
+		}
+	}
+
+	// матрица Якоби softmax симметрична
+	U := DataToMatrix([][]float64{{0.3, 1.}, {0.1, -2.}, {-0.4, 0.5}, {1., 1.}})
+	left := U.HadamardProduct(A.SoftmaxJVP(V)).SumAxis(AxisColumns)
+	right := V.HadamardProduct(A.SoftmaxJVP(U)).SumAxis(AxisColumns)
+	if !isMatrixesClose(left, right, 1e-12) {
+		t.Errorf("SoftmaxJVP error: Jacobian is not symmetric")
+	}
+
+	var shapeErr *ShapeError
+	if _, err := A.TrySoftmaxJVP(V.T()); !errors.As(err, &shapeErr) {
+		t.Errorf("Expected *ShapeError for SoftmaxJVP, got %v", err)
+	}
+}
+
+// TestConv проверяет преобразования Im2Col и Col2Im, свертку и пулинг изображений.
+func TestConv(t *testing.T) {
+	// изображение 3 на 3 с одним каналом
+	X := DataToMatrix([][]float64{{1., 2., 3., 4., 5., 6., 7., 8., 9.}})
+	s := Conv2DShape{Channels: 1, Height: 3, Width: 3, KernelHeight: 2, KernelWidth: 2, StrideH: 1, StrideW: 1}
+
+	cols := DataToMatrix([][]float64{
+		{1., 2., 4., 5.},
+		{2., 3., 5., 6.},
+		{4., 5., 7., 8.},
+		{5., 6., 8., 9.},
+	})
+	if !IsMatrixesEqual(Im2Col(X, s), cols) {
+		t.Errorf("Im2Col error: Result != Expected")
+	}
+
+	// каждый элемент входит в столько окон, сколько раз встречается в развертке
+	if !IsMatrixesEqual(Col2Im(cols, s), DataToMatrix([][]float64{{1., 4., 3., 8., 20., 12., 7., 16., 9.}})) {
+		t.Errorf("Col2Im error: Result != Expected")
+	}
+
+	Y, argmax := MaxPool2D(X, s)
+	if !IsMatrixesEqual(Y, DataToMatrix([][]float64{{5., 6., 8., 9.}})) {
+		t.Errorf("MaxPool2D error: Result != Expected")
+	}
+	if dX := MaxPool2DBackward(DataToMatrix([][]float64{{1., 1., 1., 1.}}), argmax, s); !IsMatrixesEqual(dX, DataToMatrix([][]float64{{0., 0., 0., 0., 1., 1., 0., 1., 1.}})) {
+		t.Errorf("MaxPool2DBackward error: Result != Expected")
+	}
+	if !IsMatrixesEqual(AvgPool2D(X, s), DataToMatrix([][]float64{{3., 4., 6., 7.}})) {
+		t.Errorf("AvgPool2D error: Result != Expected")
+	}
+
+	// два канала 5 на 4, ядро 3 на 2, шаг 2, дополнение 1
+	p := Conv2DShape{Channels: 2, Height: 5, Width: 4, KernelHeight: 3, KernelWidth: 2, StrideH: 2, StrideW: 2, PadH: 1, PadW: 1}
+	if p.OutHeight() != 3 || p.OutWidth() != 3 {
+		t.Fatalf("Incorrect output size %d*%d", p.OutHeight(), p.OutWidth())
+	}
+
+	A := RandMatrix(2, 20)
+	K := RandMatrix(3, 12)
+	G := RandMatrix(3, 9)
+
+	// свертка по определению
+	direct := Zero(3, 9)
+	for f := 0; f < 3; f++ {
+		for oy := 0; oy < 3; oy++ {
+			for ox := 0; ox < 3; ox++ {
+				sum := 0.
+				for c := 0; c < 2; c++ {
+					for ki := 0; ki < 3; ki++ {
+						for kj := 0; kj < 2; kj++ {
+							iy, ix := oy*2-1+ki, ox*2-1+kj
+							if iy >= 0 && iy < 5 && ix >= 0 && ix < 4 {
+								sum += K.GetIJ(f, (c*3+ki)*2+kj) * A.GetIJ(c, iy*4+ix)
+							}
+						}
+					}
+				}
+				direct.SetIJ(f, oy*3+ox, sum)
+			}
+		}
+	}
+	if !isMatrixesClose(Conv2D(A, K, p), direct, 1e-12) {
+		t.Errorf("Conv2D error: Result != direct convolution")
+	}
+	if !isMatrixesClose(Conv2D(A.AsType(Float32), K.AsType(Float32), p).AsType(Float64), direct, 1e-5) {
+		t.Errorf("Conv2D float32 error: Result != direct convolution")
+	}
+
+	// градиенты линейных операций проверяются через сопряженность: <op(V), G> = <V, op^T(G)>
+	inner := func(A, B Matrix) float64 { return A.HadamardProduct(B).Sum() }
+	V := RandMatrix(2, 20)
+	W := RandMatrix(3, 12)
+	C := RandMatrix(12, 9)
+
+	dX, dK := Conv2DBackward(A, K, G, p)
+	adjoint := []struct {
+		name        string
+		left, right float64
+	}{
+		{"Im2Col/Col2Im", inner(Im2Col(V, p), C), inner(V, Col2Im(C, p))},
+		{"Conv2DBackward dX", inner(Conv2D(V, K, p), G), inner(V, dX)},
+		{"Conv2DBackward dK", inner(Conv2D(A, W, p), G), inner(W, dK)},
+		{"AvgPool2DBackward", inner(AvgPool2D(V, p), G.SubMatrix(0, 0, 2, 9)), inner(V, AvgPool2DBackward(G.SubMatrix(0, 0, 2, 9), p))},
+	}
+	for _, a := range adjoint {
+		if math.Abs(a.left-a.right) > 1e-12*(1+math.Abs(a.left)) {
+			t.Errorf("%s error: %v != %v", a.name, a.left, a.right)
+		}
+	}
+
+	// градиент max пулинга равен производной по каждому элементу
+	Y, argmax = MaxPool2D(V, p)
+	dV := MaxPool2DBackward(G.SubMatrix(0, 0, 2, 9), argmax, p)
+	h := 1e-7
+	for c := 0; c < 2; c++ {
+		for k := 0; k < 20; k++ {
+			Vh := V.AsType(Float64)
+			Vh.SetIJ(c, k, Vh.GetIJ(c, k)+h)
+			Yh, _ := MaxPool2D(Vh, p)
+			num := (inner(Yh, G.SubMatrix(0, 0, 2, 9)) - inner(Y, G.SubMatrix(0, 0, 2, 9))) / h
+			if math.Abs(num-dV.GetIJ(c, k)) > 1e-6 {
+				t.Fatalf("MaxPool2DBackward error: element %d, %d", c, k)
+			}
+		}
+	}
+
+	var shapeErr *ShapeError
+	if _, err := TryConv2D(A, RandMatrix(3, 11), p); !errors.As(err, &shapeErr) {
+		t.Errorf("Expected *ShapeError for Conv2D, got %v", err)
+	}
+	if _, err := TryIm2Col(A.T(), p); !errors.As(err, &shapeErr) {
+		t.Errorf("Expected *ShapeError for Im2Col, got %v", err)
+	}
+	bad := p
+	bad.StrideH = 0
+	if _, err := TryAvgPool2D(A, bad); err == nil {
+		t.Errorf("Expected error for zero stride")
+	}
+	if _, err := TryMaxPool2DBackward(G.SubMatrix(0, 0, 2, 9), argmax[1:], p); err == nil {
+		t.Errorf("Expected error for incorrect indexes")
+	}
+}
+
+func TestDebug(t *testing.T) {
+	for _, dtype := range []DType{Float64, Float32} {
+		M := Zero(2, 3).AsType(dtype)
+		if !M.IsFinite() || M.CountNaN() != 0 || M.CountInf() != 0 {
+			t.Fatalf("Zero matrix of %v is not finite", dtype)
+		}
+
+		M.SetIJ(0, 1, math.NaN())
+		M.SetIJ(1, 0, math.Inf(1))
+		M.SetIJ(1, 2, math.Inf(-1))
+		if M.IsFinite() || M.CountNaN() != 1 || M.CountInf() != 2 {
+			t.Errorf("Incorrect counts for %v: %d NaN, %d Inf", dtype, M.CountNaN(), M.CountInf())
+		}
+
+		// элементы за пределами подматрицы не учитываются
+		if !M.SubMatrix(0, 0, 1, 1).IsFinite() {
+			t.Errorf("SubMatrix of %v is not finite", dtype)
+		}
+	}
+
+	var got []*NonFiniteError
+	SetDebug(func(err *NonFiniteError) { got = append(got, err) })
+	defer SetDebug(nil)
+
+	if !Debug() {
+		t.Fatalf("Debug mode is not enabled")
+	}
+
+	A := Zero(2, 2)
+	A.SetIJ(0, 0, math.MaxFloat64)
+	A.Dot(A)
+	A.Add(A)
+	A.HadamardProduct(Zero(2, 2))
+	if len(got) != 2 || got[0].Op != "Dot" || got[1].Op != "Add" {
+		t.Fatalf("Incorrect reported operations: %v", got)
+	}
+	if got[0].Inf != 1 || got[0].NaN != 0 || got[0].Shape != (Shape{Rows: 2, Columns: 2}) {
+		t.Errorf("Incorrect report: %v", got[0])
+	}
+
+	dst := Zero(2, 2)
+	AddInto(dst, A, A)
+	A.ForEach(func(x float64) float64 { return math.Log(x - 1) })
+	if len(got) != 4 || got[2].Op != "AddInto" || got[3].Op != "ForEach" || got[3].NaN != 3 {
+		t.Errorf("Incorrect reported operations: %v", got[2:])
+	}
+
+	SetDebug(PanicOnNonFinite)
+	func() {
+		defer func() {
+			var err *NonFiniteError
+			if e, ok := recover().(error); !ok || !errors.As(e, &err) || err.Op != "ScaleInPlace" {
+				t.Errorf("Expected panic with *NonFiniteError for ScaleInPlace")
+			}
+		}()
+		A.ScaleInPlace(2)
+	}()
+
+	SetDebug(nil)
+	if Debug() {
+		t.Errorf("Debug mode is not disabled")
+	}
+	A.Dot(A)
+}
+
+func TestMatrixMarket(t *testing.T) {
+	files := []struct {
+		name     string
+		data     string
+		expected []float64
+	}{
+		{"coordinate", "%%MatrixMarket matrix coordinate real general\n% comment\n\n2 3 3\n1 1 1.5\n2 3 -2\n1 1 0.5\n", []float64{2, 0, 0, 0, 0, -2}},
+		{"pattern", "%%MatrixMarket matrix coordinate pattern general\n2 3 2\n1 2\n2 1\n", []float64{0, 1, 0, 1, 0, 0}},
+		{"array", "%%MatrixMarket matrix array real general\n2 3\n1\n4\n2\n5\n3\n6\n", []float64{1, 2, 3, 4, 5, 6}},
+		{"symmetric", "%%MatrixMarket matrix coordinate integer symmetric\n3 3 3\n1 1 1\n3 1 2\n3 2 3\n", []float64{1, 0, 2, 0, 0, 3, 2, 3, 0}},
+		{"skew-symmetric array", "%%MatrixMarket matrix array real skew-symmetric\n3 3\n1\n2\n3\n", []float64{0, -1, -2, 1, 0, -3, 2, 3, 0}},
+		{"symmetric array", "%%MatrixMarket matrix array real symmetric\n3 3\n1\n2\n3\n4\n5\n6\n", []float64{1, 2, 3, 2, 4, 5, 3, 5, 6}},
+	}
+	for _, f := range files {
+		M, err := ReadMatrixMarket(bytes.NewBufferString(f.data))
+		if err != nil {
+			t.Fatalf("%s: %v", f.name, err)
+		}
+		expected := Zero(M.GetRows(), M.GetColumns())
+		expected.Slice2Matrix(f.expected)
+		if !IsMatrixesEqual(M, expected) {
+			t.Errorf("%s: incorrect matrix", f.name)
+		}
+
+		S, err := ReadSparseMatrixMarket(bytes.NewBufferString(f.data))
+		if err != nil || !IsMatrixesEqual(S.ToMatrix(), expected) {
+			t.Errorf("%s: incorrect sparse matrix: %v", f.name, err)
+		}
+	}
+
+	// запись без потери точности
+	A := RandMatrixFrom(3, 4, Float64, rand.New(rand.NewSource(1)))
+	A.SetIJ(1, 2, 0)
+	var buf bytes.Buffer
+	if err := WriteMatrixMarket(&buf, A); err != nil {
+		t.Fatal(err)
+	}
+	if B, err := ReadMatrixMarket(&buf); err != nil || !IsMatrixesEqual(A, B) {
+		t.Errorf("Incorrect array round trip: %v", err)
+	}
+
+	S := SparseFromMatrix(A)
+	buf.Reset()
+	if err := WriteSparseMatrixMarket(&buf, S); err != nil {
+		t.Fatal(err)
+	}
+	if B, err := ReadSparseMatrixMarket(&buf); err != nil || B.NNZ() != 11 || !IsMatrixesEqual(A, B.ToMatrix()) {
+		t.Errorf("Incorrect coordinate round trip: %v", err)
+	}
+
+	malformed := []struct {
+		data string
+		line int
+	}{
+		{"%%MatrixMarket matrix coordinate complex general\n1 1 1\n1 1 1 0\n", 1},
+		{"%%MatrixMarket matrix coordinate real general\n2 2\n", 2},
+		{"%%MatrixMarket matrix coordinate real general\n2 2 2\n1 1 1\n3 1 1\n", 4},
+		{"%%MatrixMarket matrix coordinate real general\n2 2 1\n1 1 x\n", 3},
+		{"%%MatrixMarket matrix coordinate real general\n2 2 2\n1 1 1\n", 3},
+		{"%%MatrixMarket matrix coordinate real symmetric\n2 2 1\n1 2 1\n", 3},
+		{"%%MatrixMarket matrix array real general\n1 2\n1\n2\n3\n", 5},
+		{"%%MatrixMarket matrix coordinate real general\n3037000500 3037000500 1\n1 1 1\n", 2},
+		{"%%MatrixMarket matrix array real general\n% comment\n9223372036854775807 2\n1\n", 3},
+	}
+	for _, m := range malformed {
+		var parseErr *ParseError
+		if _, err := ReadMatrixMarket(bytes.NewBufferString(m.data)); !errors.As(err, &parseErr) || parseErr.Line != m.line {
+			t.Errorf("Expected *ParseError at line %d for %q, got %v", m.line, m.data, err)
+		}
+		if _, err := ReadSparseMatrixMarket(bytes.NewBufferString(m.data)); !errors.As(err, &parseErr) || parseErr.Line != m.line {
+			t.Errorf("Sparse: expected *ParseError at line %d for %q, got %v", m.line, m.data, err)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	M := Zero(2, 3)
+	M.Slice2Matrix([]float64{1, -2.5, 3, 4, 5, 60})
+
+	tests := []struct {
+		format   string
+		M        any
+		expected string
+	}{
+		{"%v", M, "[[   1 -2.5    3]\n [   4    5   60]]"},
+		{"%.2f", M, "[[ 1.00 -2.50  3.00]\n [ 4.00  5.00 60.00]]"},
+		{"%+v", M.AsType(Float32), "Matrix(2*3, float32)\n[[   1 -2.5    3]\n [   4    5   60]]"},
+		{"%v", Matrix{}, "[]"},
+		{"%d", M, "%!d(matrix.Matrix=2*3)"},
+	}
+	for _, test := range tests {
+		if got := fmt.Sprintf(test.format, test.M); got != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.format, test.expected, got)
+		}
+	}
+
+	if got := M.AsType(Float32).SubMatrix(0, 0, 1, 1); got.String() != "[[1]]" {
+		t.Errorf("Incorrect String: %q", got.String())
+	}
+
+	// сокращение больших матриц
+	defer SetPrintOptions(GetPrintOptions())
+	if err := SetPrintOptions(PrintOptions{Threshold: 4, EdgeItems: 1}); err != nil {
+		t.Fatal(err)
+	}
+	A := Zero(3, 3)
+	A.Slice2Matrix([]float64{0, 1, 2, 3, 4, 5, 6, 7, 8})
+	if got := A.String(); got != "[[0 ... 2]\n ...\n [6 ... 8]]" {
+		t.Errorf("Incorrect truncated output %q", got)
+	}
+	if got := fmt.Sprintf("%#v", A); got != "[[0 1 2]\n [3 4 5]\n [6 7 8]]" {
+		t.Errorf("Incorrect full output %q", got)
+	}
+	if err := SetPrintOptions(PrintOptions{EdgeItems: 0}); err == nil {
+		t.Errorf("Expected error for incorrect print options")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	M := Zero(2, 3)
+	M.Slice2Matrix([]float64{1, -2.5, 3, 4, 5, 60})
+
+	b, err := json.Marshal(M)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"shape":[2,3],"dtype":"float64","data":[1,-2.5,3,4,5,60]}` {
+		t.Errorf("Incorrect JSON %s", b)
+	}
+
+	// матрица в составе структуры, вид без копирования
+	type params struct {
+		W Matrix
+		B Matrix
+	}
+	for _, dtype := range []DType{Float64, Float32} {
+		p := params{W: RandMatrixOf(4, 3, dtype).SubMatrix(1, 1, 2, 2), B: RandMatrixOf(2, 1, dtype)}
+		b, err := json.Marshal(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var res params
+		if err := json.Unmarshal(b, &res); err != nil {
+			t.Fatal(err)
+		}
+		if res.W.DType() != dtype || !IsMatrixesEqual(p.W, res.W) || !IsMatrixesEqual(p.B, res.B) {
+			t.Errorf("Incorrect JSON round trip for %v", dtype)
+		}
+	}
+
+	M.SetIJ(0, 0, math.NaN())
+	if _, err := json.Marshal(M); err == nil {
+		t.Errorf("Expected error for NaN")
+	}
+
+	for _, data := range []string{
+		`{"shape":[2,2],"data":[1,2,3]}`,
+		`{"shape":[0,2],"data":[]}`,
+		`{"shape":[2],"data":[1,2]}`,
+		`{"shape":[1,1],"dtype":"int","data":[1]}`,
+	} {
+		var res Matrix
+		if err := json.Unmarshal([]byte(data), &res); err == nil {
+			t.Errorf("Expected error for %s", data)
+		}
+	}
+}
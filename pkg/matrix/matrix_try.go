@@ -0,0 +1,177 @@
+package matrix
+
+/*
+Методы с префиксом Try работают так же, как одноименные методы без префикса,
+но вместо паники возвращают ошибку.
+При несовместимых размерностях операндов возвращается *ShapeError,
+при различных типах элементов операндов *DTypeError.
+Ошибка возвращается до начала вычислений, поэтому при ошибке операнды не изменяются.
+*/
+
+// TryZero работает так же, как Zero, но возвращает *ShapeError,
+// если указанные размеры матрицы не являются положительными числами.
+func TryZero(rows, columns int) (Matrix, error) {
+	return TryZeroOf(rows, columns, Float64)
+}
+
+// TryZeroOf работает так же, как ZeroOf, но возвращает *ShapeError,
+// если указанные размеры матрицы не являются положительными числами.
+func TryZeroOf(rows, columns int, dtype DType) (Matrix, error) {
+	if err := checkDims("Zero", rows, columns); err != nil {
+		return Matrix{}, err
+	}
+
+	return ZeroOf(rows, columns, dtype), nil
+}
+
+// TryDataToMatrix работает так же, как DataToMatrix, но возвращает *ShapeError,
+// если слайс пуст или состоит из неравных по количеству элементов строк.
+func TryDataToMatrix(arr [][]float64) (Matrix, error) {
+	if err := checkData(arr); err != nil {
+		return Matrix{}, err
+	}
+
+	return DataToMatrix(arr), nil
+}
+
+// TrySlice2Matrix работает так же, как Slice2Matrix, но возвращает *ShapeError,
+// если количество элементов матрицы не равно количеству элементов слайса.
+func (M Matrix) TrySlice2Matrix(slc []float64) error {
+	if err := M.matrix.checkSlice(slc); err != nil {
+		return err
+	}
+
+	M.Slice2Matrix(slc)
+	return nil
+}
+
+// TryDot работает так же, как Dot, но возвращает ошибку,
+// если исходные матрицы нельзя перемножить по определению.
+func (A Matrix) TryDot(B Matrix) (Matrix, error) {
+	if err := checkProduct("Dot", false, false, A.matrix, B.matrix); err != nil {
+		return Matrix{}, err
+	}
+
+	return A.Dot(B), nil
+}
+
+// TryTDot работает так же, как TDot, но возвращает ошибку,
+// если количество строк матриц A и B не совпадает.
+func (A Matrix) TryTDot(B Matrix) (Matrix, error) {
+	if err := checkProduct("TDot", true, false, A.matrix, B.matrix); err != nil {
+		return Matrix{}, err
+	}
+
+	return A.TDot(B), nil
+}
+
+// TryDotT работает так же, как DotT, но возвращает ошибку,
+// если количество столбцов матриц A и B не совпадает.
+func (A Matrix) TryDotT(B Matrix) (Matrix, error) {
+	if err := checkProduct("DotT", false, true, A.matrix, B.matrix); err != nil {
+		return Matrix{}, err
+	}
+
+	return A.DotT(B), nil
+}
+
+// TryAdd работает так же, как Add, но возвращает ошибку, если размерности исходных матриц не равны.
+func (A Matrix) TryAdd(B Matrix) (Matrix, error) {
+	if err := checkSameShape("Add", A.matrix, B.matrix); err != nil {
+		return Matrix{}, err
+	}
+
+	return A.Add(B), nil
+}
+
+// TrySub работает так же, как Sub, но возвращает ошибку, если размерности исходных матриц не равны.
+func (A Matrix) TrySub(B Matrix) (Matrix, error) {
+	if err := checkSameShape("Sub", A.matrix, B.matrix); err != nil {
+		return Matrix{}, err
+	}
+
+	return A.Sub(B), nil
+}
+
+// TryHadamardProduct работает так же, как HadamardProduct, но возвращает ошибку,
+// если размерности исходных матриц не равны.
+func (A Matrix) TryHadamardProduct(B Matrix) (Matrix, error) {
+	if err := checkSameShape("HadamardProduct", A.matrix, B.matrix); err != nil {
+		return Matrix{}, err
+	}
+
+	return A.HadamardProduct(B), nil
+}
+
+// TryAddInPlace работает так же, как AddInPlace, но возвращает ошибку,
+// если размерности исходных матриц не равны. При ошибке матрица A не изменяется.
+func (A Matrix) TryAddInPlace(B Matrix) error {
+	if err := checkSameShape("AddInPlace", A.matrix, B.matrix); err != nil {
+		return err
+	}
+
+	A.AddInPlace(B)
+	return nil
+}
+
+// TrySubInPlace работает так же, как SubInPlace, но возвращает ошибку,
+// если размерности исходных матриц не равны. При ошибке матрица A не изменяется.
+func (A Matrix) TrySubInPlace(B Matrix) error {
+	if err := checkSameShape("SubInPlace", A.matrix, B.matrix); err != nil {
+		return err
+	}
+
+	A.SubInPlace(B)
+	return nil
+}
+
+// TryHadamardProductInPlace работает так же, как HadamardProductInPlace, но возвращает ошибку,
+// если размерности исходных матриц не равны. При ошибке матрица A не изменяется.
+func (A Matrix) TryHadamardProductInPlace(B Matrix) error {
+	if err := checkSameShape("HadamardProductInPlace", A.matrix, B.matrix); err != nil {
+		return err
+	}
+
+	A.HadamardProductInPlace(B)
+	return nil
+}
+
+// TryScaleRows работает так же, как ScaleRows, но возвращает *ShapeError,
+// если размерность вектора v не равна n на 1.
+func (S Sparse) TryScaleRows(v Matrix) (Sparse, error) {
+	if err := S.matrix.checkScaleRows(v.matrix); err != nil {
+		return Sparse{}, err
+	}
+
+	return S.ScaleRows(v), nil
+}
+
+// TryDot работает так же, как Dot, но возвращает *ShapeError,
+// если исходные матрицы нельзя перемножить по определению.
+func (S Sparse) TryDot(B Matrix) (Matrix, error) {
+	if err := S.matrix.checkDot(B.matrix); err != nil {
+		return Matrix{}, err
+	}
+
+	return S.Dot(B), nil
+}
+
+// TryDotSparse работает так же, как DotSparse, но возвращает *ShapeError,
+// если исходные матрицы нельзя перемножить по определению.
+func (A Matrix) TryDotSparse(S Sparse) (Matrix, error) {
+	if err := A.matrix.checkDotSparse(S.matrix, false); err != nil {
+		return Matrix{}, err
+	}
+
+	return A.DotSparse(S), nil
+}
+
+// TryDotTSparse работает так же, как DotTSparse, но возвращает *ShapeError,
+// если количество столбцов матриц A и S не совпадает.
+func (A Matrix) TryDotTSparse(S Sparse) (Matrix, error) {
+	if err := A.matrix.checkDotSparse(S.matrix, true); err != nil {
+		return Matrix{}, err
+	}
+
+	return A.DotTSparse(S), nil
+}
@@ -1,45 +1,54 @@
 package matrix
 
 import (
-	"fmt"
 	"math"
 	"math/rand"
-	"time"
 )
 
 // myMatrix представляет структуру матрицы.
 // Индексация элементов начинается с 0. Это означает, что первый элемент в любом столбце или строке
 // имеет индекс 0, а не 1. Структура хранит количество столбцов и строк, а также саму матрицу
-// в виде слайса [][]float64.
+// в виде одного непрерывного слайса, в котором строки идут друг за другом (row-major).
+// Элемент i строки, j столбца находится в data[i*stride+j] (или data32[i*stride+j]).
+// В зависимости от типа элементов dtype данные хранятся либо в data, либо в data32, второй слайс равен nil.
 type myMatrix struct {
-	columns int         // Количество столбцов в матрице
-	rows    int         // Количество строк в матрице
-	data    [][]float64 // Данные матрицы, хранящиеся в двумерном массиве
+	columns int       // Количество столбцов в матрице
+	rows    int       // Количество строк в матрице
+	stride  int       // Расстояние в элементах между началами соседних строк в data
+	dtype   DType     // Тип элементов матрицы
+	data    []float64 // Данные матрицы с элементами float64, хранящиеся построчно в одном слайсе
+	data32  []float32 // Данные матрицы с элементами float32, хранящиеся построчно в одном слайсе
 }
 
-// init инициализирует генератор псевдослучайных чисел.
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
-// zero создает и возвращает указатель на новый экземпляр myMatrix с заданными размерами rows и columns.
+// zero создает и возвращает указатель на новый экземпляр myMatrix с заданными размерами rows и columns
+// и элементами float64.
 // Все элементы матрицы инициализируются нулями.
 // Функция вызывает панику, если указанные размеры матрицы не являются положительными числами.
 func zero(rows, columns int) *myMatrix {
-	if rows <= 0 || columns <= 0 {
-		panic("myMatrix dimensions must be positive: rows and columns should be greater than 0")
-	}
+	return zeroOf(rows, columns, Float64)
+}
 
-	data := make([][]float64, rows)
-	for i := range data {
-		data[i] = make([]float64, columns)
-	}
+// zeroOf создает и возвращает указатель на новый экземпляр myMatrix с заданными размерами rows и columns
+// и типом элементов dtype.
+// Все элементы матрицы инициализируются нулями.
+// Функция вызывает панику, если указанные размеры матрицы не являются положительными числами.
+func zeroOf(rows, columns int, dtype DType) *myMatrix {
+	must(checkDims("Zero", rows, columns))
 
-	return &myMatrix{
+	M := &myMatrix{
 		columns: columns,
 		rows:    rows,
-		data:    data,
+		stride:  columns,
+		dtype:   dtype,
+	}
+
+	if dtype == Float32 {
+		M.data32 = make([]float32, rows*columns)
+	} else {
+		M.data = make([]float64, rows*columns)
 	}
+
+	return M
 }
 
 // randMatrix создает и возвращает указатель на новый экземпляр myMatrix с заданными размерами rows и columns.
@@ -48,11 +57,24 @@ func zero(rows, columns int) *myMatrix {
 // Предупреждение: функция может вызвать панику, если rows или columns будут не положительными,
 // поскольку внутренне вызывается функция zero, требующая положительных значений для этих параметров.
 func randMatrix(rows, columns int) *myMatrix {
-	myMatrix := zero(rows, columns)
+	return randMatrixOf(rows, columns, Float64, nil)
+}
+
+// randMatrixOf работает так же, как randMatrix, но создает матрицу с типом элементов dtype
+// и берет случайные значения из генератора rng.
+// Если rng равен nil, используется глобальный генератор пакета math/rand.
+// Элементы генерируются построчно, поэтому один и тот же генератор дает одну и ту же матрицу.
+func randMatrixOf(rows, columns int, dtype DType, rng *rand.Rand) *myMatrix {
+	myMatrix := zeroOf(rows, columns, dtype)
+
+	normFloat64 := rand.NormFloat64
+	if rng != nil {
+		normFloat64 = rng.NormFloat64
+	}
 
 	for i := 0; i < rows; i++ {
 		for j := 0; j < columns; j++ {
-			myMatrix.data[i][j] = rand.NormFloat64() * 0.01
+			myMatrix.setIJ(i, j, normFloat64()*0.01)
 		}
 	}
 
@@ -71,41 +93,42 @@ func (M *myMatrix) getColumns() int {
 
 // getIJ возвращает элемент i строки, j столбца данной матрицы (структуры myMatrix).
 func (M *myMatrix) getIJ(i, j int) float64 {
-	return M.data[i][j]
+	if M.dtype == Float32 {
+		return float64(M.data32[i*M.stride+j])
+	}
+	return M.data[i*M.stride+j]
 }
 
 // setIJ устанавливает элемент i строки, j столбца данной матрицы (структуры myMatrix).
+// Для матриц с элементами float32 значение x округляется до float32.
 func (M *myMatrix) setIJ(i, j int, x float64) {
-	M.data[i][j] = x
+	if M.dtype == Float32 {
+		M.data32[i*M.stride+j] = float32(x)
+		return
+	}
+	M.data[i*M.stride+j] = x
 }
 
-// show выводит матрицу (структуру myMatrix) в консоль.
-func (M *myMatrix) show() {
-	for _, row := range M.data {
-		fmt.Println(row)
-	}
+// row возвращает слайс элементов i строки данной матрицы (структуры myMatrix) с элементами float64.
+// Возвращаемый слайс разделяет память с матрицей.
+func (M *myMatrix) row(i int) []float64 {
+	start := i * M.stride
+	return M.data[start : start+M.columns]
 }
 
 // dataToMatrix предназначена для тестов.
 // Создает и возвращает указатель на матрицу (структуру myMatrix).
-// Структура myMatrix получена из слайса [][]float64.
+// Структура myMatrix получена копированием слайса [][]float64.
 // Функция вызывает панику если слайс состоит из неравных по количеству элементов строк.
 func dataToMatrix(arr [][]float64) *myMatrix {
-	len0 := len(arr[0])
-	for i := 1; i < len(arr); i++ {
-		if len(arr[i]) != len0 {
-			panic("different rows of the matrix have different lengths")
-		}
-	}
-
-	rows := len(arr)
-	columns := len(arr[0])
+	must(checkData(arr))
 
-	return &myMatrix{
-		rows:    rows,
-		columns: columns,
-		data:    arr,
+	M := zero(len(arr), len(arr[0]))
+	for i := 0; i < M.getRows(); i++ {
+		copy(M.row(i), arr[i])
 	}
+
+	return M
 }
 
 // isMatrixesEqual предназначена для тестов.
@@ -122,7 +145,7 @@ func isMatrixesEqual(A, B *myMatrix) bool {
 	// проверка на равность
 	for i := 0; i < A.getRows(); i++ {
 		for j := 0; j < A.getColumns(); j++ {
-			if math.Abs(A.data[i][j]-B.data[i][j]) > epsilon {
+			if math.Abs(A.getIJ(i, j)-B.getIJ(i, j)) > epsilon {
 				return false
 			}
 		}
@@ -137,7 +160,7 @@ func _countUniqueElements(M *myMatrix) int {
 	uniqueElements := make(map[float64]bool)
 	for i := 0; i < M.getRows(); i++ {
 		for j := 0; j < M.getColumns(); j++ {
-			uniqueElements[M.data[i][j]] = true
+			uniqueElements[M.getIJ(i, j)] = true
 		}
 	}
 
@@ -0,0 +1,200 @@
+package matrix
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+)
+
+// файл содержит двоичный формат записи матриц без потери точности.
+//
+// Формат версии 1, все числа little-endian:
+// заголовок: магические байты "GBMX", версия uint16, резерв uint16 (0), количество матриц uint32;
+// далее для каждой матрицы: тип элементов uint8 (0 float64, 1 float32), резерв 3 байта (0),
+// количество строк uint32, количество столбцов uint32, элементы в порядке строк,
+// контрольная сумма CRC-32 (IEEE) описания и элементов матрицы uint32.
+// Матрицы записываются и читаются потоково, по одной строке, без буферизации всего файла.
+// Количество элементов одной матрицы не больше binaryMaxElements. Память под матрицы и элементы
+// при чтении выделяется по мере поступления данных, поэтому поврежденный заголовок с огромными
+// размерностями приводит к ошибке конца потока, а не к выделению памяти по прочитанным размерностям.
+
+// binaryMagic первые байты двоичного файла матриц.
+const binaryMagic = "GBMX"
+
+// binaryVersion текущая версия двоичного формата.
+const binaryVersion = 1
+
+// binaryMaxElements наибольшее количество элементов одной матрицы в двоичном формате.
+const binaryMaxElements = math.MaxInt32
+
+// binaryChunkElements количество элементов, под которое память при чтении матрицы выделяется сразу,
+// дальше память растет вместе с прочитанными строками.
+const binaryChunkElements = 1 << 16
+
+// ErrChecksum возвращается при чтении матрицы, контрольная сумма которой не совпадает с записанной.
+var ErrChecksum = errors.New("matrix checksum mismatch")
+
+// binaryDTypes коды типов элементов в двоичном формате.
+var binaryDTypes = map[DType]uint8{Float64: 0, Float32: 1}
+
+// writeMatrixesBinary записывает матрицы в поток writer в двоичном формате.
+// Функция не открывает и не закрывает поток вывода.
+func writeMatrixesBinary(writer io.Writer, matrixes []*myMatrix) error {
+	w := bufio.NewWriter(writer)
+
+	header := make([]byte, 0, 12)
+	header = append(header, binaryMagic...)
+	header = binary.LittleEndian.AppendUint16(header, binaryVersion)
+	header = binary.LittleEndian.AppendUint16(header, 0)
+	header = binary.LittleEndian.AppendUint32(header, uint32(len(matrixes)))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	crc := crc32.NewIEEE()
+	for _, M := range matrixes {
+		crc.Reset()
+		if err := writeMatrixBinary(io.MultiWriter(w, crc), M); err != nil {
+			return err
+		}
+		if _, err := w.Write(binary.LittleEndian.AppendUint32(nil, crc.Sum32())); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
+
+// writeMatrixBinary записывает описание и элементы матрицы M в поток writer.
+func writeMatrixBinary(writer io.Writer, M *myMatrix) error {
+	desc := make([]byte, 0, 12)
+	desc = append(desc, binaryDTypes[M.dtype], 0, 0, 0)
+	desc = binary.LittleEndian.AppendUint32(desc, uint32(M.rows))
+	desc = binary.LittleEndian.AppendUint32(desc, uint32(M.columns))
+	if _, err := writer.Write(desc); err != nil {
+		return err
+	}
+
+	row := make([]byte, 0, M.columns*M.dtype.Size())
+	for i := 0; i < M.rows; i++ {
+		row = row[:0]
+		if M.dtype == Float32 {
+			for _, x := range M.dense32().row(i) {
+				row = binary.LittleEndian.AppendUint32(row, math.Float32bits(x))
+			}
+		} else {
+			for _, x := range M.dense64().row(i) {
+				row = binary.LittleEndian.AppendUint64(row, math.Float64bits(x))
+			}
+		}
+
+		if _, err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readMatrixesBinary читает матрицы из потока reader в двоичном формате.
+// Поток читается через буфер, поэтому данные после последней матрицы могут быть прочитаны наперед,
+// если reader не является *bufio.Reader (тогда они остаются в его буфере).
+// Функция возвращает ErrChecksum, если данные матрицы повреждены,
+// и ошибку, если поток не является двоичным файлом матриц или закончился раньше времени.
+func readMatrixesBinary(reader io.Reader) ([]*myMatrix, error) {
+	r := bufio.NewReader(reader)
+
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("reading binary matrix header: %w", err)
+	}
+	if string(header[:4]) != binaryMagic {
+		return nil, fmt.Errorf("incorrect binary matrix magic %q", header[:4])
+	}
+	if version := binary.LittleEndian.Uint16(header[4:]); version != binaryVersion {
+		return nil, fmt.Errorf("unsupported binary matrix format version %d", version)
+	}
+
+	// количество матриц не проверено, поэтому слайс растет по мере чтения матриц
+	count := binary.LittleEndian.Uint32(header[8:])
+	var matrixes []*myMatrix
+
+	crc := crc32.NewIEEE()
+	for i := uint32(0); i < count; i++ {
+		crc.Reset()
+		M, err := readMatrixBinary(io.TeeReader(r, crc))
+		if err != nil {
+			return nil, fmt.Errorf("reading binary matrix %d: %w", i, err)
+		}
+
+		sum := make([]byte, 4)
+		if _, err := io.ReadFull(r, sum); err != nil {
+			return nil, fmt.Errorf("reading binary matrix %d checksum: %w", i, err)
+		}
+		if binary.LittleEndian.Uint32(sum) != crc.Sum32() {
+			return nil, fmt.Errorf("reading binary matrix %d: %w", i, ErrChecksum)
+		}
+
+		matrixes = append(matrixes, M)
+	}
+
+	return matrixes, nil
+}
+
+// readMatrixBinary читает описание и элементы одной матрицы из потока reader.
+func readMatrixBinary(reader io.Reader) (*myMatrix, error) {
+	desc := make([]byte, 12)
+	if _, err := io.ReadFull(reader, desc); err != nil {
+		return nil, err
+	}
+
+	var dtype DType
+	switch desc[0] {
+	case binaryDTypes[Float64]:
+		dtype = Float64
+	case binaryDTypes[Float32]:
+		dtype = Float32
+	default:
+		return nil, fmt.Errorf("unknown element type code %d", desc[0])
+	}
+
+	rows := int(binary.LittleEndian.Uint32(desc[4:]))
+	columns := int(binary.LittleEndian.Uint32(desc[8:]))
+	if rows <= 0 || columns <= 0 || rows > binaryMaxElements/columns {
+		return nil, fmt.Errorf("incorrect matrix dimension %d*%d", rows, columns)
+	}
+
+	// размерности не проверены, поэтому элементы дописываются по строкам,
+	// и память выделяется не больше, чем под уже прочитанные данные
+	capacity := rows * columns
+	if capacity > binaryChunkElements {
+		capacity = binaryChunkElements
+	}
+	M := &myMatrix{rows: rows, columns: columns, stride: columns, dtype: dtype}
+	if dtype == Float32 {
+		M.data32 = make([]float32, 0, capacity)
+	} else {
+		M.data = make([]float64, 0, capacity)
+	}
+
+	row := make([]byte, columns*dtype.Size())
+	for i := 0; i < rows; i++ {
+		if _, err := io.ReadFull(reader, row); err != nil {
+			return nil, err
+		}
+
+		for j := 0; j < columns; j++ {
+			if dtype == Float32 {
+				M.data32 = append(M.data32, math.Float32frombits(binary.LittleEndian.Uint32(row[4*j:])))
+			} else {
+				M.data = append(M.data, math.Float64frombits(binary.LittleEndian.Uint64(row[8*j:])))
+			}
+		}
+	}
+
+	return M, nil
+}
@@ -0,0 +1,104 @@
+package matrix
+
+// файл содержит поэлементные операции матрицы и вектора, растянутого на всю матрицу
+
+// broadcastOp поэлементная операция над матрицей и растянутым вектором.
+type broadcastOp int
+
+const (
+	broadcastAdd broadcastOp = iota // сложение
+	broadcastSub                    // вычитание
+	broadcastMul                    // умножение
+)
+
+// checkBroadcast возвращает *ShapeError, если v не является вектором-столбцом с количеством строк A
+// или вектором-строкой с количеством столбцов A, и *DTypeError, если различаются типы элементов.
+func checkBroadcast(op string, A, v *myMatrix) error {
+	column := v.rows == A.rows && v.columns == 1
+	row := v.rows == 1 && v.columns == A.columns
+
+	if !column && !row {
+		return &ShapeError{Op: op, A: A.shape(), B: v.shape()}
+	}
+	return checkDType(op, A, v)
+}
+
+// broadcastInto записывает в C результат операции op над матрицей A и вектором v,
+// растянутым на размерность A: вектор-столбец повторяется в каждом столбце, вектор-строка в каждой строке.
+// C может совпадать с A, размерности должны быть проверены заранее.
+func broadcastInto(op broadcastOp, A, v, C *myMatrix) {
+	if C.dtype == Float32 {
+		broadcastRows(op, C.dense32(), A.dense32(), v.dense32())
+		return
+	}
+	broadcastRows(op, C.dense64(), A.dense64(), v.dense64())
+}
+
+// broadcastRows записывает в C результат операции op над матрицей A и растянутым вектором v.
+// Если A сама является вектором-столбцом, то v считается вектором-столбцом.
+func broadcastRows[T float](op broadcastOp, C, A, v dense[T]) {
+	column := v.columns == 1 && v.rows == A.rows
+
+	for i := 0; i < C.rows; i++ {
+		rowC, rowA := C.row(i), A.row(i)
+
+		// для вектора-столбца вся строка обрабатывается с одним элементом вектора
+		if column {
+			x := v.data[i*v.stride]
+			switch op {
+			case broadcastAdd:
+				for j := range rowC {
+					rowC[j] = rowA[j] + x
+				}
+			case broadcastSub:
+				for j := range rowC {
+					rowC[j] = rowA[j] - x
+				}
+			case broadcastMul:
+				for j := range rowC {
+					rowC[j] = rowA[j] * x
+				}
+			}
+			continue
+		}
+
+		rowV := v.row(0)
+		switch op {
+		case broadcastAdd:
+			for j := range rowC {
+				rowC[j] = rowA[j] + rowV[j]
+			}
+		case broadcastSub:
+			for j := range rowC {
+				rowC[j] = rowA[j] - rowV[j]
+			}
+		case broadcastMul:
+			for j := range rowC {
+				rowC[j] = rowA[j] * rowV[j]
+			}
+		}
+	}
+}
+
+// broadcast возвращает указатель на новую матрицу, равную результату операции op над матрицей A
+// и растянутым вектором v.
+// Метод вызывает панику, если v нельзя растянуть на размерность A.
+func (A *myMatrix) broadcast(name string, op broadcastOp, v *myMatrix) *myMatrix {
+	must(checkBroadcast(name, A, v))
+
+	C := zeroOf(A.rows, A.columns, A.dtype)
+	broadcastInto(op, A, v, C)
+	debugCheck(name, C)
+
+	return C
+}
+
+// broadcastInPlace записывает в A результат операции op над матрицей A и растянутым вектором v, изменяя A.
+// Метод вызывает панику, если v нельзя растянуть на размерность A или v частично перекрывает A.
+func (A *myMatrix) broadcastInPlace(name string, op broadcastOp, v *myMatrix) {
+	must(checkBroadcast(name, A, v))
+	must(checkOverlap(name, A, v))
+
+	broadcastInto(op, A, v, A)
+	debugCheck(name, A)
+}
@@ -0,0 +1,266 @@
+package matrix
+
+import (
+	"fmt"
+	"math"
+)
+
+// файл содержит преобразования im2col и col2im, свертку и пулинг изображений.
+// Изображение с channels каналами размера height на width хранится в матрице channels на height*width,
+// каждая строка которой является каналом, элементы канала хранятся по строкам изображения.
+
+// outSize возвращает размер выхода по одному направлению для входа размера n,
+// окна размера k, шага stride и дополнения нулями pad с обеих сторон.
+func outSize(n, k, stride, pad int) int {
+	return (n+2*pad-k)/stride + 1
+}
+
+// check возвращает ошибку, если параметры s некорректны:
+// размеры не положительны, шаг не положителен, дополнение отрицательно или окно больше дополненного входа.
+func (s Conv2DShape) check(op string) error {
+	if s.Channels <= 0 || s.Height <= 0 || s.Width <= 0 || s.KernelHeight <= 0 || s.KernelWidth <= 0 ||
+		s.StrideH <= 0 || s.StrideW <= 0 || s.PadH < 0 || s.PadW < 0 ||
+		s.KernelHeight > s.Height+2*s.PadH || s.KernelWidth > s.Width+2*s.PadW {
+		return fmt.Errorf("incorrect convolution parameters for %s: %+v", op, s)
+	}
+	return nil
+}
+
+// checkImage возвращает ошибку, если параметры s некорректны, и *ShapeError,
+// если размерность матрицы X не равна rows на columns.
+func (s Conv2DShape) checkImage(op string, X *myMatrix, rows, columns int) error {
+	if err := s.check(op); err != nil {
+		return err
+	}
+	if X.rows != rows || X.columns != columns {
+		return &ShapeError{Op: op, A: X.shape(), B: Shape{Rows: rows, Columns: columns}}
+	}
+	return nil
+}
+
+// im2colRows записывает в C развертку изображения X: строка (c*kh+ki)*kw+kj матрицы C содержит
+// элементы канала c, попадающие в позицию ki, kj окна, для всех положений окна.
+// При add равном true выполняется обратное преобразование: элементы C прибавляются к X (col2im).
+func im2colRows[T float](s Conv2DShape, X, C dense[T], add bool) {
+	outH, outW := s.OutHeight(), s.OutWidth()
+
+	for c := 0; c < s.Channels; c++ {
+		rowX := X.row(c)
+		for ki := 0; ki < s.KernelHeight; ki++ {
+			for kj := 0; kj < s.KernelWidth; kj++ {
+				rowC := C.row((c*s.KernelHeight+ki)*s.KernelWidth + kj)
+
+				for oy := 0; oy < outH; oy++ {
+					iy := oy*s.StrideH - s.PadH + ki
+					if iy < 0 || iy >= s.Height {
+						continue
+					}
+
+					for ox := 0; ox < outW; ox++ {
+						ix := ox*s.StrideW - s.PadW + kj
+						if ix < 0 || ix >= s.Width {
+							continue
+						}
+
+						if add {
+							rowX[iy*s.Width+ix] += rowC[oy*outW+ox]
+						} else {
+							rowC[oy*outW+ox] = rowX[iy*s.Width+ix]
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// im2col возвращает указатель на развертку изображения X размерности
+// channels*kernelHeight*kernelWidth на outHeight*outWidth. Параметры должны быть проверены заранее.
+func im2col(s Conv2DShape, X *myMatrix) *myMatrix {
+	C := zeroOf(s.Channels*s.KernelHeight*s.KernelWidth, s.OutHeight()*s.OutWidth(), X.dtype)
+
+	if X.dtype == Float32 {
+		im2colRows(s, X.dense32(), C.dense32(), false)
+	} else {
+		im2colRows(s, X.dense64(), C.dense64(), false)
+	}
+
+	return C
+}
+
+// col2im возвращает указатель на изображение размерности channels на height*width,
+// каждый элемент которого равен сумме элементов развертки C, в которые он попадает.
+// Параметры должны быть проверены заранее.
+func col2im(s Conv2DShape, C *myMatrix) *myMatrix {
+	X := zeroOf(s.Channels, s.Height*s.Width, C.dtype)
+
+	if C.dtype == Float32 {
+		im2colRows(s, X.dense32(), C.dense32(), true)
+	} else {
+		im2colRows(s, X.dense64(), C.dense64(), true)
+	}
+
+	return X
+}
+
+// poolRows записывает в Y пулинг изображения X: наибольший элемент окна при isMax равном true
+// (индекс элемента в канале записывается в argmax, -1 если окно целиком лежит в дополнении)
+// или сумму элементов окна, деленную на площадь окна, при isMax равном false.
+// Дополнение не участвует в поиске наибольшего элемента и считается нулями при усреднении.
+func poolRows[T float](s Conv2DShape, X, Y dense[T], argmax []int, isMax bool) {
+	outH, outW := s.OutHeight(), s.OutWidth()
+	area := float64(s.KernelHeight * s.KernelWidth)
+
+	for c := 0; c < s.Channels; c++ {
+		rowX, rowY := X.row(c), Y.row(c)
+
+		for oy := 0; oy < outH; oy++ {
+			for ox := 0; ox < outW; ox++ {
+				best, bestIdx, sum := math.Inf(-1), -1, 0.
+
+				for ki := 0; ki < s.KernelHeight; ki++ {
+					iy := oy*s.StrideH - s.PadH + ki
+					if iy < 0 || iy >= s.Height {
+						continue
+					}
+
+					for kj := 0; kj < s.KernelWidth; kj++ {
+						ix := ox*s.StrideW - s.PadW + kj
+						if ix < 0 || ix >= s.Width {
+							continue
+						}
+
+						x := float64(rowX[iy*s.Width+ix])
+						sum += x
+						if x > best || bestIdx < 0 {
+							best, bestIdx = x, iy*s.Width+ix
+						}
+					}
+				}
+
+				o := oy*outW + ox
+				if isMax {
+					argmax[c*outH*outW+o] = bestIdx
+					if bestIdx < 0 {
+						best = 0
+					}
+					rowY[o] = T(best)
+				} else {
+					rowY[o] = T(sum / area)
+				}
+			}
+		}
+	}
+}
+
+// maxPool возвращает указатель на результат max пулинга изображения X и индексы наибольших элементов
+// в каналах для каждого элемента результата. Параметры должны быть проверены заранее.
+func maxPool(s Conv2DShape, X *myMatrix) (*myMatrix, []int) {
+	Y := zeroOf(s.Channels, s.OutHeight()*s.OutWidth(), X.dtype)
+	argmax := make([]int, Y.rows*Y.columns)
+
+	if X.dtype == Float32 {
+		poolRows(s, X.dense32(), Y.dense32(), argmax, true)
+	} else {
+		poolRows(s, X.dense64(), Y.dense64(), argmax, true)
+	}
+
+	return Y, argmax
+}
+
+// avgPool возвращает указатель на результат усредняющего пулинга изображения X.
+// Параметры должны быть проверены заранее.
+func avgPool(s Conv2DShape, X *myMatrix) *myMatrix {
+	Y := zeroOf(s.Channels, s.OutHeight()*s.OutWidth(), X.dtype)
+
+	if X.dtype == Float32 {
+		poolRows(s, X.dense32(), Y.dense32(), nil, false)
+	} else {
+		poolRows(s, X.dense64(), Y.dense64(), nil, false)
+	}
+
+	return Y
+}
+
+// maxPoolBackwardRows прибавляет каждый элемент dY к элементу dX с индексом из argmax.
+func maxPoolBackwardRows[T float](dX, dY dense[T], argmax []int) {
+	for c := 0; c < dY.rows; c++ {
+		rowX := dX.row(c)
+		for o, g := range dY.row(c) {
+			if idx := argmax[c*dY.columns+o]; idx >= 0 {
+				rowX[idx] += g
+			}
+		}
+	}
+}
+
+// maxPoolBackward возвращает указатель на градиент по входу max пулинга
+// по градиенту dY по выходу и индексам argmax. Параметры должны быть проверены заранее.
+func maxPoolBackward(s Conv2DShape, dY *myMatrix, argmax []int) *myMatrix {
+	dX := zeroOf(s.Channels, s.Height*s.Width, dY.dtype)
+
+	if dY.dtype == Float32 {
+		maxPoolBackwardRows(dX.dense32(), dY.dense32(), argmax)
+	} else {
+		maxPoolBackwardRows(dX.dense64(), dY.dense64(), argmax)
+	}
+
+	return dX
+}
+
+// avgPoolBackward возвращает указатель на градиент по входу усредняющего пулинга по градиенту dY по выходу:
+// каждый элемент dY, деленный на площадь окна, прибавляется ко всем элементам своего окна.
+// Параметры должны быть проверены заранее.
+func avgPoolBackward(s Conv2DShape, dY *myMatrix) *myMatrix {
+	// для каждой позиции окна градиент одинаков, поэтому используется col2im
+	C := zeroOf(s.Channels*s.KernelHeight*s.KernelWidth, dY.columns, dY.dtype)
+	k := s.KernelHeight * s.KernelWidth
+	for c := 0; c < s.Channels; c++ {
+		for p := 0; p < k; p++ {
+			axpyOp(1./float64(k), dY.view(c, 0, 1, dY.columns), C.view(c*k+p, 0, 1, dY.columns))
+		}
+	}
+
+	return col2im(s, C)
+}
+
+// checkConv2D возвращает ошибку, если параметры s некорректны, *ShapeError, если размерность
+// изображения X или фильтров K не соответствует параметрам, и *DTypeError, если различаются типы элементов.
+func checkConv2D(op string, X, K *myMatrix, s Conv2DShape) error {
+	if err := s.checkImage(op, X, s.Channels, s.Height*s.Width); err != nil {
+		return err
+	}
+	if K.columns != s.Channels*s.KernelHeight*s.KernelWidth {
+		return &ShapeError{Op: op, A: K.shape(), B: Shape{Rows: K.rows, Columns: s.Channels * s.KernelHeight * s.KernelWidth}}
+	}
+	return checkDType(op, X, K)
+}
+
+// checkConv2DBackward работает так же, как checkConv2D, но дополнительно проверяет,
+// что размерность градиента dY равна filters на OutHeight*OutWidth.
+func checkConv2DBackward(X, K, dY *myMatrix, s Conv2DShape) error {
+	if err := checkConv2D("Conv2DBackward", X, K, s); err != nil {
+		return err
+	}
+	if dY.rows != K.rows || dY.columns != s.OutHeight()*s.OutWidth() {
+		return &ShapeError{Op: "Conv2DBackward", A: dY.shape(), B: Shape{Rows: K.rows, Columns: s.OutHeight() * s.OutWidth()}}
+	}
+	return checkDType("Conv2DBackward", X, dY)
+}
+
+// checkMaxPoolBackward возвращает ошибку, если параметры s некорректны, *ShapeError,
+// если размерность dY не соответствует параметрам, и ошибку, если индексы argmax не соответствуют dY.
+func checkMaxPoolBackward(dY *myMatrix, argmax []int, s Conv2DShape) error {
+	if err := s.checkImage("MaxPool2DBackward", dY, s.Channels, s.OutHeight()*s.OutWidth()); err != nil {
+		return err
+	}
+	if len(argmax) != dY.rows*dY.columns {
+		return fmt.Errorf("incorrect number of indexes for MaxPool2DBackward: %d, expected %d", len(argmax), dY.rows*dY.columns)
+	}
+	for _, idx := range argmax {
+		if idx < -1 || idx >= s.Height*s.Width {
+			return fmt.Errorf("index out of range for MaxPool2DBackward: %d", idx)
+		}
+	}
+	return nil
+}
@@ -4,33 +4,19 @@ import (
 	"errors"
 	"fmt"
 	"math"
-	"sync"
 )
 
 // slice2Matrix преобразует слайс []float64 в матрицу (структуру myMatrix).
 // Результат сохраняется в M, изменяя ее.
 // Метод вызывает панику, если количество элементов матрицы не равно количеству элементов слайса.
 func (M *myMatrix) slice2Matrix(slc []float64) {
-	if M.getRows()*M.getColumns() != len(slc) {
-		panic("Incorrect dimension of the result myMatrix or lenght of the slice for creating a myMatrix from a slice")
-	}
-
-	wg := new(sync.WaitGroup)
-	wg.Add(M.getRows())
+	must(M.checkSlice(slc))
 
 	for i := 0; i < M.getRows(); i++ {
-
-		go func(i int) {
-			defer wg.Done()
-
-			for j := 0; j < M.getColumns(); j++ {
-				M.data[i][j] = slc[i*M.columns+j]
-			}
-		}(i)
-
+		for j := 0; j < M.getColumns(); j++ {
+			M.setIJ(i, j, slc[i*M.columns+j])
+		}
 	}
-
-	wg.Wait()
 }
 
 // num возвращает единственный элемент матрицы (структуры myMatrix) размерности 1 на 1
@@ -39,7 +25,7 @@ func (M *myMatrix) num() float64 {
 	if M.getColumns() != 1 && M.getRows() != 1 {
 		panic("Matrix dimension  must be 1*1")
 	}
-	return M.data[0][0]
+	return M.getIJ(0, 0)
 }
 
 // float64ToInt возвращает преобразованное вещественное число в целое.
@@ -67,7 +53,7 @@ func num2Vec(x float64, n int) (*myMatrix, error) {
 	}
 
 	res := zero(n, 1)
-	res.data[xI][0] = 1.
+	res.setIJ(xI, 0, 1.)
 
 	return res, nil
 
@@ -102,14 +88,6 @@ func vec2Num(M *myMatrix) int {
 		panic("Incorrect vector")
 	}
 
-	max := M.data[0][0]
-	ind := 0
-
-	for i := 1; i < M.getRows(); i++ {
-		if M.data[i][0] > max {
-			ind = i
-			max = M.data[i][0]
-		}
-	}
+	ind, _, _ := M.argBest(greater)
 	return ind
 }
@@ -1,41 +1,44 @@
 package matrix
 
-import (
-	"sync"
-)
-
 // dot возвращает указатель на структуру myMatrix.
 // Возвращаемая матрица является результатом произведения матриц A и B.
 // Метод вызывает панику, если исходные матрицы нельзя перемножить по определению.
 // Предупреждение: функция может вызвать панику, если rows или columns будут не положительными,
 // поскольку внутренне вызывается функция zero, требующая положительных значений для этих параметров.
 func (A *myMatrix) dot(B *myMatrix) *myMatrix {
-	if A.getColumns() != B.getRows() {
-		panic("Incorrect dimension for myMatrix multiplication")
-	}
+	must(checkProduct("Dot", false, false, A, B))
 
-	wg := new(sync.WaitGroup)
-	wg.Add(A.getRows() * B.getColumns())
+	C := zeroOf(A.getRows(), B.getColumns(), A.dtype)
+	gemmOp(false, false, A, B, C)
+	debugCheck("Dot", C)
 
-	C := zero(A.getRows(), B.getColumns())
+	return C
+}
 
-	for i := 0; i < A.getRows(); i++ {
-		for j := 0; j < B.getColumns(); j++ {
+// tDot возвращает указатель на структуру myMatrix.
+// Возвращаемая матрица является результатом произведения транспонированной матрицы A на матрицу B.
+// Транспонированная копия матрицы A при этом не создается.
+// Метод вызывает панику, если количество строк матриц A и B не совпадает.
+func (A *myMatrix) tDot(B *myMatrix) *myMatrix {
+	must(checkProduct("TDot", true, false, A, B))
 
-			go func(i, j int) {
-				defer wg.Done()
+	C := zeroOf(A.getColumns(), B.getColumns(), A.dtype)
+	gemmOp(true, false, A, B, C)
+	debugCheck("TDot", C)
 
-				sum := float64(0)
+	return C
+}
 
-				for k := 0; k < A.getColumns(); k++ {
-					sum += A.data[i][k] * B.data[k][j]
-				}
-				C.data[i][j] = sum
-			}(i, j)
-		}
-	}
+// dotT возвращает указатель на структуру myMatrix.
+// Возвращаемая матрица является результатом произведения матрицы A на транспонированную матрицу B.
+// Транспонированная копия матрицы B при этом не создается.
+// Метод вызывает панику, если количество столбцов матриц A и B не совпадает.
+func (A *myMatrix) dotT(B *myMatrix) *myMatrix {
+	must(checkProduct("DotT", false, true, A, B))
 
-	wg.Wait()
+	C := zeroOf(A.getRows(), B.getRows(), A.dtype)
+	gemmOp(false, true, A, B, C)
+	debugCheck("DotT", C)
 
 	return C
 }
@@ -46,26 +49,11 @@ func (A *myMatrix) dot(B *myMatrix) *myMatrix {
 // Предупреждение: функция может вызвать панику, если rows или columns будут не положительными,
 // поскольку внутренне вызывается функция zero, требующая положительных значений для этих параметров.
 func (A *myMatrix) add(B *myMatrix) *myMatrix {
-	if A.getRows() != B.getRows() || A.getColumns() != B.getColumns() {
-		panic("Incorrect dimension for myMatrix additional")
-	}
-
-	C := zero(A.getRows(), A.getColumns())
-
-	wg := new(sync.WaitGroup)
-	wg.Add(A.getRows())
-	for i := 0; i < A.getRows(); i++ {
-
-		go func(i int) {
-			defer wg.Done()
-			for j := 0; j < A.getColumns(); j++ {
-				C.data[i][j] = A.data[i][j] + B.data[i][j]
-			}
+	must(checkSameShape("Add", A, B))
 
-		}(i)
-	}
-
-	wg.Wait()
+	C := zeroOf(A.getRows(), A.getColumns(), A.dtype)
+	addOp(A, B, C)
+	debugCheck("Add", C)
 
 	return C
 }
@@ -76,27 +64,11 @@ func (A *myMatrix) add(B *myMatrix) *myMatrix {
 // Предупреждение: функция может вызвать панику, если rows или columns будут не положительными,
 // поскольку внутренне вызывается функция zero, требующая положительных значений для этих параметров.
 func (A *myMatrix) sub(B *myMatrix) *myMatrix {
-	if A.getRows() != B.getRows() || A.getColumns() != B.getColumns() {
-		panic("Incorrect dimension for myMatrix subtraction")
-	}
-
-	C := zero(A.getRows(), A.getColumns())
-
-	wg := new(sync.WaitGroup)
-	wg.Add(A.getRows())
-
-	for i := 0; i < A.getRows(); i++ {
+	must(checkSameShape("Sub", A, B))
 
-		go func(i int) {
-			defer wg.Done()
-
-			for j := 0; j < A.getColumns(); j++ {
-				C.data[i][j] = A.data[i][j] - B.data[i][j]
-			}
-		}(i)
-
-	}
-	wg.Wait()
+	C := zeroOf(A.getRows(), A.getColumns(), A.dtype)
+	subOp(A, B, C)
+	debugCheck("Sub", C)
 
 	return C
 }
@@ -109,27 +81,11 @@ func (A *myMatrix) sub(B *myMatrix) *myMatrix {
 // Результатом адамарного произведение есть матрица такой же размерности что и перемножаемые матрицы,
 // где i, j элемент результирующей матрицы равен произведению соответствующих элементов исходных матриц.
 func (A *myMatrix) hadamardProduct(B *myMatrix) *myMatrix {
-	if A.getRows() != B.getRows() || A.getColumns() != B.getColumns() {
-		panic("Incorrect dimension for myMatrix Hadamard product")
-	}
-
-	C := zero(A.getRows(), A.getColumns())
+	must(checkSameShape("HadamardProduct", A, B))
 
-	wg := new(sync.WaitGroup)
-	wg.Add(A.getRows())
-
-	for i := 0; i < A.getRows(); i++ {
-
-		go func(i int) {
-			defer wg.Done()
-
-			for j := 0; j < A.getColumns(); j++ {
-				C.data[i][j] = A.data[i][j] * B.data[i][j]
-			}
-		}(i)
-
-	}
-	wg.Wait()
+	C := zeroOf(A.getRows(), A.getColumns(), A.dtype)
+	mulOp(A, B, C)
+	debugCheck("HadamardProduct", C)
 
 	return C
 }
@@ -139,24 +95,10 @@ func (A *myMatrix) hadamardProduct(B *myMatrix) *myMatrix {
 // Предупреждение: функция может вызвать панику, если rows или columns будут не положительными,
 // поскольку внутренне вызывается функция zero, требующая положительных значений для этих параметров.
 func (M *myMatrix) t() *myMatrix {
-	myMatrix := zero(M.getColumns(), M.getRows())
-
-	wg := new(sync.WaitGroup)
-	wg.Add(M.getRows())
-
-	for i := 0; i < M.getRows(); i++ {
-
-		go func(i int) {
-			defer wg.Done()
+	myMatrix := zeroOf(M.getColumns(), M.getRows(), M.dtype)
 
-			for j := 0; j < M.getColumns(); j++ {
-				myMatrix.data[j][i] = M.data[i][j]
-			}
-		}(i)
-
-	}
-
-	wg.Wait()
+	transposeOp(M, myMatrix)
+	debugCheck("T", myMatrix)
 
 	return myMatrix
 }
@@ -166,23 +108,9 @@ func (M *myMatrix) t() *myMatrix {
 // Предупреждение: функция может вызвать панику, если rows или columns будут не положительными,
 // поскольку внутренне вызывается функция zero, требующая положительных значений для этих параметров.
 func (M *myMatrix) forEach(f func(float64) float64) *myMatrix {
-	myMatrix := zero(M.getRows(), M.getColumns())
-
-	wg := new(sync.WaitGroup)
-	wg.Add(M.getRows())
-
-	for i := 0; i < M.getRows(); i++ {
-
-		go func(i int) {
-			defer wg.Done()
-
-			for j := 0; j < M.getColumns(); j++ {
-				myMatrix.data[i][j] = f(M.data[i][j])
-			}
-		}(i)
-
-	}
-	wg.Wait()
+	myMatrix := zeroOf(M.getRows(), M.getColumns(), M.dtype)
+	applyOp(f, M, myMatrix)
+	debugCheck("ForEach", myMatrix)
 
 	return myMatrix
 }
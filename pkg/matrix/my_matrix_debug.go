@@ -0,0 +1,45 @@
+package matrix
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// debugHandler текущий обработчик режима отладки, nil если режим отключен.
+var debugHandler atomic.Pointer[func(*NonFiniteError)]
+
+// countNonFiniteRows возвращает количество элементов NaN и ±Inf матрицы A.
+func countNonFiniteRows[T float](A dense[T]) (nan, inf int) {
+	for i := 0; i < A.rows; i++ {
+		for _, x := range A.row(i) {
+			v := float64(x)
+			if math.IsNaN(v) {
+				nan++
+			} else if math.IsInf(v, 0) {
+				inf++
+			}
+		}
+	}
+	return nan, inf
+}
+
+// countNonFinite возвращает количество элементов NaN и ±Inf матрицы M.
+func (M *myMatrix) countNonFinite() (nan, inf int) {
+	if M.dtype == Float32 {
+		return countNonFiniteRows(M.dense32())
+	}
+	return countNonFiniteRows(M.dense64())
+}
+
+// debugCheck передает обработчику режима отладки *NonFiniteError, если результат M операции op
+// содержит NaN или ±Inf. При отключенном режиме отладки элементы не просматриваются.
+func debugCheck(op string, M *myMatrix) {
+	h := debugHandler.Load()
+	if h == nil {
+		return
+	}
+
+	if nan, inf := M.countNonFinite(); nan+inf > 0 {
+		(*h)(&NonFiniteError{Op: op, NaN: nan, Inf: inf, Shape: M.shape()})
+	}
+}
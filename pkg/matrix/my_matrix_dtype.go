@@ -0,0 +1,106 @@
+package matrix
+
+// dense64 возвращает представление данных матрицы (структуры myMatrix) с элементами float64
+// для внутренних ядер вычислений.
+func (M *myMatrix) dense64() dense[float64] {
+	return dense[float64]{
+		rows:    M.rows,
+		columns: M.columns,
+		stride:  M.stride,
+		data:    M.data,
+	}
+}
+
+// dense32 возвращает представление данных матрицы (структуры myMatrix) с элементами float32
+// для внутренних ядер вычислений.
+func (M *myMatrix) dense32() dense[float32] {
+	return dense[float32]{
+		rows:    M.rows,
+		columns: M.columns,
+		stride:  M.stride,
+		data:    M.data32,
+	}
+}
+
+// asType возвращает указатель на новую матрицу (структуру myMatrix) с типом элементов dtype,
+// элементы которой равны элементам матрицы M (при преобразовании в float32 округляются).
+// Возвращаемая матрица не разделяет память с M, даже если тип элементов совпадает.
+func (M *myMatrix) asType(dtype DType) *myMatrix {
+	res := zeroOf(M.getRows(), M.getColumns(), dtype)
+
+	for i := 0; i < M.getRows(); i++ {
+		for j := 0; j < M.getColumns(); j++ {
+			res.setIJ(i, j, M.getIJ(i, j))
+		}
+	}
+
+	return res
+}
+
+/*
+Операции, входящие в интерфейс Backend, передаются текущей реализации для матриц обоих типов элементов.
+Размерности и типы элементов должны быть проверены заранее.
+*/
+
+// gemmOp прибавляет к C произведение op(A)·op(B) (см. Backend.Gemm).
+func gemmOp(transA, transB bool, A, B, C *myMatrix) {
+	CurrentBackend().Gemm(transA, transB, A.raw(), B.raw(), C.raw())
+}
+
+// addOp записывает в C поэлементную сумму A и B.
+func addOp(A, B, C *myMatrix) {
+	CurrentBackend().Add(A.raw(), B.raw(), C.raw())
+}
+
+// subOp записывает в C поэлементную разность A и B.
+func subOp(A, B, C *myMatrix) {
+	CurrentBackend().Sub(A.raw(), B.raw(), C.raw())
+}
+
+// mulOp записывает в C поэлементное произведение A и B.
+func mulOp(A, B, C *myMatrix) {
+	CurrentBackend().HadamardProduct(A.raw(), B.raw(), C.raw())
+}
+
+// transposeOp записывает в C транспонированную A.
+func transposeOp(A, C *myMatrix) {
+	CurrentBackend().Transpose(A.raw(), C.raw())
+}
+
+// applyOp записывает в C результат применения функции f к каждому элементу A.
+func applyOp(f func(float64) float64, A, C *myMatrix) {
+	CurrentBackend().ForEach(f, A.raw(), C.raw())
+}
+
+// scaleOp записывает в C матрицу A, умноженную на число k.
+func scaleOp(k float64, A, C *myMatrix) {
+	CurrentBackend().Scale(k, A.raw(), C.raw())
+}
+
+// axpyOp прибавляет к C матрицу A, умноженную на число k.
+func axpyOp(k float64, A, C *myMatrix) {
+	CurrentBackend().AddScaled(k, A.raw(), C.raw())
+}
+
+/*
+Заполнение и копирование не входят в интерфейс Backend
+и выполняются встроенными ядрами для обоих типов элементов.
+*/
+
+// fillOp записывает во все элементы C число x.
+func fillOp(x float64, C *myMatrix) {
+	if C.dtype == Float32 {
+		fillRows(C.dense32(), x)
+		return
+	}
+	fillRows(C.dense64(), x)
+}
+
+// copyOp копирует элементы A в C.
+func copyOp(A, C *myMatrix) {
+	if C.dtype == Float32 {
+		copyRows(C.dense32(), A.dense32())
+		return
+	}
+	copyRows(C.dense64(), A.dense64())
+}
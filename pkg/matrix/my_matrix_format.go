@@ -0,0 +1,167 @@
+package matrix
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// formatElement возвращает строковое представление числа x для глагола verb с точностью prec
+// (-1 для кратчайшего представления, однозначно задающего число типа dtype).
+func formatElement(x float64, verb rune, prec int, dtype DType) string {
+	bitSize := 64
+	if dtype == Float32 {
+		bitSize = 32
+	}
+
+	switch verb {
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		if prec < 0 && (verb == 'e' || verb == 'E' || verb == 'f' || verb == 'F') {
+			prec = 6
+		}
+		return strconv.FormatFloat(x, byte(verb), prec, bitSize)
+	default:
+		return strconv.FormatFloat(x, 'g', prec, bitSize)
+	}
+}
+
+// shownIndexes возвращает индексы строк или столбцов из n, которые выводятся при сокращении
+// до edge первых и edge последних, и позицию, после которой выводится многоточие (-1 без сокращения).
+func shownIndexes(n, edge int, truncate bool) ([]int, int) {
+	if !truncate || n <= 2*edge {
+		idx := make([]int, n)
+		for i := range idx {
+			idx[i] = i
+		}
+		return idx, -1
+	}
+
+	idx := make([]int, 0, 2*edge)
+	for i := 0; i < edge; i++ {
+		idx = append(idx, i)
+	}
+	for i := n - edge; i < n; i++ {
+		idx = append(idx, i)
+	}
+	return idx, edge
+}
+
+// format возвращает представление матрицы в стиле NumPy: строки в квадратных скобках,
+// элементы выровнены по ширине самого длинного из них.
+// Если truncate равен true и количество элементов больше opts.Threshold, то выводятся только
+// opts.EdgeItems первых и последних строк и столбцов, а пропущенные заменяются многоточием.
+func (M *myMatrix) format(verb rune, prec int, truncate bool, opts PrintOptions) string {
+	truncate = truncate && M.rows*M.columns > opts.Threshold
+	rows, rowGap := shownIndexes(M.rows, opts.EdgeItems, truncate)
+	columns, colGap := shownIndexes(M.columns, opts.EdgeItems, truncate)
+
+	// элементы форматируются заранее, чтобы найти ширину столбца
+	cells := make([][]string, len(rows))
+	width := 0
+	for r, i := range rows {
+		cells[r] = make([]string, len(columns))
+		for c, j := range columns {
+			cells[r][c] = formatElement(M.getIJ(i, j), verb, prec, M.dtype)
+			if len(cells[r][c]) > width {
+				width = len(cells[r][c])
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteByte('[')
+	for r := range rows {
+		if r == rowGap {
+			b.WriteString("\n ...")
+		}
+		if r > 0 {
+			b.WriteString("\n ")
+		}
+
+		b.WriteByte('[')
+		for c := range columns {
+			if c == colGap {
+				b.WriteString(" ...")
+			}
+			if c > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteString(strings.Repeat(" ", width-len(cells[r][c])))
+			b.WriteString(cells[r][c])
+		}
+		b.WriteByte(']')
+	}
+	b.WriteByte(']')
+
+	return b.String()
+}
+
+// jsonMatrix представление матрицы в формате JSON: размерность [rows, columns], тип элементов
+// и элементы в порядке строк.
+type jsonMatrix struct {
+	Shape []int           `json:"shape"`
+	DType string          `json:"dtype"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// marshalJSON возвращает представление матрицы M в формате JSON.
+// Функция возвращает ошибку, если матрица содержит NaN или ±Inf, которые не представимы в JSON.
+func (M *myMatrix) marshalJSON() ([]byte, error) {
+	var data []byte
+	var err error
+	if M.dtype == Float32 {
+		data, err = json.Marshal(M.copy().data32)
+	} else {
+		data, err = json.Marshal(M.copy().data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("marshal matrix to JSON: %v", err)
+	}
+
+	return json.Marshal(jsonMatrix{
+		Shape: []int{M.rows, M.columns},
+		DType: M.dtype.String(),
+		Data:  data,
+	})
+}
+
+// unmarshalJSON возвращает указатель на матрицу, прочитанную из представления в формате JSON (см. marshalJSON),
+// и ошибку, если данные некорректны или количество элементов не соответствует размерности.
+// Если тип элементов не указан, используется float64.
+func unmarshalJSON(b []byte) (*myMatrix, error) {
+	var m jsonMatrix
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+
+	if len(m.Shape) != 2 {
+		return nil, fmt.Errorf("incorrect matrix shape %v in JSON", m.Shape)
+	}
+	if err := checkDims("UnmarshalJSON", m.Shape[0], m.Shape[1]); err != nil {
+		return nil, err
+	}
+
+	dtype := Float64
+	if m.DType != "" {
+		var err error
+		if dtype, err = ParseDType(m.DType); err != nil {
+			return nil, err
+		}
+	}
+
+	var data []float64
+	if err := json.Unmarshal(m.Data, &data); err != nil {
+		return nil, fmt.Errorf("incorrect matrix data in JSON: %v", err)
+	}
+	if len(data) != m.Shape[0]*m.Shape[1] {
+		return nil, fmt.Errorf("matrix data length %d does not match shape %d*%d", len(data), m.Shape[0], m.Shape[1])
+	}
+
+	M := zeroOf(m.Shape[0], m.Shape[1], dtype)
+	for k, x := range data {
+		M.setIJ(k/M.columns, k%M.columns, x)
+	}
+
+	return M, nil
+}
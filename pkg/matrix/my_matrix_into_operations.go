@@ -0,0 +1,216 @@
+package matrix
+
+import "fmt"
+
+// файл содержит операции, которые записывают результат в заранее созданную матрицу dst
+// и поэтому не выделяют память
+
+// checkDst возвращает *ShapeError, если размерность матрицы dst не равна rows на columns,
+// и *DTypeError, если тип элементов dst не равен dtype.
+func checkDst(op string, dst *myMatrix, rows, columns int, dtype DType) error {
+	if dst.rows != rows || dst.columns != columns {
+		return &ShapeError{Op: op, A: dst.shape(), B: Shape{Rows: rows, Columns: columns}}
+	}
+
+	if dst.dtype != dtype {
+		return &DTypeError{Op: op, A: dst.dtype, B: dtype}
+	}
+	return nil
+}
+
+// checkAlias возвращает ошибку, если матрица dst разделяет память с операндом A.
+// Используется операциями, которые читают операнд после начала записи результата.
+func checkAlias(op string, dst, A *myMatrix) error {
+	if sharesData(dst, A) {
+		return fmt.Errorf("destination of %s shares memory with an operand", op)
+	}
+	return nil
+}
+
+// checkOverlap возвращает ошибку, если матрица dst частично перекрывает операнд A.
+// Используется поэлементными операциями: dst может совпадать с операндом только целиком,
+// то есть иметь то же начало, шаг строк и размерность, иначе запись результата портит еще не прочитанные элементы.
+func checkOverlap(op string, dst, A *myMatrix) error {
+	if sameView(dst, A) || !overlaps(dst, A) {
+		return nil
+	}
+	return checkAlias(op, dst, A)
+}
+
+// sameView возвращает true, если матрицы A и B являются одним и тем же представлением одних данных.
+func sameView(A, B *myMatrix) bool {
+	if !sharesData(A, B) || A.rows != B.rows || A.columns != B.columns {
+		return false
+	}
+	start, _ := dataSpan(A)
+	startB, _ := dataSpan(B)
+	return start == startB && (A.stride == B.stride || A.rows == 1)
+}
+
+// overlaps возвращает true, если области памяти, занимаемые элементами матриц A и B, пересекаются.
+// Область матрицы считается от ее первого до последнего элемента, включая промежутки между строками.
+func overlaps(A, B *myMatrix) bool {
+	if !sharesData(A, B) {
+		return false
+	}
+	startA, endA := dataSpan(A)
+	startB, endB := dataSpan(B)
+	return startA < endB && startB < endA
+}
+
+// dataSpan возвращает начало и конец области памяти элементов матрицы M,
+// отсчитанные от конца общего массива (поэтому начало отрицательно).
+// Сравнивать имеет смысл только области матриц, разделяющих память (см. sharesData).
+func dataSpan(M *myMatrix) (int, int) {
+	start := -cap(M.data)
+	if M.dtype == Float32 {
+		start = -cap(M.data32)
+	}
+	return start, start + (M.rows-1)*M.stride + M.columns
+}
+
+// sharesData возвращает true, если данные матриц A и B лежат в одном массиве.
+// Слайсы одного массива имеют общий последний элемент по емкости.
+func sharesData(A, B *myMatrix) bool {
+	if A.dtype != B.dtype {
+		return false
+	}
+
+	if A.dtype == Float32 {
+		a, b := A.data32[:cap(A.data32)], B.data32[:cap(B.data32)]
+		return &a[len(a)-1] == &b[len(b)-1]
+	}
+
+	a, b := A.data[:cap(A.data)], B.data[:cap(B.data)]
+	return &a[len(a)-1] == &b[len(b)-1]
+}
+
+// productInto записывает в dst произведение op(A)·op(B), где op транспонирует матрицу
+// при transA (transB) равном true.
+// Метод вызывает панику, если матрицы нельзя перемножить, размерность dst не равна размерности произведения
+// или dst разделяет память с A или B.
+func productInto(op string, transA, transB bool, dst, A, B *myMatrix) {
+	must(checkProduct(op, transA, transB, A, B))
+
+	rows, columns := A.rows, B.columns
+	if transA {
+		rows = A.columns
+	}
+	if transB {
+		columns = B.rows
+	}
+
+	must(checkDst(op, dst, rows, columns, A.dtype))
+	must(checkAlias(op, dst, A))
+	must(checkAlias(op, dst, B))
+
+	// произведение прибавляется к dst, поэтому dst предварительно обнуляется
+	fillOp(0., dst)
+	gemmOp(transA, transB, A, B, dst)
+	debugCheck(op, dst)
+}
+
+// addInto записывает в dst поэлементную сумму матриц A и B.
+// dst может совпадать с A или B, но не должна частично перекрывать их.
+// Метод вызывает панику, если размерности матриц не равны или dst частично перекрывает операнд.
+func addInto(dst, A, B *myMatrix) {
+	must(checkSameShape("AddInto", A, B))
+	must(checkDst("AddInto", dst, A.rows, A.columns, A.dtype))
+	must(checkOverlap("AddInto", dst, A))
+	must(checkOverlap("AddInto", dst, B))
+
+	addOp(A, B, dst)
+	debugCheck("AddInto", dst)
+}
+
+// subInto записывает в dst поэлементную разность матриц A и B.
+// dst может совпадать с A или B, но не должна частично перекрывать их.
+// Метод вызывает панику, если размерности матриц не равны или dst частично перекрывает операнд.
+func subInto(dst, A, B *myMatrix) {
+	must(checkSameShape("SubInto", A, B))
+	must(checkDst("SubInto", dst, A.rows, A.columns, A.dtype))
+	must(checkOverlap("SubInto", dst, A))
+	must(checkOverlap("SubInto", dst, B))
+
+	subOp(A, B, dst)
+	debugCheck("SubInto", dst)
+}
+
+// hadamardProductInto записывает в dst адамарное произведение матриц A и B.
+// dst может совпадать с A или B, но не должна частично перекрывать их.
+// Метод вызывает панику, если размерности матриц не равны или dst частично перекрывает операнд.
+func hadamardProductInto(dst, A, B *myMatrix) {
+	must(checkSameShape("HadamardProductInto", A, B))
+	must(checkDst("HadamardProductInto", dst, A.rows, A.columns, A.dtype))
+	must(checkOverlap("HadamardProductInto", dst, A))
+	must(checkOverlap("HadamardProductInto", dst, B))
+
+	mulOp(A, B, dst)
+	debugCheck("HadamardProductInto", dst)
+}
+
+// forEachInto записывает в dst результат применения функции f к каждому элементу матрицы M.
+// dst может совпадать с M, но не должна частично перекрывать ее.
+// Метод вызывает панику, если размерности dst и M не равны или dst частично перекрывает M.
+func forEachInto(dst, M *myMatrix, f func(float64) float64) {
+	must(checkDst("ForEachInto", dst, M.rows, M.columns, M.dtype))
+	must(checkOverlap("ForEachInto", dst, M))
+
+	applyOp(f, M, dst)
+	debugCheck("ForEachInto", dst)
+}
+
+// tInto записывает в dst транспонированную матрицу M.
+// Метод вызывает панику, если размерность dst не равна транспонированной размерности M
+// или dst разделяет память с M.
+func tInto(dst, M *myMatrix) {
+	must(checkDst("TInto", dst, M.columns, M.rows, M.dtype))
+	must(checkAlias("TInto", dst, M))
+
+	transposeOp(M, dst)
+	debugCheck("TInto", dst)
+}
+
+// scaleInPlace умножает каждый элемент матрицы M на число k, изменяя ее.
+func (M *myMatrix) scaleInPlace(k float64) {
+	scaleOp(k, M, M)
+	debugCheck("ScaleInPlace", M)
+}
+
+// addScaledInPlace прибавляет к матрице A матрицу B, умноженную на число k, изменяя A.
+// Метод вызывает панику, если размерности матриц не равны.
+func (A *myMatrix) addScaledInPlace(k float64, B *myMatrix) {
+	must(checkSameShape("AddScaledInPlace", A, B))
+	must(checkOverlap("AddScaledInPlace", A, B))
+
+	axpyOp(k, B, A)
+	debugCheck("AddScaledInPlace", A)
+}
+
+// fill записывает во все элементы матрицы M число x, изменяя ее.
+func (M *myMatrix) fill(x float64) {
+	fillOp(x, M)
+}
+
+// dotSparseInto записывает в dst произведение матрицы A на разреженную матрицу S
+// (на транспонированную S при trans равном true).
+// Метод вызывает панику, если матрицы нельзя перемножить или размерность dst не равна размерности произведения.
+func dotSparseInto(dst, A *myMatrix, S *mySparse, trans bool) {
+	must(A.checkDotSparse(S, trans))
+
+	op, columns := "DotSparseInto", S.columns
+	if trans {
+		op, columns = "DotTSparseInto", S.rows
+	}
+
+	must(checkDst(op, dst, A.rows, columns, A.dtype))
+	must(checkAlias(op, dst, A))
+
+	fillOp(0., dst)
+	if A.dtype == Float32 {
+		denseSparse(A.dense32(), S, dst.dense32(), trans)
+	} else {
+		denseSparse(A.dense64(), S, dst.dense64(), trans)
+	}
+	debugCheck(op, dst)
+}
@@ -12,7 +12,8 @@ import (
 // если запись не удалась функция возвращает ошибку.
 // Функция форматирует данные каждой матрицы следующим образом:
 // сначала записывается общее количество матриц в переданном слайсе,
-// с новой строки для каждой матрицы записывается ее размерность (количество строк и столбцов),
+// с новой строки для каждой матрицы записывается ее размерность (количество строк и столбцов)
+// и, если элементы матрицы не float64, через пробел тип элементов (например float32),
 // с новой строки данные самой матрицы.
 // Элементы каждой строки матрицы разделяются пробелом, а строки матрицы - переводами строк.
 // Функция не открывает и не закрывает поток вывода, управление потоком
@@ -25,8 +26,14 @@ func writeMatrixes(writer io.Writer, matrixes []*myMatrix) error {
 	}
 
 	for _, matrix := range matrixes {
-		// записываем размерность матрицы
-		_, err := fmt.Fprintf(writer, "%d %d\n", matrix.rows, matrix.columns)
+		// записываем размерность матрицы,
+		// тип элементов записывается только если он отличается от float64
+		var err error
+		if matrix.dtype == Float64 {
+			_, err = fmt.Fprintf(writer, "%d %d\n", matrix.rows, matrix.columns)
+		} else {
+			_, err = fmt.Fprintf(writer, "%d %d %v\n", matrix.rows, matrix.columns, matrix.dtype)
+		}
 		if err != nil {
 			return err
 		}
@@ -34,7 +41,7 @@ func writeMatrixes(writer io.Writer, matrixes []*myMatrix) error {
 		// записываем элементы матрицы
 		for r := 0; r < matrix.rows; r++ {
 			for c := 0; c < matrix.columns; c++ {
-				_, err := fmt.Fprintf(writer, "%f ", matrix.data[r][c])
+				_, err := fmt.Fprintf(writer, "%f ", matrix.getIJ(r, c))
 				if err != nil {
 					return err
 				}
@@ -56,7 +63,8 @@ func writeMatrixes(writer io.Writer, matrixes []*myMatrix) error {
 // Функция читает данные, начиная с текущей позиции в потоке сканера.
 // Каждый блок матрицы в файле должен быть отформатирован следующим образом:
 // сначала идет число - общее количество матриц,
-// затем с новой строки для каждой матрицы должна быть ее размерность (количество строк и столбцов),
+// затем с новой строки для каждой матрицы должна быть ее размерность (количество строк и столбцов)
+// и необязательный тип элементов (по умолчанию float64),
 // с новой строки данные самой матрицы.
 // Элементы каждой строки матрицы разделяются пробелом, а строки матрицы - переводами строк.
 // Функция принимает уже инициализированный сканер структуры *bufio.Scanner.
@@ -80,10 +88,19 @@ func readMatrixes(scanner *bufio.Scanner) ([]*myMatrix, error) {
 				return nil, fmt.Errorf("expected matrix dimensions, found EOF")
 			}
 			dimension := strings.Fields(scanner.Text())
-			if len(dimension) != 2 {
+			if len(dimension) != 2 && len(dimension) != 3 {
 				return nil, fmt.Errorf("incorrect matrix dimension")
 			}
 
+			// тип элементов указывается третьим необязательным полем
+			dtype := Float64
+			if len(dimension) == 3 {
+				dtype, err = ParseDType(dimension[2])
+				if err != nil {
+					return nil, err
+				}
+			}
+
 			// количество строк
 			rows, err := strconv.Atoi(dimension[0])
 			if err != nil {
@@ -96,8 +113,12 @@ func readMatrixes(scanner *bufio.Scanner) ([]*myMatrix, error) {
 				return nil, err
 			}
 
-			// возвращаемый слайс
-			matrix := make([][]float64, rows)
+			if rows <= 0 || columns <= 0 {
+				return nil, fmt.Errorf("incorrect matrix dimension")
+			}
+
+			// возвращаемая матрица
+			matrix := zeroOf(rows, columns, dtype)
 
 			for r := 0; r < rows; r++ {
 
@@ -111,7 +132,6 @@ func readMatrixes(scanner *bufio.Scanner) ([]*myMatrix, error) {
 					return nil, fmt.Errorf("incorrect number of columns in matrix")
 				}
 
-				matrix[r] = make([]float64, columns)
 				for c := 0; c < columns; c++ {
 
 					// преобразуем в вещественное число
@@ -120,15 +140,11 @@ func readMatrixes(scanner *bufio.Scanner) ([]*myMatrix, error) {
 						return nil, err
 					}
 
-					matrix[r][c] = num
+					matrix.setIJ(r, c, num)
 				}
 			}
 
-			matrixes = append(matrixes, &myMatrix{
-				rows:    rows,
-				columns: columns,
-				data:    matrix,
-			})
+			matrixes = append(matrixes, matrix)
 		}
 	} else if err := scanner.Err(); err != nil {
 		return nil, err
@@ -0,0 +1,496 @@
+package matrix
+
+import "math"
+
+// файл содержит разложения матриц и решение систем линейных уравнений.
+// Вычисления всегда производятся над копией матрицы с элементами float64.
+
+// singularTol возвращает порог, ниже которого диагональный элемент треугольного множителя
+// матрицы A размерности n считается нулевым: n * машинный эпсилон * наибольший по модулю элемент A.
+func singularTol(n int, A dense[float64]) float64 {
+	maxAbs := 0.
+	for i := 0; i < A.rows; i++ {
+		for _, x := range A.row(i) {
+			maxAbs = math.Max(maxAbs, math.Abs(x))
+		}
+	}
+	return float64(n) * 0x1p-52 * maxAbs
+}
+
+// identity возвращает указатель на единичную матрицу размерности n на n с типом элементов dtype.
+func identity(n int, dtype DType) *myMatrix {
+	I := zeroOf(n, n, dtype)
+	for i := 0; i < n; i++ {
+		I.setIJ(i, i, 1.)
+	}
+	return I
+}
+
+// myLU хранит LU разложение с выбором ведущего элемента по столбцу: PA = LU.
+// Под диагональю lu хранятся элементы L (диагональ L единичная), на и над диагональю элементы U.
+type myLU struct {
+	lu       dense[float64] // L и U в одной матрице
+	piv      []int          // i строка PA является piv[i] строкой A
+	sign     float64        // Знак перестановки P
+	singular bool           // Есть ли на диагонали U элемент меньше порога singularTol
+	dtype    DType          // Тип элементов исходной матрицы
+}
+
+// luDecompose возвращает LU разложение квадратной матрицы A.
+// Размерность должна быть проверена заранее.
+func luDecompose(A *myMatrix) *myLU {
+	a := A.asType(Float64).dense64()
+	n := a.rows
+
+	f := &myLU{
+		lu:    a,
+		piv:   make([]int, n),
+		sign:  1.,
+		dtype: A.dtype,
+	}
+	for i := range f.piv {
+		f.piv[i] = i
+	}
+
+	tol := singularTol(n, a)
+
+	for k := 0; k < n; k++ {
+		// ведущий элемент наибольший по модулю в k столбце
+		p := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(a.data[i*a.stride+k]) > math.Abs(a.data[p*a.stride+k]) {
+				p = i
+			}
+		}
+
+		if p != k {
+			rowP, rowK := a.row(p), a.row(k)
+			for j := range rowK {
+				rowP[j], rowK[j] = rowK[j], rowP[j]
+			}
+			f.piv[p], f.piv[k] = f.piv[k], f.piv[p]
+			f.sign = -f.sign
+		}
+
+		pivot := a.data[k*a.stride+k]
+		if math.Abs(pivot) <= tol {
+			f.singular = true
+			if pivot == 0 {
+				continue
+			}
+		}
+
+		rowK := a.row(k)
+		for i := k + 1; i < n; i++ {
+			rowI := a.row(i)
+			rowI[k] /= pivot
+
+			l := rowI[k]
+			if l == 0 {
+				continue
+			}
+			for j := k + 1; j < n; j++ {
+				rowI[j] -= l * rowK[j]
+			}
+		}
+	}
+
+	return f
+}
+
+// det возвращает определитель исходной матрицы.
+func (f *myLU) det() float64 {
+	d := f.sign
+	for i := 0; i < f.lu.rows; i++ {
+		d *= f.lu.data[i*f.lu.stride+i]
+	}
+	return d
+}
+
+// solve возвращает решение X системы AX = B с элементами float64.
+// Матрица не должна быть вырожденной, размерности должны быть проверены заранее.
+func (f *myLU) solve(B *myMatrix) *myMatrix {
+	b := B.asType(Float64)
+	X := zeroOf(b.rows, b.columns, Float64)
+	x, lu := X.dense64(), f.lu
+	n := lu.rows
+
+	// X = PB
+	for i := 0; i < n; i++ {
+		copy(x.row(i), b.dense64().row(f.piv[i]))
+	}
+
+	// LY = PB, L с единичной диагональю
+	for k := 0; k < n; k++ {
+		rowK := x.row(k)
+		for i := k + 1; i < n; i++ {
+			l := lu.data[i*lu.stride+k]
+			rowI := x.row(i)
+			for j := range rowI {
+				rowI[j] -= l * rowK[j]
+			}
+		}
+	}
+
+	// UX = Y
+	for k := n - 1; k >= 0; k-- {
+		rowK := x.row(k)
+		u := lu.data[k*lu.stride+k]
+		for j := range rowK {
+			rowK[j] /= u
+		}
+
+		for i := 0; i < k; i++ {
+			l := lu.data[i*lu.stride+k]
+			rowI := x.row(i)
+			for j := range rowI {
+				rowI[j] -= l * rowK[j]
+			}
+		}
+	}
+
+	return X
+}
+
+// solveVec решает систему Ax = b (при trans равном true систему A^T x = b) для вектора b, изменяя его.
+// Используется оценкой числа обусловленности.
+func (f *myLU) solveVec(b []float64, trans bool) {
+	lu, n := f.lu, f.lu.rows
+	at := func(i, j int) float64 {
+		return lu.data[i*lu.stride+j]
+	}
+
+	if !trans {
+		x := make([]float64, n)
+		for i := range x {
+			x[i] = b[f.piv[i]]
+		}
+		for i := 0; i < n; i++ {
+			for k := 0; k < i; k++ {
+				x[i] -= at(i, k) * x[k]
+			}
+		}
+		for i := n - 1; i >= 0; i-- {
+			for k := i + 1; k < n; k++ {
+				x[i] -= at(i, k) * x[k]
+			}
+			x[i] /= at(i, i)
+		}
+		copy(b, x)
+		return
+	}
+
+	// A^T = U^T L^T P: U^T w = b, L^T u = w, x[piv[i]] = u[i]
+	w := make([]float64, n)
+	copy(w, b)
+	for i := 0; i < n; i++ {
+		for k := 0; k < i; k++ {
+			w[i] -= at(k, i) * w[k]
+		}
+		w[i] /= at(i, i)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for k := i + 1; k < n; k++ {
+			w[i] -= at(k, i) * w[k]
+		}
+	}
+	for i := 0; i < n; i++ {
+		b[f.piv[i]] = w[i]
+	}
+}
+
+// invNorm1 возвращает оценку 1-нормы обратной матрицы методом Хагера (Hager, 1984),
+// которому требуется несколько решений систем с матрицей и транспонированной матрицей
+// вместо явного обращения.
+func (f *myLU) invNorm1() float64 {
+	n := f.lu.rows
+
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = 1. / float64(n)
+	}
+
+	est := 0.
+	y := make([]float64, n)
+	z := make([]float64, n)
+
+	for iter := 0; iter < 5; iter++ {
+		copy(y, x)
+		f.solveVec(y, false)
+
+		est = 0.
+		for i, v := range y {
+			est += math.Abs(v)
+			if v >= 0 {
+				z[i] = 1.
+			} else {
+				z[i] = -1.
+			}
+		}
+
+		f.solveVec(z, true)
+
+		// x является точкой максимума, если ни одно направление e_j не увеличивает оценку
+		jMax, zx := 0, 0.
+		for j := range z {
+			zx += z[j] * x[j]
+			if math.Abs(z[j]) > math.Abs(z[jMax]) {
+				jMax = j
+			}
+		}
+		if math.Abs(z[jMax]) <= zx {
+			break
+		}
+
+		for j := range x {
+			x[j] = 0.
+		}
+		x[jMax] = 1.
+	}
+
+	return est
+}
+
+// norm1 возвращает 1-норму матрицы A: наибольшую сумму модулей элементов столбца.
+func norm1(A *myMatrix) float64 {
+	sums := make([]float64, A.columns)
+	A.each(func(_, j int, x float64) {
+		sums[j] += math.Abs(x)
+	})
+
+	res := 0.
+	for _, s := range sums {
+		res = math.Max(res, s)
+	}
+	return res
+}
+
+// myQR хранит QR разложение матрицы отражениями Хаусхолдера: A = QR.
+// Под и на диагонали qr хранятся векторы отражений, над диагональю элементы R,
+// диагональ R хранится в rdiag.
+type myQR struct {
+	qr    dense[float64] // Векторы отражений и R
+	rdiag []float64      // Диагональ R
+	tol   float64        // Порог, ниже которого диагональный элемент R считается нулевым
+	dtype DType          // Тип элементов исходной матрицы
+}
+
+// qrDecompose возвращает QR разложение матрицы A с количеством строк не меньше количества столбцов.
+// Размерность должна быть проверена заранее.
+func qrDecompose(A *myMatrix) *myQR {
+	a := A.asType(Float64).dense64()
+	m, n := a.rows, a.columns
+
+	f := &myQR{
+		qr:    a,
+		rdiag: make([]float64, n),
+		tol:   singularTol(m, a),
+		dtype: A.dtype,
+	}
+
+	for k := 0; k < n; k++ {
+		// норма k столбца под диагональю
+		nrm := 0.
+		for i := k; i < m; i++ {
+			nrm = math.Hypot(nrm, a.data[i*a.stride+k])
+		}
+
+		if nrm != 0 {
+			// отражение переводит столбец в -nrm * e_k
+			if a.data[k*a.stride+k] < 0 {
+				nrm = -nrm
+			}
+			for i := k; i < m; i++ {
+				a.data[i*a.stride+k] /= nrm
+			}
+			a.data[k*a.stride+k] += 1.
+
+			// применение отражения к остальным столбцам
+			for j := k + 1; j < n; j++ {
+				s := 0.
+				for i := k; i < m; i++ {
+					s += a.data[i*a.stride+k] * a.data[i*a.stride+j]
+				}
+				s = -s / a.data[k*a.stride+k]
+				for i := k; i < m; i++ {
+					a.data[i*a.stride+j] += s * a.data[i*a.stride+k]
+				}
+			}
+		}
+
+		f.rdiag[k] = -nrm
+	}
+
+	return f
+}
+
+// fullRank возвращает true, если все диагональные элементы R больше порога.
+func (f *myQR) fullRank() bool {
+	for _, d := range f.rdiag {
+		if math.Abs(d) <= f.tol {
+			return false
+		}
+	}
+	return true
+}
+
+// q возвращает указатель на матрицу Q размерности m на n с ортонормированными столбцами.
+func (f *myQR) q() *myMatrix {
+	m, n := f.qr.rows, f.qr.columns
+	Q := zeroOf(m, n, Float64)
+	q, a := Q.dense64(), f.qr
+
+	for k := n - 1; k >= 0; k-- {
+		q.data[k*q.stride+k] = 1.
+		for j := k; j < n; j++ {
+			if a.data[k*a.stride+k] == 0 {
+				continue
+			}
+
+			s := 0.
+			for i := k; i < m; i++ {
+				s += a.data[i*a.stride+k] * q.data[i*q.stride+j]
+			}
+			s = -s / a.data[k*a.stride+k]
+			for i := k; i < m; i++ {
+				q.data[i*q.stride+j] += s * a.data[i*a.stride+k]
+			}
+		}
+	}
+
+	return Q.asType(f.dtype)
+}
+
+// r возвращает указатель на верхнетреугольную матрицу R размерности n на n.
+func (f *myQR) r() *myMatrix {
+	n := f.qr.columns
+	R := zeroOf(n, n, f.dtype)
+
+	for i := 0; i < n; i++ {
+		R.setIJ(i, i, f.rdiag[i])
+		for j := i + 1; j < n; j++ {
+			R.setIJ(i, j, f.qr.data[i*f.qr.stride+j])
+		}
+	}
+
+	return R
+}
+
+// solve возвращает решение X задачи наименьших квадратов min ||AX - B|| с элементами float64.
+// Матрица должна иметь полный ранг, размерности должны быть проверены заранее.
+func (f *myQR) solve(B *myMatrix) *myMatrix {
+	m, n := f.qr.rows, f.qr.columns
+	Y := B.asType(Float64)
+	y, a := Y.dense64(), f.qr
+
+	// Y = Q^T B
+	for k := 0; k < n; k++ {
+		for j := 0; j < y.columns; j++ {
+			s := 0.
+			for i := k; i < m; i++ {
+				s += a.data[i*a.stride+k] * y.data[i*y.stride+j]
+			}
+			s = -s / a.data[k*a.stride+k]
+			for i := k; i < m; i++ {
+				y.data[i*y.stride+j] += s * a.data[i*a.stride+k]
+			}
+		}
+	}
+
+	// RX = Y
+	X := zeroOf(n, y.columns, Float64)
+	x := X.dense64()
+	for k := n - 1; k >= 0; k-- {
+		for j := 0; j < x.columns; j++ {
+			s := y.data[k*y.stride+j]
+			for i := k + 1; i < n; i++ {
+				s -= a.data[k*a.stride+i] * x.data[i*x.stride+j]
+			}
+			x.data[k*x.stride+j] = s / f.rdiag[k]
+		}
+	}
+
+	return X
+}
+
+// myCholesky хранит разложение Холецкого симметричной положительно определенной матрицы: A = LL^T.
+type myCholesky struct {
+	l     dense[float64] // Нижнетреугольный множитель L
+	dtype DType          // Тип элементов исходной матрицы
+}
+
+// choleskyDecompose возвращает разложение Холецкого квадратной матрицы A
+// и false, если матрица не является положительно определенной.
+// Используются только элементы на и под диагональю A.
+// Размерность должна быть проверена заранее.
+func choleskyDecompose(A *myMatrix) (*myCholesky, bool) {
+	a := A.asType(Float64).dense64()
+	n := a.rows
+
+	L := zeroOf(n, n, Float64)
+	l := L.dense64()
+
+	for j := 0; j < n; j++ {
+		rowJ := l.row(j)
+
+		d := 0.
+		for k := 0; k < j; k++ {
+			rowK := l.row(k)
+
+			s := a.data[j*a.stride+k]
+			for i := 0; i < k; i++ {
+				s -= rowK[i] * rowJ[i]
+			}
+			s /= rowK[k]
+
+			rowJ[k] = s
+			d += s * s
+		}
+
+		d = a.data[j*a.stride+j] - d
+		if d <= 0 || math.IsNaN(d) {
+			return nil, false
+		}
+		rowJ[j] = math.Sqrt(d)
+	}
+
+	return &myCholesky{l: l, dtype: A.dtype}, true
+}
+
+// solve возвращает решение X системы AX = B с элементами float64.
+// Размерности должны быть проверены заранее.
+func (f *myCholesky) solve(B *myMatrix) *myMatrix {
+	X := B.asType(Float64)
+	x, l := X.dense64(), f.l
+	n := l.rows
+
+	// LY = B
+	for k := 0; k < n; k++ {
+		rowK := x.row(k)
+		for i := 0; i < k; i++ {
+			li, rowI := l.data[k*l.stride+i], x.row(i)
+			for j := range rowK {
+				rowK[j] -= li * rowI[j]
+			}
+		}
+		for j := range rowK {
+			rowK[j] /= l.data[k*l.stride+k]
+		}
+	}
+
+	// L^T X = Y
+	for k := n - 1; k >= 0; k-- {
+		rowK := x.row(k)
+		for i := k + 1; i < n; i++ {
+			li, rowI := l.data[i*l.stride+k], x.row(i)
+			for j := range rowK {
+				rowK[j] -= li * rowI[j]
+			}
+		}
+		for j := range rowK {
+			rowK[j] /= l.data[k*l.stride+k]
+		}
+	}
+
+	return X
+}
@@ -0,0 +1,312 @@
+package matrix
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// файл содержит чтение и запись матриц в формате Matrix Market
+// (см. https://math.nist.gov/MatrixMarket/formats.html).
+
+// mmFormat имя формата Matrix Market в ошибках чтения.
+const mmFormat = "matrix market"
+
+// mmHeader первое слово заголовка файла Matrix Market.
+const mmHeader = "%%MatrixMarket"
+
+// mmMaxLine наибольшая длина строки текстовых файлов данных.
+const mmMaxLine = 64 << 20
+
+// mmMaxDenseElements наибольшее количество элементов плотной матрицы, читаемой из файла Matrix Market.
+// Размерность проверяется до выделения памяти, поэтому некорректная строка размерности приводит к ошибке.
+const mmMaxDenseElements = math.MaxInt32
+
+// mmData элементы матрицы, прочитанные из файла Matrix Market, в виде троек (индексы начиная с 0).
+type mmData struct {
+	rows, columns  int
+	rowIdx, colIdx []int
+	values         []float64
+}
+
+// lineScanner сканер строк текстового файла данных, считающий номер текущей строки для ошибок чтения.
+type lineScanner struct {
+	*bufio.Scanner
+	format string
+	line   int
+}
+
+// newLineScanner возвращает сканер строк reader для формата format.
+func newLineScanner(reader io.Reader, format string) *lineScanner {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, mmMaxLine)
+	return &lineScanner{Scanner: scanner, format: format}
+}
+
+// next переходит к следующей строке, пропуская пустые строки и строки, начинающиеся с comment,
+// и возвращает ее поля. Функция возвращает nil и ошибку чтения, если строки закончились.
+func (s *lineScanner) next(comment string) ([]string, error) {
+	for s.Scan() {
+		s.line++
+		text := strings.TrimSpace(s.Text())
+		if text == "" || strings.HasPrefix(text, comment) {
+			continue
+		}
+		return strings.Fields(text), nil
+	}
+	return nil, s.Err()
+}
+
+// errorf возвращает *ParseError текущей строки с описанием по формату.
+func (s *lineScanner) errorf(format string, args ...any) error {
+	return &ParseError{Format: s.format, Line: s.line, Err: fmt.Errorf(format, args...)}
+}
+
+// readMatrixMarket читает из reader матрицу в формате Matrix Market:
+// coordinate или array, с элементами real, integer или pattern (для coordinate),
+// с симметрией general, symmetric или skew-symmetric.
+// Для симметричных матриц в файле хранится нижний треугольник, он отражается на верхний.
+// Если dense равен true, то количество элементов матрицы должно быть не больше mmMaxDenseElements,
+// иначе количество элементов только не должно переполнять int.
+func readMatrixMarket(reader io.Reader, dense bool) (*mmData, error) {
+	s := newLineScanner(reader, mmFormat)
+
+	// заголовок: %%MatrixMarket matrix <coordinate|array> <field> <symmetry>
+	if !s.Scan() {
+		if err := s.Err(); err != nil {
+			return nil, err
+		}
+		return nil, s.errorf("empty file")
+	}
+	s.line++
+	header := strings.Fields(strings.ToLower(s.Text()))
+	if len(header) != 5 || header[0] != strings.ToLower(mmHeader) || header[1] != "matrix" {
+		return nil, s.errorf("expected header %q", mmHeader+" matrix <format> <field> <symmetry>")
+	}
+	format, field, symmetry := header[2], header[3], header[4]
+
+	if format != "coordinate" && format != "array" {
+		return nil, s.errorf("unsupported format %q", format)
+	}
+	if field != "real" && field != "integer" && (field != "pattern" || format != "coordinate") {
+		return nil, s.errorf("unsupported field %q for %s format", field, format)
+	}
+	if symmetry != "general" && symmetry != "symmetric" && symmetry != "skew-symmetric" {
+		return nil, s.errorf("unsupported symmetry %q", symmetry)
+	}
+
+	// строка размерности: rows columns [entries]
+	fields, err := s.next("%")
+	if err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		return nil, s.errorf("expected matrix dimensions, found EOF")
+	}
+	sizes := 2
+	if format == "coordinate" {
+		sizes = 3
+	}
+	if len(fields) != sizes {
+		return nil, s.errorf("expected %d sizes, found %d", sizes, len(fields))
+	}
+	size := make([]int, sizes)
+	for k, f := range fields {
+		if size[k], err = strconv.Atoi(f); err != nil || size[k] < 0 {
+			return nil, s.errorf("incorrect size %q", f)
+		}
+	}
+
+	M := &mmData{rows: size[0], columns: size[1]}
+	if M.rows <= 0 || M.columns <= 0 {
+		return nil, s.errorf("matrix dimensions must be positive")
+	}
+	maxElements := math.MaxInt
+	if dense {
+		maxElements = mmMaxDenseElements
+	}
+	if M.rows > maxElements/M.columns {
+		return nil, s.errorf("matrix dimensions %d*%d are too large", M.rows, M.columns)
+	}
+	if symmetry != "general" && M.rows != M.columns {
+		return nil, s.errorf("%s matrix must be square", symmetry)
+	}
+
+	// количество элементов в файле
+	entries := size[len(size)-1]
+	if format == "array" {
+		switch symmetry {
+		case "general":
+			entries = M.rows * M.columns
+		case "symmetric":
+			entries = M.rows * (M.rows + 1) / 2
+		default:
+			entries = M.rows * (M.rows - 1) / 2
+		}
+	}
+
+	// индексы текущего элемента в формате array (см. nextArrayIndex)
+	i, j := 0, 0
+	if symmetry == "skew-symmetric" {
+		i = 1
+	}
+
+	for n := 0; n < entries; n++ {
+		fields, err := s.next("%")
+		if err != nil {
+			return nil, err
+		}
+		if fields == nil {
+			return nil, s.errorf("expected %d entries, found %d", entries, n)
+		}
+
+		var value float64
+		if format == "array" {
+			if len(fields) != 1 {
+				return nil, s.errorf("expected 1 value, found %d fields", len(fields))
+			}
+			if value, err = strconv.ParseFloat(fields[0], 64); err != nil {
+				return nil, s.errorf("incorrect value %q", fields[0])
+			}
+		} else {
+			if i, j, value, err = parseCoordinate(fields, field == "pattern"); err != nil {
+				return nil, s.errorf("%v", err)
+			}
+			if i < 0 || i >= M.rows || j < 0 || j >= M.columns {
+				return nil, s.errorf("index (%d, %d) out of range %d*%d", i+1, j+1, M.rows, M.columns)
+			}
+			if symmetry == "symmetric" && j > i || symmetry == "skew-symmetric" && j >= i {
+				return nil, s.errorf("index (%d, %d) is not in lower triangle of %s matrix", i+1, j+1, symmetry)
+			}
+		}
+
+		M.append(i, j, value)
+		switch {
+		case symmetry == "symmetric" && i != j:
+			M.append(j, i, value)
+		case symmetry == "skew-symmetric":
+			M.append(j, i, -value)
+		}
+
+		if format == "array" {
+			i, j = M.nextArrayIndex(i, j, symmetry)
+		}
+	}
+
+	// после элементов допускаются только комментарии и пустые строки
+	fields, err = s.next("%")
+	if err != nil {
+		return nil, err
+	}
+	if fields != nil {
+		return nil, s.errorf("expected %d entries, found more", entries)
+	}
+
+	return M, nil
+}
+
+// nextArrayIndex возвращает индексы элемента файла в формате array, следующего за элементом с индексами i, j:
+// элементы записаны по столбцам, для симметричных матриц только нижний треугольник
+// (без диагонали для skew-symmetric), поэтому каждый столбец j начинается со строки j (j+1).
+func (M *mmData) nextArrayIndex(i, j int, symmetry string) (int, int) {
+	if i+1 < M.rows {
+		return i + 1, j
+	}
+
+	j++
+	switch symmetry {
+	case "general":
+		return 0, j
+	case "symmetric":
+		return j, j
+	default:
+		return j + 1, j
+	}
+}
+
+// append добавляет элемент value с индексами i, j.
+func (M *mmData) append(i, j int, value float64) {
+	M.rowIdx = append(M.rowIdx, i)
+	M.colIdx = append(M.colIdx, j)
+	M.values = append(M.values, value)
+}
+
+// parseCoordinate возвращает индексы (начиная с 0) и значение элемента строки файла в формате coordinate.
+// Для pattern строка не содержит значения, оно равно 1.
+func parseCoordinate(fields []string, pattern bool) (int, int, float64, error) {
+	expected := 3
+	if pattern {
+		expected = 2
+	}
+	if len(fields) != expected {
+		return 0, 0, 0, fmt.Errorf("expected %d fields, found %d", expected, len(fields))
+	}
+
+	i, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("incorrect row index %q", fields[0])
+	}
+	j, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("incorrect column index %q", fields[1])
+	}
+
+	value := 1.
+	if !pattern {
+		if value, err = strconv.ParseFloat(fields[2], 64); err != nil {
+			return 0, 0, 0, fmt.Errorf("incorrect value %q", fields[2])
+		}
+	}
+
+	return i - 1, j - 1, value, nil
+}
+
+// toMatrix возвращает плотную матрицу с элементами float64, повторяющиеся элементы суммируются.
+func (M *mmData) toMatrix() *myMatrix {
+	res := zeroOf(M.rows, M.columns, Float64)
+	for n, v := range M.values {
+		res.data[M.rowIdx[n]*res.stride+M.colIdx[n]] += v
+	}
+	return res
+}
+
+// writeMatrixMarket записывает матрицу M в writer в формате Matrix Market array real general
+// без потери точности.
+func writeMatrixMarket(writer io.Writer, M *myMatrix) error {
+	w := bufio.NewWriter(writer)
+
+	fmt.Fprintf(w, "%s matrix array real general\n%d %d\n", mmHeader, M.rows, M.columns)
+
+	bitSize := 64
+	if M.dtype == Float32 {
+		bitSize = 32
+	}
+	// элементы записываются по столбцам
+	for j := 0; j < M.columns; j++ {
+		for i := 0; i < M.rows; i++ {
+			w.WriteString(strconv.FormatFloat(M.getIJ(i, j), 'g', -1, bitSize))
+			w.WriteByte('\n')
+		}
+	}
+
+	return w.Flush()
+}
+
+// writeSparseMatrixMarket записывает разреженную матрицу S в writer в формате Matrix Market
+// coordinate real general без потери точности.
+func writeSparseMatrixMarket(writer io.Writer, S *mySparse) error {
+	w := bufio.NewWriter(writer)
+
+	fmt.Fprintf(w, "%s matrix coordinate real general\n%d %d %d\n", mmHeader, S.rows, S.columns, S.nnz())
+
+	for i := 0; i < S.rows; i++ {
+		for p := S.indptr[i]; p < S.indptr[i+1]; p++ {
+			fmt.Fprintf(w, "%d %d %s\n", i+1, S.indices[p]+1, strconv.FormatFloat(S.values[p], 'g', -1, 64))
+		}
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,250 @@
+package matrix
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// файл содержит чтение и запись матриц в форматах NumPy .npy и .npz
+// (см. https://numpy.org/doc/stable/reference/generated/numpy.lib.format.html).
+
+// npyMagic первые байты файла .npy.
+const npyMagic = "\x93NUMPY"
+
+// npyAlign кратность длины заголовка вместе с магическими байтами, версией и длиной заголовка.
+const npyAlign = 64
+
+// npyMaxElements наибольшее количество элементов читаемого массива .npy.
+// Ограничение защищает от переполнения размера данных при некорректной размерности в заголовке.
+const npyMaxElements = math.MaxInt32
+
+// регулярные выражения для полей заголовка .npy, который является литералом словаря Python
+var (
+	npyDescrRe   = regexp.MustCompile(`'descr'\s*:\s*'([^']*)'`)
+	npyFortranRe = regexp.MustCompile(`'fortran_order'\s*:\s*(True|False)`)
+	npyShapeRe   = regexp.MustCompile(`'shape'\s*:\s*\(([^)]*)\)`)
+)
+
+// writeNpy записывает матрицу M в поток writer в формате .npy версии 1.0:
+// двумерный массив в порядке строк (C order) с элементами little-endian float64 ('<f8') или float32 ('<f4').
+func writeNpy(writer io.Writer, M *myMatrix) error {
+	descr := "<f8"
+	if M.dtype == Float32 {
+		descr = "<f4"
+	}
+
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': (%d, %d), }", descr, M.rows, M.columns)
+
+	// заголовок дополняется пробелами и завершается переводом строки
+	prefix := len(npyMagic) + 4
+	pad := npyAlign - (prefix+len(header)+1)%npyAlign
+	if pad == npyAlign {
+		pad = 0
+	}
+	header += strings.Repeat(" ", pad) + "\n"
+
+	buf := make([]byte, 0, prefix+len(header)+M.rows*M.columns*M.dtype.Size())
+	buf = append(buf, npyMagic...)
+	buf = append(buf, 1, 0)
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(len(header)))
+	buf = append(buf, header...)
+
+	for i := 0; i < M.rows; i++ {
+		for j := 0; j < M.columns; j++ {
+			if M.dtype == Float32 {
+				buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(M.data32[i*M.stride+j]))
+			} else {
+				buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(M.data[i*M.stride+j]))
+			}
+		}
+	}
+
+	_, err := writer.Write(buf)
+	return err
+}
+
+// readNpy читает матрицу из потока reader в формате .npy версий 1.0, 2.0 и 3.0.
+// Поддерживаются элементы float64 и float32 с любым порядком байтов,
+// порядок элементов по строкам (C order) и по столбцам (Fortran order).
+// Одномерный массив длины n читается как вектор размерности n на 1, скаляр как матрица 1 на 1.
+// Функция возвращает ошибку, если массив имеет больше двух измерений, нулевой размер,
+// больше npyMaxElements элементов или неподдерживаемый тип элементов.
+func readNpy(reader io.Reader) (*myMatrix, error) {
+	prefix := make([]byte, len(npyMagic)+2)
+	if _, err := io.ReadFull(reader, prefix); err != nil {
+		return nil, fmt.Errorf("npy: reading magic string: %w", err)
+	}
+	if string(prefix[:len(npyMagic)]) != npyMagic {
+		return nil, fmt.Errorf("npy: incorrect magic string")
+	}
+
+	// длина заголовка 2 байта в версии 1.0 и 4 байта в версиях 2.0 и 3.0
+	var headerLen int
+	switch major := prefix[len(npyMagic)]; major {
+	case 1:
+		var n uint16
+		if err := binary.Read(reader, binary.LittleEndian, &n); err != nil {
+			return nil, fmt.Errorf("npy: reading header length: %w", err)
+		}
+		headerLen = int(n)
+	case 2, 3:
+		var n uint32
+		if err := binary.Read(reader, binary.LittleEndian, &n); err != nil {
+			return nil, fmt.Errorf("npy: reading header length: %w", err)
+		}
+		headerLen = int(n)
+	default:
+		return nil, fmt.Errorf("npy: unsupported format version %d", major)
+	}
+
+	header := make([]byte, headerLen)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, fmt.Errorf("npy: reading header: %w", err)
+	}
+
+	dtype, order, rows, columns, fortran, err := parseNpyHeader(string(header))
+	if err != nil {
+		return nil, err
+	}
+
+	// память под данные растет по мере чтения, а не выделяется сразу по размерности из заголовка
+	size := int64(rows) * int64(columns) * int64(dtype.Size())
+	data, err := io.ReadAll(io.LimitReader(reader, size))
+	if err != nil {
+		return nil, fmt.Errorf("npy: reading data: %w", err)
+	}
+	if int64(len(data)) != size {
+		return nil, fmt.Errorf("npy: reading data: %w", io.ErrUnexpectedEOF)
+	}
+
+	M := zeroOf(rows, columns, dtype)
+	for k := 0; k < rows*columns; k++ {
+		i, j := k/columns, k%columns
+		if fortran {
+			i, j = k%rows, k/rows
+		}
+
+		if dtype == Float32 {
+			M.data32[i*M.stride+j] = math.Float32frombits(order.Uint32(data[4*k:]))
+		} else {
+			M.data[i*M.stride+j] = math.Float64frombits(order.Uint64(data[8*k:]))
+		}
+	}
+
+	return M, nil
+}
+
+// parseNpyHeader возвращает тип элементов, порядок байтов, размерность массива
+// и флаг порядка элементов по столбцам из заголовка .npy.
+func parseNpyHeader(header string) (DType, binary.ByteOrder, int, int, bool, error) {
+	descr := npyDescrRe.FindStringSubmatch(header)
+	fortran := npyFortranRe.FindStringSubmatch(header)
+	shape := npyShapeRe.FindStringSubmatch(header)
+	if descr == nil || fortran == nil || shape == nil {
+		return 0, nil, 0, 0, false, fmt.Errorf("npy: incorrect header %q", strings.TrimSpace(header))
+	}
+
+	var order binary.ByteOrder = binary.LittleEndian
+	var dtype DType
+	switch descr[1] {
+	case "<f8", "=f8", ">f8":
+		dtype = Float64
+	case "<f4", "=f4", ">f4":
+		dtype = Float32
+	default:
+		return 0, nil, 0, 0, false, fmt.Errorf("npy: unsupported element type %q", descr[1])
+	}
+	if descr[1][0] == '>' {
+		order = binary.BigEndian
+	}
+
+	var dims []int
+	for _, field := range strings.Split(shape[1], ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		d, err := strconv.Atoi(field)
+		if err != nil {
+			return 0, nil, 0, 0, false, fmt.Errorf("npy: incorrect shape %q", shape[1])
+		}
+		dims = append(dims, d)
+	}
+
+	rows, columns := 1, 1
+	switch len(dims) {
+	case 0:
+	case 1:
+		rows = dims[0]
+	case 2:
+		rows, columns = dims[0], dims[1]
+	default:
+		return 0, nil, 0, 0, false, fmt.Errorf("npy: array with %d dimensions is not a matrix", len(dims))
+	}
+	if rows <= 0 || columns <= 0 {
+		return 0, nil, 0, 0, false, fmt.Errorf("npy: empty array of shape (%s)", shape[1])
+	}
+	if rows > npyMaxElements/columns {
+		return 0, nil, 0, 0, false, fmt.Errorf("npy: array of shape (%s) is too large", shape[1])
+	}
+
+	return dtype, order, rows, columns, fortran[1] == "True", nil
+}
+
+// writeNpz записывает матрицы arrays в поток writer в формате .npz:
+// zip архив без сжатия, в котором каждая матрица записана в файл с именем ключа и расширением .npy.
+// Файлы записываются в порядке возрастания имен.
+func writeNpz(writer io.Writer, arrays map[string]*myMatrix) error {
+	names := make([]string, 0, len(arrays))
+	for name := range arrays {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	zw := zip.NewWriter(writer)
+	for _, name := range names {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name + ".npy", Method: zip.Store})
+		if err != nil {
+			return err
+		}
+		if err := writeNpy(w, arrays[name]); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// readNpz читает матрицы из архива .npz размером size байт, сжатого (numpy.savez_compressed) или нет (numpy.savez).
+// Ключ каждой матрицы имя файла в архиве без расширения .npy.
+func readNpz(reader io.ReaderAt, size int64) (map[string]*myMatrix, error) {
+	zr, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("npz: %w", err)
+	}
+
+	arrays := make(map[string]*myMatrix, len(zr.File))
+	for _, file := range zr.File {
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("npz: %s: %w", file.Name, err)
+		}
+
+		M, err := readNpy(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("npz: %s: %w", file.Name, err)
+		}
+
+		arrays[strings.TrimSuffix(file.Name, ".npy")] = M
+	}
+
+	return arrays, nil
+}
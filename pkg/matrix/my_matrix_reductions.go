@@ -0,0 +1,191 @@
+package matrix
+
+import "math"
+
+// файл содержит свертки элементов матрицы: по всей матрице, по строкам и по столбцам
+
+// each вызывает функцию f для каждого элемента матрицы M (структуры myMatrix) в порядке строк.
+// i, j индексы элемента, x его значение, приведенное к float64.
+func (M *myMatrix) each(f func(i, j int, x float64)) {
+	if M.dtype == Float32 {
+		eachDense(M.dense32(), f)
+		return
+	}
+	eachDense(M.dense64(), f)
+}
+
+// eachDense вызывает функцию f для каждого элемента d в порядке строк.
+func eachDense[T float](d dense[T], f func(i, j int, x float64)) {
+	for i := 0; i < d.rows; i++ {
+		for j, x := range d.row(i) {
+			f(i, j, float64(x))
+		}
+	}
+}
+
+// axisLen возвращает количество сверток по направлению axis (длину результата)
+// и количество элементов в одной свертке.
+// Функция вызывает панику, если направление axis не определено.
+func (M *myMatrix) axisLen(axis Axis) (int, int) {
+	switch axis {
+	case AxisRows:
+		return M.rows, M.columns
+	case AxisColumns:
+		return M.columns, M.rows
+	}
+	panic("Incorrect axis for matrix reduction")
+}
+
+// axisIndex возвращает номер свертки по направлению axis, в которую входит элемент i, j.
+func axisIndex(axis Axis, i, j int) int {
+	if axis == AxisRows {
+		return i
+	}
+	return j
+}
+
+// axisMatrix возвращает указатель на матрицу (структуру myMatrix) с типом элементов dtype,
+// содержащую значения vals: вектор-столбец для AxisRows и вектор-строку для AxisColumns.
+func axisMatrix(axis Axis, vals []float64, dtype DType) *myMatrix {
+	var res *myMatrix
+	if axis == AxisRows {
+		res = zeroOf(len(vals), 1, dtype)
+	} else {
+		res = zeroOf(1, len(vals), dtype)
+	}
+
+	for k, v := range vals {
+		if axis == AxisRows {
+			res.setIJ(k, 0, v)
+		} else {
+			res.setIJ(0, k, v)
+		}
+	}
+
+	return res
+}
+
+// sum возвращает сумму всех элементов матрицы M.
+// Сумма вычисляется в float64 для любого типа элементов.
+func (M *myMatrix) sum() float64 {
+	s := 0.
+	M.each(func(_, _ int, x float64) {
+		s += x
+	})
+	return s
+}
+
+// sumAxis возвращает указатель на матрицу сумм элементов матрицы M по направлению axis.
+func (M *myMatrix) sumAxis(axis Axis) *myMatrix {
+	n, _ := M.axisLen(axis)
+
+	sums := make([]float64, n)
+	M.each(func(i, j int, x float64) {
+		sums[axisIndex(axis, i, j)] += x
+	})
+
+	return axisMatrix(axis, sums, M.dtype)
+}
+
+// mean возвращает среднее арифметическое всех элементов матрицы M.
+func (M *myMatrix) mean() float64 {
+	return M.sum() / float64(M.rows*M.columns)
+}
+
+// meanAxis возвращает указатель на матрицу средних арифметических элементов матрицы M по направлению axis.
+func (M *myMatrix) meanAxis(axis Axis) *myMatrix {
+	n, size := M.axisLen(axis)
+
+	sums := make([]float64, n)
+	M.each(func(i, j int, x float64) {
+		sums[axisIndex(axis, i, j)] += x
+	})
+
+	for k := range sums {
+		sums[k] /= float64(size)
+	}
+
+	return axisMatrix(axis, sums, M.dtype)
+}
+
+// norm возвращает евклидову (фробениусову) норму матрицы M: корень из суммы квадратов элементов.
+func (M *myMatrix) norm() float64 {
+	s := 0.
+	M.each(func(_, _ int, x float64) {
+		s += x * x
+	})
+	return math.Sqrt(s)
+}
+
+// normAxis возвращает указатель на матрицу евклидовых норм строк (AxisRows) или столбцов (AxisColumns) матрицы M.
+func (M *myMatrix) normAxis(axis Axis) *myMatrix {
+	n, _ := M.axisLen(axis)
+
+	sums := make([]float64, n)
+	M.each(func(i, j int, x float64) {
+		sums[axisIndex(axis, i, j)] += x * x
+	})
+
+	for k := range sums {
+		sums[k] = math.Sqrt(sums[k])
+	}
+
+	return axisMatrix(axis, sums, M.dtype)
+}
+
+// argBest возвращает индексы i, j и значение элемента матрицы M, лучшего по функции better:
+// better(x, best) возвращает true, если x лучше текущего лучшего значения best.
+// При равенстве выбирается первый элемент в порядке строк, первый NaN лучше любого элемента (см. replaces).
+func (M *myMatrix) argBest(better func(x, best float64) bool) (int, int, float64) {
+	bi, bj, best := 0, 0, M.getIJ(0, 0)
+	M.each(func(i, j int, x float64) {
+		if replaces(better, x, best) {
+			bi, bj, best = i, j, x
+		}
+	})
+	return bi, bj, best
+}
+
+// argBestAxis возвращает индексы и значения лучших по функции better элементов
+// в каждой строке (AxisRows) или каждом столбце (AxisColumns) матрицы M.
+// Индекс это номер столбца лучшего элемента строки или номер строки лучшего элемента столбца.
+func (M *myMatrix) argBestAxis(axis Axis, better func(x, best float64) bool) ([]int, []float64) {
+	n, _ := M.axisLen(axis)
+
+	idx := make([]int, n)
+	vals := make([]float64, n)
+	seen := make([]bool, n)
+
+	M.each(func(i, j int, x float64) {
+		k, pos := i, j
+		if axis == AxisColumns {
+			k, pos = j, i
+		}
+
+		if !seen[k] || replaces(better, x, vals[k]) {
+			idx[k], vals[k], seen[k] = pos, x, true
+		}
+	})
+
+	return idx, vals
+}
+
+// replaces возвращает true, если элемент x должен заменить текущее лучшее значение best:
+// NaN распространяется, поэтому NaN заменяет любое значение, кроме NaN, и не заменяется ничем,
+// иначе x заменяет best, если better(x, best).
+func replaces(better func(x, best float64) bool, x, best float64) bool {
+	if math.IsNaN(best) {
+		return false
+	}
+	return math.IsNaN(x) || better(x, best)
+}
+
+// greater возвращает true, если x больше best.
+func greater(x, best float64) bool {
+	return x > best
+}
+
+// less возвращает true, если x меньше best.
+func less(x, best float64) bool {
+	return x < best
+}
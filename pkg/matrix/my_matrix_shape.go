@@ -0,0 +1,100 @@
+package matrix
+
+// файл содержит изменение формы матриц, объединение матриц и представления частей матрицы
+
+// view возвращает указатель на структуру myMatrix, представляющую блок размерности rows на columns
+// матрицы M, начинающийся с элемента i, j. Блок разделяет память с матрицей M.
+// Блок должен быть проверен заранее.
+func (M *myMatrix) view(i, j, rows, columns int) *myMatrix {
+	start := i*M.stride + j
+	end := start + (rows-1)*M.stride + columns
+
+	V := &myMatrix{
+		columns: columns,
+		rows:    rows,
+		stride:  M.stride,
+		dtype:   M.dtype,
+	}
+
+	if M.dtype == Float32 {
+		V.data32 = M.data32[start:end]
+	} else {
+		V.data = M.data[start:end]
+	}
+
+	return V
+}
+
+// isContiguous возвращает true, если строки матрицы M лежат в памяти подряд без промежутков.
+func (M *myMatrix) isContiguous() bool {
+	return M.stride == M.columns || M.rows == 1
+}
+
+// reshape возвращает указатель на структуру myMatrix размерности rows на columns
+// с элементами матрицы M в порядке строк.
+// Если строки M лежат в памяти подряд, результат разделяет память с M, иначе является копией.
+// Функция вызывает панику, если количество элементов не совпадает.
+func (M *myMatrix) reshape(rows, columns int) *myMatrix {
+	must(M.checkReshape(rows, columns))
+
+	if !M.isContiguous() {
+		M = M.copy()
+	}
+
+	R := &myMatrix{
+		columns: columns,
+		rows:    rows,
+		stride:  columns,
+		dtype:   M.dtype,
+	}
+
+	if M.dtype == Float32 {
+		R.data32 = M.data32[:rows*columns]
+	} else {
+		R.data = M.data[:rows*columns]
+	}
+
+	return R
+}
+
+// copy возвращает указатель на копию матрицы M, строки которой лежат в памяти подряд.
+func (M *myMatrix) copy() *myMatrix {
+	C := zeroOf(M.rows, M.columns, M.dtype)
+	copyOp(M, C)
+	return C
+}
+
+// stack возвращает указатель на структуру myMatrix, в которой матрицы ms объединены
+// по строкам (при AxisRows строки результата составлены из строк всех матриц подряд)
+// или по столбцам (при AxisColumns матрицы расположены друг под другом).
+// Функция вызывает панику, если направление axis не определено или матрицы нельзя объединить (см. checkStack).
+func stack(op string, axis Axis, ms []*myMatrix) *myMatrix {
+	if axis != AxisRows && axis != AxisColumns {
+		panic("Incorrect axis for matrix concatenation")
+	}
+	must(checkStack(op, axis, ms))
+
+	rows, columns := ms[0].rows, ms[0].columns
+	for _, M := range ms[1:] {
+		if axis == AxisRows {
+			columns += M.columns
+		} else {
+			rows += M.rows
+		}
+	}
+
+	C := zeroOf(rows, columns, ms[0].dtype)
+
+	offset := 0
+	for _, M := range ms {
+		if axis == AxisRows {
+			copyOp(M, C.view(0, offset, M.rows, M.columns))
+			offset += M.columns
+		} else {
+			copyOp(M, C.view(offset, 0, M.rows, M.columns))
+			offset += M.rows
+		}
+	}
+
+	return C
+}
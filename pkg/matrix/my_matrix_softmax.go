@@ -0,0 +1,168 @@
+package matrix
+
+import "math"
+
+// файл содержит softmax, log-softmax и log-sum-exp по столбцам матрицы и их производные по направлению.
+// Из каждого столбца вычитается его наибольший элемент, поэтому экспонента не переполняется.
+// Вычисления производятся в float64, для матриц с элементами float32 результат округляется до float32.
+
+// softmaxOp вид результата ядра softmaxRows.
+type softmaxOp int
+
+const (
+	opSoftmax    softmaxOp = iota // softmax: exp(x - lse)
+	opLogSoftmax                  // log-softmax: x - lse
+)
+
+// columnStats возвращает для каждого столбца A сдвиг (наибольший конечный элемент, иначе 0)
+// и сумму экспонент элементов столбца за вычетом сдвига.
+// log-sum-exp столбца равен shift + log(sum).
+func columnStats[T float](A dense[T]) ([]float64, []float64) {
+	shift := make([]float64, A.columns)
+	sum := make([]float64, A.columns)
+
+	for j := range shift {
+		shift[j] = math.Inf(-1)
+	}
+	for i := 0; i < A.rows; i++ {
+		for j, x := range A.row(i) {
+			shift[j] = math.Max(shift[j], float64(x))
+		}
+	}
+
+	// при бесконечном наибольшем элементе сдвиг не нужен: результат и так бесконечен или NaN
+	for j, m := range shift {
+		if math.IsInf(m, 0) {
+			shift[j] = 0
+		}
+	}
+
+	for i := 0; i < A.rows; i++ {
+		for j, x := range A.row(i) {
+			sum[j] += math.Exp(float64(x) - shift[j])
+		}
+	}
+
+	return shift, sum
+}
+
+// softmaxRows записывает в C softmax или log-softmax столбцов A по статистикам столбцов shift и sum.
+func softmaxRows[T float](op softmaxOp, C, A dense[T], shift, sum []float64) {
+	for i := 0; i < C.rows; i++ {
+		rowC, rowA := C.row(i), A.row(i)
+		for j := range rowC {
+			x := float64(rowA[j]) - shift[j]
+			if op == opSoftmax {
+				rowC[j] = T(math.Exp(x) / sum[j])
+			} else {
+				rowC[j] = T(x - math.Log(sum[j]))
+			}
+		}
+	}
+}
+
+// softmaxJVPRows записывает в C производную по направлению V softmax (при op равном opSoftmax)
+// или log-softmax столбцов A: s ⊙ (v - <s, v>) или v - <s, v>, где s softmax столбца.
+// Возвращает скалярные произведения <s, v> столбцов, которые являются производной log-sum-exp.
+// C может быть nil, тогда записываются только скалярные произведения.
+func softmaxJVPRows[T float](op softmaxOp, C *dense[T], A, V dense[T]) []float64 {
+	shift, sum := columnStats(A)
+
+	dot := make([]float64, A.columns)
+	for i := 0; i < A.rows; i++ {
+		rowA, rowV := A.row(i), V.row(i)
+		for j := range rowA {
+			dot[j] += math.Exp(float64(rowA[j])-shift[j]) / sum[j] * float64(rowV[j])
+		}
+	}
+
+	if C == nil {
+		return dot
+	}
+
+	for i := 0; i < C.rows; i++ {
+		rowC, rowA, rowV := C.row(i), A.row(i), V.row(i)
+		for j := range rowC {
+			d := float64(rowV[j]) - dot[j]
+			if op == opSoftmax {
+				d *= math.Exp(float64(rowA[j])-shift[j]) / sum[j]
+			}
+			rowC[j] = T(d)
+		}
+	}
+
+	return dot
+}
+
+// softmax возвращает указатель на матрицу softmax (при op равном opSoftmax) или log-softmax столбцов M.
+func (M *myMatrix) softmax(op softmaxOp) *myMatrix {
+	C := zeroOf(M.rows, M.columns, M.dtype)
+	C.softmaxInto(op, M)
+
+	return C
+}
+
+// softmaxInto записывает в C softmax (при op равном opSoftmax) или log-softmax столбцов M.
+// Статистики столбцов вычисляются до записи, поэтому C может совпадать с M.
+// Размерности и типы элементов должны быть проверены заранее.
+func (C *myMatrix) softmaxInto(op softmaxOp, M *myMatrix) {
+	if M.dtype == Float32 {
+		shift, sum := columnStats(M.dense32())
+		softmaxRows(op, C.dense32(), M.dense32(), shift, sum)
+	} else {
+		shift, sum := columnStats(M.dense64())
+		softmaxRows(op, C.dense64(), M.dense64(), shift, sum)
+	}
+}
+
+// logSumExp возвращает указатель на вектор-строку размерности 1 на columns
+// логарифмов сумм экспонент столбцов M.
+func (M *myMatrix) logSumExp() *myMatrix {
+	var shift, sum []float64
+	if M.dtype == Float32 {
+		shift, sum = columnStats(M.dense32())
+	} else {
+		shift, sum = columnStats(M.dense64())
+	}
+
+	for j := range shift {
+		shift[j] += math.Log(sum[j])
+	}
+
+	return axisMatrix(AxisColumns, shift, M.dtype)
+}
+
+// softmaxJVP возвращает указатель на производную softmax (при op равном opSoftmax) или log-softmax
+// столбцов M по направлению V. Размерности и типы элементов должны быть проверены заранее.
+func (M *myMatrix) softmaxJVP(op softmaxOp, V *myMatrix) *myMatrix {
+	C := zeroOf(M.rows, M.columns, M.dtype)
+	C.softmaxJVPInto(op, M, V)
+
+	return C
+}
+
+// softmaxJVPInto записывает в C производную softmax (при op равном opSoftmax) или log-softmax
+// столбцов M по направлению V. Скалярные произведения вычисляются до записи, поэтому C может совпадать с V.
+// Размерности и типы элементов должны быть проверены заранее.
+func (C *myMatrix) softmaxJVPInto(op softmaxOp, M, V *myMatrix) {
+	if M.dtype == Float32 {
+		c := C.dense32()
+		softmaxJVPRows(op, &c, M.dense32(), V.dense32())
+	} else {
+		c := C.dense64()
+		softmaxJVPRows(op, &c, M.dense64(), V.dense64())
+	}
+}
+
+// logSumExpJVP возвращает указатель на вектор-строку производных log-sum-exp столбцов M по направлению V.
+// Размерности и типы элементов должны быть проверены заранее.
+func (M *myMatrix) logSumExpJVP(V *myMatrix) *myMatrix {
+	var dot []float64
+	if M.dtype == Float32 {
+		dot = softmaxJVPRows[float32](opSoftmax, nil, M.dense32(), V.dense32())
+	} else {
+		dot = softmaxJVPRows[float64](opSoftmax, nil, M.dense64(), V.dense64())
+	}
+
+	return axisMatrix(AxisColumns, dot, M.dtype)
+}
@@ -0,0 +1,327 @@
+package matrix
+
+import (
+	"math"
+	"sort"
+)
+
+// файл содержит сингулярное разложение и разложение симметричной матрицы по собственным векторам.
+// Оба разложения вычисляются методом Якоби над копией матрицы с элементами float64.
+
+// maxJacobiSweeps максимальное количество проходов метода Якоби.
+// На практике метод сходится за 6-10 проходов, ограничение срабатывает только для NaN и Inf.
+const maxJacobiSweeps = 60
+
+// mySVD хранит сингулярное разложение A = U * diag(s) * V^T с элементами float64.
+type mySVD struct {
+	u, v  *myMatrix // Левые и правые сингулярные векторы по столбцам
+	s     []float64 // Сингулярные числа по убыванию
+	m, n  int       // Размерность исходной матрицы
+	dtype DType     // Тип элементов исходной матрицы
+}
+
+// svdDecompose возвращает сингулярное разложение матрицы A и false, если метод Якоби не сошелся.
+// При full равном false разложение тонкое: U размерности m на k, V размерности n на k, где k = min(m, n),
+// иначе U размерности m на m, V размерности n на n.
+func svdDecompose(A *myMatrix, full bool) (*mySVD, bool) {
+	// для широкой матрицы раскладывается A^T = V diag(s) U^T
+	if A.rows < A.columns {
+		f, ok := svdDecompose(A.t(), full)
+		if !ok {
+			return nil, false
+		}
+		f.u, f.v = f.v, f.u
+		f.m, f.n = f.n, f.m
+		f.dtype = A.dtype
+		return f, true
+	}
+
+	a := A.asType(Float64).dense64()
+	m, n := a.rows, a.columns
+	V := identity(n, Float64)
+	v := V.dense64()
+
+	// односторонний метод Якоби: вращения столбцов a до их попарной ортогональности
+	converged := false
+	for sweep := 0; sweep < maxJacobiSweeps && !converged; sweep++ {
+		converged = true
+		for p := 0; p < n-1; p++ {
+			for q := p + 1; q < n; q++ {
+				alpha, beta, gamma := 0., 0., 0.
+				for i := 0; i < m; i++ {
+					ap, aq := a.data[i*a.stride+p], a.data[i*a.stride+q]
+					alpha += ap * ap
+					beta += aq * aq
+					gamma += ap * aq
+				}
+
+				if gamma == 0 || math.Abs(gamma) <= 0x1p-52*math.Sqrt(alpha*beta) {
+					continue
+				}
+				converged = false
+
+				c, s := jacobiRotation(alpha, beta, gamma)
+				rotateColumns(a, p, q, c, s)
+				rotateColumns(v, p, q, c, s)
+			}
+		}
+	}
+	if !converged {
+		return nil, false
+	}
+
+	// сингулярные числа нормы столбцов, сортировка по убыванию
+	s := make([]float64, n)
+	for j := range s {
+		for i := 0; i < m; i++ {
+			s[j] = math.Hypot(s[j], a.data[i*a.stride+j])
+		}
+	}
+	order := make([]int, n)
+	for j := range order {
+		order[j] = j
+	}
+	sort.SliceStable(order, func(i, j int) bool { return s[order[i]] > s[order[j]] })
+
+	k := n
+	if full {
+		k = m
+	}
+	U := zeroOf(m, k, Float64)
+	u := U.dense64()
+	Vs := zeroOf(n, n, Float64)
+	vs := Vs.dense64()
+	sorted := make([]float64, n)
+
+	tol := float64(m) * 0x1p-52
+	if n > 0 {
+		tol *= s[order[0]]
+	}
+
+	// столбцы U для малых сингулярных чисел и дополнение до m столбцов строятся отдельно
+	var missing []int
+	for j, p := range order {
+		sorted[j] = s[p]
+		for i := 0; i < n; i++ {
+			vs.data[i*vs.stride+j] = v.data[i*v.stride+p]
+		}
+		if s[p] <= tol {
+			missing = append(missing, j)
+			continue
+		}
+		for i := 0; i < m; i++ {
+			u.data[i*u.stride+j] = a.data[i*a.stride+p] / s[p]
+		}
+	}
+	for j := n; j < k; j++ {
+		missing = append(missing, j)
+	}
+	completeBasis(u, missing)
+
+	return &mySVD{u: U, v: Vs, s: sorted, m: A.rows, n: A.columns, dtype: A.dtype}, true
+}
+
+// jacobiRotation возвращает косинус и синус вращения, обнуляющего скалярное произведение gamma
+// двух векторов с квадратами норм alpha и beta.
+func jacobiRotation(alpha, beta, gamma float64) (float64, float64) {
+	zeta := (beta - alpha) / (2 * gamma)
+	t := 1. / (math.Abs(zeta) + math.Sqrt(1+zeta*zeta))
+	if zeta < 0 {
+		t = -t
+	}
+	c := 1. / math.Sqrt(1+t*t)
+	return c, c * t
+}
+
+// rotateColumns применяет вращение к столбцам p и q матрицы a.
+func rotateColumns(a dense[float64], p, q int, c, s float64) {
+	for i := 0; i < a.rows; i++ {
+		row := a.row(i)
+		ap, aq := row[p], row[q]
+		row[p] = c*ap - s*aq
+		row[q] = s*ap + c*aq
+	}
+}
+
+// completeBasis заполняет столбцы missing матрицы u единичными векторами,
+// ортогональными остальным столбцам, так что все столбцы u образуют ортонормированную систему.
+// Кандидатами служат векторы стандартного базиса, ортогонализация Грама-Шмидта выполняется дважды.
+func completeBasis(u dense[float64], missing []int) {
+	if len(missing) == 0 {
+		return
+	}
+
+	filled := make([]bool, u.columns)
+	for j := range filled {
+		filled[j] = true
+	}
+	for _, j := range missing {
+		filled[j] = false
+	}
+
+	w := make([]float64, u.rows)
+	e := 0
+	for _, j := range missing {
+		for ; e < u.rows; e++ {
+			for i := range w {
+				w[i] = 0
+			}
+			w[e] = 1.
+
+			for pass := 0; pass < 2; pass++ {
+				for c := range filled {
+					if !filled[c] {
+						continue
+					}
+					d := 0.
+					for i := range w {
+						d += u.data[i*u.stride+c] * w[i]
+					}
+					for i := range w {
+						w[i] -= d * u.data[i*u.stride+c]
+					}
+				}
+			}
+
+			nrm := 0.
+			for _, x := range w {
+				nrm = math.Hypot(nrm, x)
+			}
+			if nrm > 0.5 {
+				for i, x := range w {
+					u.data[i*u.stride+j] = x / nrm
+				}
+				filled[j] = true
+				e++
+				break
+			}
+		}
+	}
+}
+
+// rankTol возвращает порог, ниже которого сингулярное число считается нулевым:
+// max(m, n) * машинный эпсилон * наибольшее сингулярное число.
+func (f *mySVD) rankTol() float64 {
+	if len(f.s) == 0 {
+		return 0
+	}
+	k := f.m
+	if f.n > k {
+		k = f.n
+	}
+	return float64(k) * 0x1p-52 * f.s[0]
+}
+
+// rank возвращает количество сингулярных чисел больше порога rankTol.
+func (f *mySVD) rank() int {
+	tol := f.rankTol()
+	r := 0
+	for _, x := range f.s {
+		if x > tol {
+			r++
+		}
+	}
+	return r
+}
+
+// pinv возвращает указатель на псевдообратную матрицу V * diag(1/s) * U^T с элементами float64,
+// сингулярные числа не больше порога rankTol считаются нулевыми.
+func (f *mySVD) pinv() *myMatrix {
+	r := f.rank()
+	P := zeroOf(f.n, f.m, Float64)
+	p, u, v := P.dense64(), f.u.dense64(), f.v.dense64()
+
+	for i := 0; i < f.n; i++ {
+		rowP := p.row(i)
+		for k := 0; k < r; k++ {
+			vk := v.data[i*v.stride+k] / f.s[k]
+			for j := range rowP {
+				rowP[j] += vk * u.data[j*u.stride+k]
+			}
+		}
+	}
+
+	return P
+}
+
+// myEigenSym хранит разложение симметричной матрицы A = V * diag(values) * V^T с элементами float64.
+type myEigenSym struct {
+	values  []float64 // Собственные значения по возрастанию
+	vectors *myMatrix // Собственные векторы по столбцам
+	dtype   DType     // Тип элементов исходной матрицы
+}
+
+// eigenSymDecompose возвращает разложение квадратной матрицы A по собственным векторам
+// и false, если метод Якоби не сошелся.
+// Используются только элементы на и под диагональю A. Размерность должна быть проверена заранее.
+func eigenSymDecompose(A *myMatrix) (*myEigenSym, bool) {
+	a := A.asType(Float64).dense64()
+	n := a.rows
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			a.data[i*a.stride+j] = a.data[j*a.stride+i]
+		}
+	}
+
+	V := identity(n, Float64)
+	v := V.dense64()
+
+	// классический циклический метод Якоби: A = J^T A J до обнуления внедиагональных элементов
+	converged := false
+	for sweep := 0; sweep < maxJacobiSweeps && !converged; sweep++ {
+		off, total := 0., 0.
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				x := a.data[i*a.stride+j] * a.data[i*a.stride+j]
+				total += x
+				if i != j {
+					off += x
+				}
+			}
+		}
+		if off <= 0x1p-104*total {
+			converged = true
+			break
+		}
+
+		for p := 0; p < n-1; p++ {
+			for q := p + 1; q < n; q++ {
+				apq := a.data[p*a.stride+q]
+				if apq == 0 {
+					continue
+				}
+
+				c, s := jacobiRotation(a.data[p*a.stride+p], a.data[q*a.stride+q], apq)
+				rotateColumns(a, p, q, c, s)
+				rowP, rowQ := a.row(p), a.row(q)
+				for k := range rowP {
+					xp, xq := rowP[k], rowQ[k]
+					rowP[k] = c*xp - s*xq
+					rowQ[k] = s*xp + c*xq
+				}
+				rotateColumns(v, p, q, c, s)
+			}
+		}
+	}
+	if !converged {
+		return nil, false
+	}
+
+	order := make([]int, n)
+	for j := range order {
+		order[j] = j
+	}
+	sort.SliceStable(order, func(i, j int) bool { return a.data[order[i]*a.stride+order[i]] < a.data[order[j]*a.stride+order[j]] })
+
+	values := make([]float64, n)
+	W := zeroOf(n, n, Float64)
+	w := W.dense64()
+	for j, p := range order {
+		values[j] = a.data[p*a.stride+p]
+		for i := 0; i < n; i++ {
+			w.data[i*w.stride+j] = v.data[i*v.stride+p]
+		}
+	}
+
+	return &myEigenSym{values: values, vectors: W, dtype: A.dtype}, true
+}
@@ -0,0 +1,295 @@
+package matrix
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// mySparse представляет структуру разреженной матрицы в формате CSR (compressed sparse row).
+// Ненулевые элементы i строки хранятся в values[indptr[i]:indptr[i+1]],
+// номера их столбцов в indices[indptr[i]:indptr[i+1]] по возрастанию.
+// Индексация элементов начинается с 0.
+type mySparse struct {
+	columns int       // Количество столбцов в матрице
+	rows    int       // Количество строк в матрице
+	indptr  []int     // Начала строк в indices и values, длина rows+1
+	indices []int     // Номера столбцов ненулевых элементов
+	values  []float64 // Значения ненулевых элементов
+}
+
+// sparseFromTriplets создает и возвращает указатель на разреженную матрицу (структуру mySparse)
+// размерности rows на columns из троек (rowIdx[n], colIdx[n], values[n]).
+// Значения повторяющихся пар индексов суммируются.
+// Функция возвращает ошибку, если размерности не положительны, слайсы разной длины
+// или индексы выходят за пределы матрицы.
+func sparseFromTriplets(rows, columns int, rowIdx, colIdx []int, values []float64) (*mySparse, error) {
+	if rows <= 0 || columns <= 0 {
+		return nil, errors.New("sparse matrix dimensions must be positive")
+	}
+
+	if len(rowIdx) != len(colIdx) || len(rowIdx) != len(values) {
+		return nil, errors.New("triplet slices must have equal lengths")
+	}
+
+	for n := range rowIdx {
+		if rowIdx[n] < 0 || rowIdx[n] >= rows || colIdx[n] < 0 || colIdx[n] >= columns {
+			return nil, fmt.Errorf("triplet %d index (%d, %d) out of range %d * %d", n, rowIdx[n], colIdx[n], rows, columns)
+		}
+	}
+
+	// сортируем тройки по строке, затем по столбцу
+	order := make([]int, len(values))
+	for n := range order {
+		order[n] = n
+	}
+	sort.Slice(order, func(a, b int) bool {
+		if rowIdx[order[a]] != rowIdx[order[b]] {
+			return rowIdx[order[a]] < rowIdx[order[b]]
+		}
+		return colIdx[order[a]] < colIdx[order[b]]
+	})
+
+	S := &mySparse{
+		rows:    rows,
+		columns: columns,
+		indptr:  make([]int, rows+1),
+		indices: make([]int, 0, len(values)),
+		values:  make([]float64, 0, len(values)),
+	}
+
+	for idx, n := range order {
+		// повторяющаяся пара индексов
+		if idx > 0 && rowIdx[n] == rowIdx[order[idx-1]] && colIdx[n] == colIdx[order[idx-1]] {
+			S.values[len(S.values)-1] += values[n]
+			continue
+		}
+
+		S.indices = append(S.indices, colIdx[n])
+		S.values = append(S.values, values[n])
+		S.indptr[rowIdx[n]+1]++
+	}
+
+	for i := 0; i < rows; i++ {
+		S.indptr[i+1] += S.indptr[i]
+	}
+
+	return S, nil
+}
+
+// sparseFromMatrix возвращает указатель на разреженную матрицу (структуру mySparse),
+// содержащую все ненулевые элементы матрицы M.
+func sparseFromMatrix(M *myMatrix) *mySparse {
+	S := &mySparse{
+		rows:    M.getRows(),
+		columns: M.getColumns(),
+		indptr:  make([]int, M.getRows()+1),
+	}
+
+	for i := 0; i < M.getRows(); i++ {
+		for j := 0; j < M.getColumns(); j++ {
+			if x := M.getIJ(i, j); x != 0 {
+				S.indices = append(S.indices, j)
+				S.values = append(S.values, x)
+			}
+		}
+		S.indptr[i+1] = len(S.values)
+	}
+
+	return S
+}
+
+// toMatrix возвращает указатель на плотную матрицу (структуру myMatrix) с элементами float64,
+// равную разреженной матрице S.
+func (S *mySparse) toMatrix() *myMatrix {
+	M := zero(S.rows, S.columns)
+
+	for i := 0; i < S.rows; i++ {
+		for p := S.indptr[i]; p < S.indptr[i+1]; p++ {
+			M.setIJ(i, S.indices[p], S.values[p])
+		}
+	}
+
+	return M
+}
+
+// triplets возвращает тройки (строка, столбец, значение) хранимых элементов разреженной матрицы (структуры mySparse)
+// в порядке возрастания строк, а внутри строки столбцов.
+func (S *mySparse) triplets() ([]int, []int, []float64) {
+	rowIdx := make([]int, S.nnz())
+	colIdx := make([]int, S.nnz())
+	values := make([]float64, S.nnz())
+
+	for i := 0; i < S.rows; i++ {
+		for p := S.indptr[i]; p < S.indptr[i+1]; p++ {
+			rowIdx[p] = i
+		}
+	}
+	copy(colIdx, S.indices)
+	copy(values, S.values)
+
+	return rowIdx, colIdx, values
+}
+
+// nnz возвращает количество хранимых элементов разреженной матрицы (структуры mySparse).
+func (S *mySparse) nnz() int {
+	return len(S.values)
+}
+
+// getIJ возвращает элемент i строки, j столбца разреженной матрицы (структуры mySparse).
+func (S *mySparse) getIJ(i, j int) float64 {
+	row := S.indices[S.indptr[i]:S.indptr[i+1]]
+
+	p := sort.SearchInts(row, j)
+	if p < len(row) && row[p] == j {
+		return S.values[S.indptr[i]+p]
+	}
+	return 0
+}
+
+// t возвращает указатель на разреженную матрицу (структуру mySparse), транспонированную к S.
+// Количество операций пропорционально количеству ненулевых элементов и размерностям матрицы.
+func (S *mySparse) t() *mySparse {
+	T := &mySparse{
+		rows:    S.columns,
+		columns: S.rows,
+		indptr:  make([]int, S.columns+1),
+		indices: make([]int, S.nnz()),
+		values:  make([]float64, S.nnz()),
+	}
+
+	// количество элементов в каждом столбце S
+	for _, j := range S.indices {
+		T.indptr[j+1]++
+	}
+	for j := 0; j < T.rows; j++ {
+		T.indptr[j+1] += T.indptr[j]
+	}
+
+	// строки S обходятся по возрастанию, поэтому номера столбцов в строках T упорядочены
+	next := make([]int, T.rows)
+	copy(next, T.indptr[:T.rows])
+
+	for i := 0; i < S.rows; i++ {
+		for p := S.indptr[i]; p < S.indptr[i+1]; p++ {
+			j := S.indices[p]
+			T.indices[next[j]] = i
+			T.values[next[j]] = S.values[p]
+			next[j]++
+		}
+	}
+
+	return T
+}
+
+// scaleRows возвращает указатель на разреженную матрицу (структуру mySparse),
+// i строка которой равна i строке S, умноженной на i элемент вектора v (матрицы размерности rows на 1).
+// Метод вызывает панику, если размерность вектора не равна rows на 1.
+func (S *mySparse) scaleRows(v *myMatrix) *mySparse {
+	must(S.checkScaleRows(v))
+
+	res := &mySparse{
+		rows:    S.rows,
+		columns: S.columns,
+		indptr:  S.indptr,
+		indices: S.indices,
+		values:  make([]float64, S.nnz()),
+	}
+
+	for i := 0; i < S.rows; i++ {
+		k := v.getIJ(i, 0)
+		for p := S.indptr[i]; p < S.indptr[i+1]; p++ {
+			res.values[p] = S.values[p] * k
+		}
+	}
+
+	return res
+}
+
+// dot возвращает указатель на плотную матрицу (структуру myMatrix), равную произведению разреженной матрицы S
+// на плотную матрицу B. Тип элементов результата совпадает с типом элементов B.
+// Метод вызывает панику, если матрицы нельзя перемножить по определению.
+func (S *mySparse) dot(B *myMatrix) *myMatrix {
+	must(S.checkDot(B))
+
+	C := zeroOf(S.rows, B.getColumns(), B.dtype)
+	if B.dtype == Float32 {
+		sparseDense(S, B.dense32(), C.dense32())
+	} else {
+		sparseDense(S, B.dense64(), C.dense64())
+	}
+	debugCheck("Sparse.Dot", C)
+
+	return C
+}
+
+// dotSparse возвращает указатель на плотную матрицу (структуру myMatrix), равную произведению
+// плотной матрицы A на разреженную матрицу S. Тип элементов результата совпадает с типом элементов A.
+// Количество операций пропорционально количеству строк A, умноженному на количество ненулевых элементов S.
+// Метод вызывает панику, если матрицы нельзя перемножить по определению.
+func (A *myMatrix) dotSparse(S *mySparse) *myMatrix {
+	must(A.checkDotSparse(S, false))
+
+	C := zeroOf(A.getRows(), S.columns, A.dtype)
+	if A.dtype == Float32 {
+		denseSparse(A.dense32(), S, C.dense32(), false)
+	} else {
+		denseSparse(A.dense64(), S, C.dense64(), false)
+	}
+	debugCheck("DotSparse", C)
+
+	return C
+}
+
+// dotTSparse возвращает указатель на плотную матрицу (структуру myMatrix), равную произведению
+// плотной матрицы A на транспонированную разреженную матрицу S. Тип элементов результата совпадает с типом элементов A.
+// Метод вызывает панику, если количество столбцов A и S не совпадает.
+func (A *myMatrix) dotTSparse(S *mySparse) *myMatrix {
+	must(A.checkDotSparse(S, true))
+
+	C := zeroOf(A.getRows(), S.rows, A.dtype)
+	if A.dtype == Float32 {
+		denseSparse(A.dense32(), S, C.dense32(), true)
+	} else {
+		denseSparse(A.dense64(), S, C.dense64(), true)
+	}
+	debugCheck("DotTSparse", C)
+
+	return C
+}
+
+// sparseDense прибавляет к C произведение разреженной матрицы S на плотную матрицу B.
+func sparseDense[T float](S *mySparse, B, C dense[T]) {
+	for i := 0; i < S.rows; i++ {
+		rowC := C.row(i)
+
+		for p := S.indptr[i]; p < S.indptr[i+1]; p++ {
+			v := T(S.values[p])
+			rowB := B.row(S.indices[p])
+
+			for j := range rowC {
+				rowC[j] += v * rowB[j]
+			}
+		}
+	}
+}
+
+// denseSparse прибавляет к C произведение плотной матрицы A на разреженную матрицу S
+// (или на транспонированную S, если transS равен true).
+// Перебираются только ненулевые элементы S: элемент S[k, j] (или S[j, k] при transS)
+// прибавляет к столбцу j матрицы C столбец k матрицы A, умноженный на значение элемента.
+func denseSparse[T float](A dense[T], S *mySparse, C dense[T], transS bool) {
+	for r := 0; r < S.rows; r++ {
+		for p := S.indptr[r]; p < S.indptr[r+1]; p++ {
+			k, j := r, S.indices[p]
+			if transS {
+				k, j = j, k
+			}
+
+			v := T(S.values[p])
+			for i := 0; i < A.rows; i++ {
+				C.data[i*C.stride+j] += A.data[i*A.stride+k] * v
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package matrix
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// minParallelWork минимальный объем работы (количество обрабатываемых элементов),
+// начиная с которого операция распараллеливается.
+// Для матриц меньшего размера накладные расходы на передачу задач исполнителям превышают выигрыш.
+const minParallelWork = 1 << 14
+
+// workerPool представляет фиксированный набор горутин-исполнителей,
+// которые выполняют части параллельных операций над матрицами.
+// Горутина, запустившая операцию, сама является одним из исполнителей,
+// поэтому набор размера size запускает size-1 дополнительных горутин
+// и количество одновременно работающих над операцией горутин не превышает size.
+type workerPool struct {
+	size  int           // Количество исполнителей вместе с вызывающей горутиной
+	tasks chan poolTask // Небуферизованный канал задач
+	quit  chan struct{} // Закрывается при замене набора исполнителей
+}
+
+// poolTask представляет одну часть параллельной операции: вызов f(lo, hi).
+type poolTask struct {
+	f      func(lo, hi int)
+	lo, hi int
+	wg     *sync.WaitGroup
+}
+
+// pool текущий набор исполнителей, создается при первом обращении.
+var (
+	pool     atomic.Pointer[workerPool]
+	poolOnce sync.Once
+)
+
+// newWorkerPool создает и запускает набор из size исполнителей.
+func newWorkerPool(size int) *workerPool {
+	p := &workerPool{
+		size:  size,
+		tasks: make(chan poolTask),
+		quit:  make(chan struct{}),
+	}
+
+	for i := 0; i < size-1; i++ {
+		go p.work()
+	}
+
+	return p
+}
+
+// work цикл исполнителя: забирает задачи из канала, пока набор не будет заменен.
+func (p *workerPool) work() {
+	for {
+		select {
+		case t := <-p.tasks:
+			t.f(t.lo, t.hi)
+			t.wg.Done()
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// getPool возвращает текущий набор исполнителей, при необходимости создавая его
+// с размером runtime.GOMAXPROCS(0).
+func getPool() *workerPool {
+	poolOnce.Do(func() {
+		pool.Store(newWorkerPool(runtime.GOMAXPROCS(0)))
+	})
+	return pool.Load()
+}
+
+// SetNumWorkers устанавливает количество горутин-исполнителей, которые выполняют
+// параллельные операции над матрицами (произведение, поэлементные операции, транспонирование).
+// Если n не положительно, то используется значение runtime.GOMAXPROCS(0).
+// При n равном 1 все операции выполняются последовательно в вызывающей горутине.
+// Функция безопасна для вызова во время выполнения операций над матрицами.
+func SetNumWorkers(n int) {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+
+	getPool()
+	old := pool.Swap(newWorkerPool(n))
+	close(old.quit)
+}
+
+// NumWorkers возвращает текущее количество горутин-исполнителей операций над матрицами.
+func NumWorkers() int {
+	return getPool().size
+}
+
+// serialWork возвращает true, если операция над n частями с общим объемом работы work
+// выполняется parallelFor последовательно в текущей горутине.
+// Ядра проверяют это до вызова parallelFor, чтобы не создавать замыкание,
+// поэтому последовательные операции над небольшими матрицами не выделяют память.
+func serialWork(n, work int) bool {
+	return work < minParallelWork || n <= 1 || getPool().size <= 1
+}
+
+// parallelFor разбивает отрезок [0, n) на непересекающиеся части и
+// вызывает для каждой части функцию f(lo, hi).
+// work оценка общего объема работы, если она меньше minParallelWork,
+// то f вызывается один раз в текущей горутине.
+// Части раздаются свободным исполнителям из набора, если свободных исполнителей нет,
+// то часть выполняется в текущей горутине, поэтому вложенные вызовы не приводят к взаимной блокировке.
+func parallelFor(n, work int, f func(lo, hi int)) {
+	p := getPool()
+
+	parts := p.size
+	if parts > n {
+		parts = n
+	}
+
+	if work < minParallelWork || parts <= 1 {
+		f(0, n)
+		return
+	}
+
+	chunk := (n + parts - 1) / parts
+
+	wg := new(sync.WaitGroup)
+
+	// первую часть выполняет текущая горутина после раздачи остальных
+	for lo := chunk; lo < n; lo += chunk {
+		hi := lo + chunk
+		if hi > n {
+			hi = n
+		}
+
+		wg.Add(1)
+		select {
+		case p.tasks <- poolTask{f: f, lo: lo, hi: hi, wg: wg}:
+		default:
+			f(lo, hi)
+			wg.Done()
+		}
+	}
+
+	f(0, chunk)
+
+	wg.Wait()
+}
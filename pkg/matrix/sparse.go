@@ -0,0 +1,117 @@
+package matrix
+
+// Sparse является структурой оболочкой над собственной реализацией разреженных матриц в формате CSR.
+// Разреженная матрица хранит только ненулевые элементы с элементами float64
+// и предназначена для входных данных, большая часть которых равна нулю
+// (мешок слов, one-hot кодирование категориальных признаков, изображения MNIST).
+// Разреженная матрица не изменяется после создания.
+type Sparse struct {
+	matrix *mySparse // Указатель на собственную реализацию структуры разреженных матриц mySparse
+}
+
+// SparseFromTriplets возвращает разреженную матрицу (структуру Sparse) размерности rows на columns
+// и ошибку.
+// Ненулевые элементы задаются тройками: rowIdx[n] строка, colIdx[n] столбец и values[n] значение n-го элемента.
+// Значения повторяющихся пар индексов суммируются.
+// Функция возвращает ошибку, если размерности не положительны, слайсы разной длины
+// или индексы выходят за пределы матрицы.
+func SparseFromTriplets(rows, columns int, rowIdx, colIdx []int, values []float64) (Sparse, error) {
+	S, err := sparseFromTriplets(rows, columns, rowIdx, colIdx, values)
+	if err != nil {
+		return Sparse{}, err
+	}
+
+	return Sparse{
+		matrix: S,
+	}, nil
+}
+
+// SparseFromMatrix возвращает разреженную матрицу (структуру Sparse), содержащую все ненулевые элементы матрицы M.
+func SparseFromMatrix(M Matrix) Sparse {
+	return Sparse{
+		matrix: sparseFromMatrix(M.matrix),
+	}
+}
+
+// ToMatrix возвращает плотную матрицу (структуру Matrix) с элементами float64, равную разреженной матрице S.
+func (S Sparse) ToMatrix() Matrix {
+	return Matrix{
+		matrix: S.matrix.toMatrix(),
+	}
+}
+
+// GetRows возвращает количество строк разреженной матрицы (структуры Sparse).
+func (S Sparse) GetRows() int {
+	return S.matrix.rows
+}
+
+// GetColumns возвращает количество столбцов разреженной матрицы (структуры Sparse).
+func (S Sparse) GetColumns() int {
+	return S.matrix.columns
+}
+
+// NNZ возвращает количество хранимых (ненулевых) элементов разреженной матрицы (структуры Sparse).
+func (S Sparse) NNZ() int {
+	return S.matrix.nnz()
+}
+
+// Triplets возвращает тройки хранимых элементов разреженной матрицы (структуры Sparse):
+// строки, столбцы и значения элементов в порядке возрастания строк, а внутри строки столбцов.
+// Возвращаемые слайсы не разделяют память с матрицей.
+func (S Sparse) Triplets() ([]int, []int, []float64) {
+	return S.matrix.triplets()
+}
+
+// GetIJ возвращает элемент i строки, j столбца разреженной матрицы (структуры Sparse).
+func (S Sparse) GetIJ(i, j int) float64 {
+	return S.matrix.getIJ(i, j)
+}
+
+// T возвращает разреженную матрицу (структуру Sparse), транспонированную к S.
+func (S Sparse) T() Sparse {
+	return Sparse{
+		matrix: S.matrix.t(),
+	}
+}
+
+// ScaleRows возвращает разреженную матрицу (структуру Sparse), i строка которой равна i строке S,
+// умноженной на i элемент вектора v (матрицы размерности n на 1, где n количество строк S).
+// Для вектора признаков (разреженной матрицы n на 1) это адамарное произведение на v.
+// Метод вызывает панику, если размерность вектора v не равна n на 1.
+func (S Sparse) ScaleRows(v Matrix) Sparse {
+	return Sparse{
+		matrix: S.matrix.scaleRows(v.matrix),
+	}
+}
+
+// Dot возвращает экземпляр Matrix.
+// Возвращаемая плотная матрица является результатом произведения разреженной матрицы S на плотную матрицу B,
+// тип ее элементов совпадает с типом элементов B.
+// Метод вызывает панику, если исходные матрицы нельзя перемножить по определению.
+func (S Sparse) Dot(B Matrix) Matrix {
+	return Matrix{
+		matrix: S.matrix.dot(B.matrix),
+	}
+}
+
+// DotSparse возвращает экземпляр Matrix.
+// Возвращаемая матрица является результатом произведения плотной матрицы A на разреженную матрицу S,
+// тип ее элементов совпадает с типом элементов A.
+// Перебираются только ненулевые элементы S, поэтому произведение матрицы весов на разреженный вектор признаков
+// не требует преобразования вектора в плотную матрицу.
+// Метод вызывает панику, если исходные матрицы нельзя перемножить по определению.
+func (A Matrix) DotSparse(S Sparse) Matrix {
+	return Matrix{
+		matrix: A.matrix.dotSparse(S.matrix),
+	}
+}
+
+// DotTSparse возвращает экземпляр Matrix.
+// Возвращаемая матрица является результатом произведения плотной матрицы A на транспонированную разреженную матрицу S,
+// то есть совпадает с A.DotSparse(S.T()), тип ее элементов совпадает с типом элементов A.
+// Метод вызывает панику, если количество столбцов матриц A и S не совпадает.
+func (A Matrix) DotTSparse(S Sparse) Matrix {
+	return Matrix{
+		matrix: A.matrix.dotTSparse(S.matrix),
+	}
+}
@@ -0,0 +1,153 @@
+package matrix
+
+import "errors"
+
+/*
+Сингулярное разложение, разложение симметричной матрицы по собственным векторам,
+псевдообратная матрица и ранг.
+Вычисления производятся в float64 для любого типа элементов,
+возвращаемые матрицы имеют тип элементов исходной матрицы.
+Сингулярное число считается нулевым, если оно не превышает
+max(m, n) * машинный эпсилон * наибольшее сингулярное число.
+*/
+
+// ErrNoConvergence возвращается, если итерационный метод не сошелся.
+// Обычно это означает, что матрица содержит NaN или Inf.
+var ErrNoConvergence = errors.New("matrix decomposition did not converge")
+
+// SVD хранит сингулярное разложение матрицы A размерности m на n: A = U * Sigma * V^T,
+// где U и V матрицы с ортонормированными столбцами, Sigma диагональная матрица сингулярных чисел по убыванию.
+type SVD struct {
+	svd *mySVD // Указатель на собственную реализацию разложения
+}
+
+// SVD возвращает тонкое сингулярное разложение (структуру SVD) матрицы A и ошибку.
+// U имеет размерность m на k, Sigma k на k, V n на k, где k = min(m, n).
+// Метод возвращает ErrNoConvergence, если разложение не удалось вычислить.
+func (A Matrix) SVD() (SVD, error) {
+	return A.svd(false)
+}
+
+// FullSVD возвращает полное сингулярное разложение (структуру SVD) матрицы A и ошибку.
+// U имеет размерность m на m, Sigma m на n, V n на n.
+// Метод возвращает ErrNoConvergence, если разложение не удалось вычислить.
+func (A Matrix) FullSVD() (SVD, error) {
+	return A.svd(true)
+}
+
+// svd возвращает тонкое или полное (при full равном true) сингулярное разложение матрицы A и ошибку.
+func (A Matrix) svd(full bool) (SVD, error) {
+	svd, ok := svdDecompose(A.matrix, full)
+	if !ok {
+		return SVD{}, ErrNoConvergence
+	}
+
+	return SVD{
+		svd: svd,
+	}, nil
+}
+
+// U возвращает матрицу левых сингулярных векторов (по столбцам).
+func (f SVD) U() Matrix {
+	return Matrix{matrix: f.svd.u.asType(f.svd.dtype)}
+}
+
+// V возвращает матрицу правых сингулярных векторов (по столбцам).
+func (f SVD) V() Matrix {
+	return Matrix{matrix: f.svd.v.asType(f.svd.dtype)}
+}
+
+// Values возвращает вектор размерности k на 1 сингулярных чисел по убыванию, где k = min(m, n).
+func (f SVD) Values() Matrix {
+	S := zeroOf(len(f.svd.s), 1, f.svd.dtype)
+	for i, s := range f.svd.s {
+		S.setIJ(i, 0, s)
+	}
+
+	return Matrix{matrix: S}
+}
+
+// Sigma возвращает диагональную матрицу сингулярных чисел такой размерности,
+// что произведение U * Sigma * V^T равно исходной матрице.
+func (f SVD) Sigma() Matrix {
+	rows, columns := f.svd.u.columns, f.svd.v.columns
+	S := zeroOf(rows, columns, f.svd.dtype)
+	for i, s := range f.svd.s {
+		S.setIJ(i, i, s)
+	}
+
+	return Matrix{matrix: S}
+}
+
+// Rank возвращает ранг исходной матрицы: количество ненулевых сингулярных чисел.
+func (f SVD) Rank() int {
+	return f.svd.rank()
+}
+
+// Pinv возвращает псевдообратную матрицу Мура-Пенроуза для исходной матрицы размерности n на m.
+func (f SVD) Pinv() Matrix {
+	return Matrix{matrix: f.svd.pinv().asType(f.svd.dtype)}
+}
+
+// Rank возвращает ранг матрицы A, вычисленный по сингулярному разложению, и ошибку.
+// Метод возвращает ErrNoConvergence, если разложение не удалось вычислить.
+func (A Matrix) Rank() (int, error) {
+	f, err := A.SVD()
+	if err != nil {
+		return 0, err
+	}
+	return f.Rank(), nil
+}
+
+// Pinv возвращает псевдообратную матрицу Мура-Пенроуза для матрицы A и ошибку.
+// Для обратимой матрицы результат совпадает с обратной матрицей,
+// для матрицы с линейно независимыми столбцами X = Pinv(A) * B решение задачи наименьших квадратов.
+// Метод возвращает ErrNoConvergence, если сингулярное разложение не удалось вычислить.
+func (A Matrix) Pinv() (Matrix, error) {
+	f, err := A.SVD()
+	if err != nil {
+		return Matrix{}, err
+	}
+	return f.Pinv(), nil
+}
+
+// EigenSym хранит разложение симметричной матрицы A по собственным векторам: A = V * diag(values) * V^T,
+// где V ортогональная матрица собственных векторов.
+type EigenSym struct {
+	eig *myEigenSym // Указатель на собственную реализацию разложения
+}
+
+// EigenSym возвращает разложение симметричной матрицы A по собственным векторам (структуру EigenSym) и ошибку.
+// Используются только элементы на и под диагональю A, симметричность не проверяется.
+// Метод возвращает *ShapeError, если матрица A не является квадратной,
+// и ErrNoConvergence, если разложение не удалось вычислить.
+func (A Matrix) EigenSym() (EigenSym, error) {
+	if err := checkSquare("EigenSym", A.matrix); err != nil {
+		return EigenSym{}, err
+	}
+
+	eig, ok := eigenSymDecompose(A.matrix)
+	if !ok {
+		return EigenSym{}, ErrNoConvergence
+	}
+
+	return EigenSym{
+		eig: eig,
+	}, nil
+}
+
+// Values возвращает вектор размерности n на 1 собственных значений по возрастанию.
+func (f EigenSym) Values() Matrix {
+	E := zeroOf(len(f.eig.values), 1, f.eig.dtype)
+	for i, e := range f.eig.values {
+		E.setIJ(i, 0, e)
+	}
+
+	return Matrix{matrix: E}
+}
+
+// Vectors возвращает ортогональную матрицу собственных векторов (по столбцам),
+// i столбец соответствует i собственному значению.
+func (f EigenSym) Vectors() Matrix {
+	return Matrix{matrix: f.eig.vectors.asType(f.eig.dtype)}
+}
@@ -0,0 +1,62 @@
+package matrix
+
+// Workspace хранит набор матриц для повторного использования.
+// Матрица, полученная методом Get, принадлежит вызывающему до вызова Reset,
+// после чего возвращается в набор и может быть выдана снова с той же размерностью и типом элементов.
+// Когда набор заполнен матрицами всех нужных размерностей, Get и Reset не выделяют память,
+// поэтому Workspace вместе с операциями Into позволяет повторять вычисления без выделения памяти.
+// Workspace не предназначен для одновременного использования из нескольких горутин.
+type Workspace struct {
+	free map[workspaceKey][]*myMatrix // Свободные матрицы по размерности и типу элементов
+	used []*myMatrix                  // Матрицы, выданные с последнего вызова Reset
+}
+
+// workspaceKey размерность и тип элементов матриц набора.
+type workspaceKey struct {
+	rows    int
+	columns int
+	dtype   DType
+}
+
+// NewWorkspace возвращает указатель на пустой набор матриц (структуру Workspace).
+func NewWorkspace() *Workspace {
+	return &Workspace{
+		free: make(map[workspaceKey][]*myMatrix),
+	}
+}
+
+// Get возвращает матрицу (структуру Matrix) размерности rows на columns с типом элементов dtype.
+// Матрица берется из набора, если в нем есть свободная матрица такой размерности и типа элементов,
+// иначе создается новая.
+// Элементы матрицы не определены (могут остаться от прошлого использования),
+// поэтому она предназначена для записи результата операций Into или должна быть заполнена методом Fill.
+// Метод вызывает панику, если указанные размеры матрицы не являются положительными числами.
+func (ws *Workspace) Get(rows, columns int, dtype DType) Matrix {
+	key := workspaceKey{rows: rows, columns: columns, dtype: dtype}
+
+	var M *myMatrix
+	if free := ws.free[key]; len(free) > 0 {
+		M = free[len(free)-1]
+		ws.free[key] = free[:len(free)-1]
+	} else {
+		M = zeroOf(rows, columns, dtype)
+	}
+
+	ws.used = append(ws.used, M)
+
+	return Matrix{
+		matrix: M,
+	}
+}
+
+// Reset возвращает в набор все матрицы, выданные методом Get с последнего вызова Reset.
+// После вызова Reset эти матрицы не должны использоваться.
+func (ws *Workspace) Reset() {
+	for i, M := range ws.used {
+		key := workspaceKey{rows: M.rows, columns: M.columns, dtype: M.dtype}
+		ws.free[key] = append(ws.free[key], M)
+		ws.used[i] = nil
+	}
+
+	ws.used = ws.used[:0]
+}
@@ -3,55 +3,475 @@ package neural_network
 // файл содержит функции активации
 
 import (
+	"errors"
+	"fmt"
 	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
 
+	"github.com/Davmgiz/GoblinNeuronet/pkg/autodiff"
 	"github.com/Davmgiz/GoblinNeuronet/pkg/matrix"
 )
 
-// activationFunc интерфейс для функций активации.
+// Activation интерфейс для функций активации.
 // Функция активации должна иметь еще производную.
-type activationFunc interface {
-	fnc(z float64) float64                        // сама функция активации
-	prime(z float64) float64                      // производная функции активации
-	getName() string                              // имя функции активации, которое используется при записи параметров нейронной сети
-	getDelta(z, a, y matrix.Matrix) matrix.Matrix // ошибка на выходном слое
+// Чтобы нейронную сеть с пользовательской функцией активации можно было записать и прочитать,
+// функция активации регистрируется под своим именем без пробельных символов (см. RegisterActivation).
+// Встроенная функция активации Softmax применяется ко всему вектору слоя, а не поэлементно,
+// поэтому ее методы Fnc и Prime не имеют смысла и всегда возвращают NaN (см. Softmax).
+type Activation interface {
+	Fnc(z float64) float64   // сама функция активации
+	Prime(z float64) float64 // производная функции активации
+	GetName() string         // имя функции активации, которое используется при записи параметров нейронной сети
 }
 
-// nameToActFunc возвращает интерфейс activationFunc.
-// Функция возвращает функцию активации соответствующую принимаемому имени.
-// Функция вызывает панику если переданному имени не соответствует никакая функция активации.
-func nameToActFunc(name string) activationFunc {
-	name2ActFunc := make(map[string]activationFunc)
+// vectorActivation функция активации, значение которой на каждом нейроне зависит от взвешенных сумм
+// всех нейронов слоя, поэтому она применяется ко всему вектору слоя, а не поэлементно (Fnc и Prime не используются).
+type vectorActivation interface {
+	Activation
+	apply(dst, z matrix.Matrix)                     // записывает в dst активацию для вектора z, dst может совпадать с z
+	backward(dst, z, delta matrix.Matrix)           // записывает в dst произведение delta на матрицу Якоби в точке z, dst может совпадать с delta
+	variable(z autodiff.Variable) autodiff.Variable // записывает функцию активации на ленту автоматического дифференцирования
+}
+
+// activate записывает в dst активацию actFunc для вектора взвешенных сумм z,
+// fnc функция активации actFunc.Fnc, сохраненная заранее, чтобы не создавать значение метода на каждом вызове.
+// dst может совпадать с z.
+func activate(dst, z matrix.Matrix, actFunc Activation, fnc func(float64) float64) {
+	if v, ok := actFunc.(vectorActivation); ok {
+		v.apply(dst, z)
+		return
+	}
+	matrix.ForEachInto(dst, z, fnc)
+}
+
+// ErrUnknownActivation ошибка чтения нейронной сети с функцией активации, не зарегистрированной RegisterActivation.
+var ErrUnknownActivation = errors.New("activation function not registered")
+
+// Реестр функций активации: функции активации без параметров по полному имени
+// и функции активации с параметрами по имени без параметров.
+var (
+	activationsMu        sync.RWMutex
+	activations          = make(map[string]func() Activation)
+	parametricActivation = make(map[string]func(params []float64) (Activation, error))
+)
+
+// init регистрирует встроенные функции активации.
+func init() {
+	RegisterActivation("Sigmoid", func() Activation { return Sigmoid{} })
+	RegisterActivation("ReLU", func() Activation { return ReLU{} })
+	RegisterActivation("Tanh", func() Activation { return Tanh{} })
+	RegisterActivation("SELU", func() Activation { return SELU{} })
+	RegisterActivation("GELU", func() Activation { return GELU{} })
+	RegisterActivation("Swish", func() Activation { return Swish{} })
+	RegisterActivation("Softplus", func() Activation { return Softplus{} })
+	RegisterActivation("Identity", func() Activation { return Identity{} })
+	RegisterActivation("Softmax", func() Activation { return Softmax{} })
+
+	RegisterParametricActivation("LeakyReLU", func(params []float64) (Activation, error) {
+		if len(params) != 1 {
+			return nil, fmt.Errorf("LeakyReLU expects 1 parameter, got %d", len(params))
+		}
+		return LeakyReLU{Slope: params[0]}, nil
+	})
+	RegisterParametricActivation("ELU", func(params []float64) (Activation, error) {
+		if len(params) != 1 {
+			return nil, fmt.Errorf("ELU expects 1 parameter, got %d", len(params))
+		}
+		return ELU{Alpha: params[0]}, nil
+	})
+}
+
+// RegisterActivation добавляет в реестр функцию активации с именем name, которую создает factory.
+// Зарегистрированные функции активации используются NewNeuralNetworkByName и функциями чтения нейронной сети.
+// Функция вызывает панику, если функция активации с таким именем уже зарегистрирована,
+// имя пустое или содержит пробельные символы (в текстовом формате имена разделяются пробелами),
+// factory равна nil или имя созданной функции активации (GetName) не равно name.
+func RegisterActivation(name string, factory func() Activation) {
+	checkActivationSyntax(name)
+	if factory == nil {
+		panic(fmt.Sprintf("nil factory for activation function %q", name))
+	}
+	if got := factory().GetName(); got != name {
+		panic(fmt.Sprintf("activation function registered as %q has name %q", name, got))
+	}
+
+	activationsMu.Lock()
+	defer activationsMu.Unlock()
+
+	checkActivationName(name)
+	activations[name] = factory
+}
+
+// RegisterParametricActivation добавляет в реестр функцию активации с параметрами,
+// имя которой (GetName) имеет вид name(p1,p2,...), например "LeakyReLU(0.01)".
+// factory создает функцию активации по прочитанным параметрам и возвращает ошибку, если они некорректны.
+// Функция вызывает панику, если функция активации с таким именем уже зарегистрирована,
+// factory равна nil, имя пустое, содержит пробельные символы или скобки.
+func RegisterParametricActivation(name string, factory func(params []float64) (Activation, error)) {
+	checkActivationSyntax(name)
+	if factory == nil {
+		panic(fmt.Sprintf("nil factory for activation function %q", name))
+	}
+	if strings.ContainsAny(name, "()") {
+		panic(fmt.Sprintf("incorrect name of parametric activation function %q", name))
+	}
+
+	activationsMu.Lock()
+	defer activationsMu.Unlock()
+
+	checkActivationName(name)
+	parametricActivation[name] = factory
+}
+
+// checkActivationSyntax вызывает панику, если имя функции активации name пустое или содержит пробельные символы:
+// такое имя нельзя прочитать из текстового формата, где имена функций активации слоев разделяются пробелами.
+func checkActivationSyntax(name string) {
+	if name == "" || strings.IndexFunc(name, unicode.IsSpace) >= 0 {
+		panic(fmt.Sprintf("incorrect name of activation function %q: name must be non-empty and contain no whitespace", name))
+	}
+}
+
+// checkActivationName вызывает панику, если функция активации с именем name уже зарегистрирована.
+// Вызывается при заблокированном реестре.
+func checkActivationName(name string) {
+	_, plain := activations[name]
+	_, parametric := parametricActivation[name]
+	if plain || parametric {
+		panic(fmt.Sprintf("activation function %q already registered", name))
+	}
+}
+
+// parametricName возвращает имя функции активации или функции потерь с параметрами params в виде name(p1,p2,...).
+// Параметры записываются без потери точности.
+func parametricName(name string, params ...float64) string {
+	s := make([]string, len(params))
+	for i, p := range params {
+		s[i] = strconv.FormatFloat(p, 'g', -1, 64)
+	}
+	return name + "(" + strings.Join(s, ",") + ")"
+}
+
+// NewActivation возвращает зарегистрированную функцию активации с именем name
+// и ошибку, оборачивающую ErrUnknownActivation, если функция активации с таким именем не зарегистрирована.
+// Для функций активации с параметрами имя имеет вид name(p1,p2,...) (см. RegisterParametricActivation),
+// функция возвращает ошибку, если параметры некорректны.
+func NewActivation(name string) (Activation, error) {
+	base, args, parametric := strings.Cut(name, "(")
+
+	activationsMu.RLock()
+	factory, ok := activations[name]
+	parametricFactory, okParametric := parametricActivation[base]
+	activationsMu.RUnlock()
+
+	if ok {
+		return factory(), nil
+	}
+	if !parametric || !okParametric {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownActivation, name)
+	}
 
-	name2ActFunc["Sigmoid"] = Sigmoid{}
+	// параметры перечисляются через запятую в скобках после имени
+	if !strings.HasSuffix(args, ")") {
+		return nil, fmt.Errorf("incorrect parameters of activation function %q", name)
+	}
+	var params []float64
+	if args = strings.TrimSuffix(args, ")"); args != "" {
+		for _, arg := range strings.Split(args, ",") {
+			p, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				return nil, fmt.Errorf("incorrect parameters of activation function %q: %v", name, err)
+			}
+			params = append(params, p)
+		}
+	}
 
-	actFunc, ok := name2ActFunc[name]
-	if !ok {
-		panic("Activation function not defined")
+	return parametricFactory(params)
+}
+
+// Activations возвращает отсортированные имена зарегистрированных функций активации,
+// для функций активации с параметрами имена без параметров.
+func Activations() []string {
+	activationsMu.RLock()
+	defer activationsMu.RUnlock()
+
+	names := make([]string, 0, len(activations)+len(parametricActivation))
+	for name := range activations {
+		names = append(names, name)
 	}
-	return actFunc
+	for name := range parametricActivation {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
 }
 
-// Sigmoid структура имплементирующая интерфейс activationFunc.
+// Sigmoid структура имплементирующая интерфейс Activation.
 type Sigmoid struct {
 }
 
-// fnc возвращает результат функции активации.
-func (s Sigmoid) fnc(z float64) float64 {
+// Fnc возвращает результат функции активации.
+func (s Sigmoid) Fnc(z float64) float64 {
 	return 1. / (1. + math.Exp(-z))
 }
 
-// prime возвращает результат производной функции активации.
-func (s Sigmoid) prime(z float64) float64 {
-	return s.fnc(z) * (1. - s.fnc(z))
+// Prime возвращает результат производной функции активации.
+func (s Sigmoid) Prime(z float64) float64 {
+	return s.Fnc(z) * (1. - s.Fnc(z))
 }
 
-// getName возвращает имя функции активации.
-func (s Sigmoid) getName() string {
+// GetName возвращает имя функции активации.
+func (s Sigmoid) GetName() string {
 	return "Sigmoid"
 }
 
-// getDelta возвращает ошибку на выходном слое.
-func (s Sigmoid) getDelta(z, a, y matrix.Matrix) matrix.Matrix {
-	return a.Sub(y)
+// ReLU структура имплементирующая интерфейс Activation: max(0, z).
+// Производная в нуле считается равной 0.
+type ReLU struct {
+}
+
+// Fnc возвращает результат функции активации.
+func (r ReLU) Fnc(z float64) float64 {
+	if z > 0 {
+		return z
+	}
+	return 0
+}
+
+// Prime возвращает результат производной функции активации.
+func (r ReLU) Prime(z float64) float64 {
+	if z > 0 {
+		return 1
+	}
+	return 0
+}
+
+// GetName возвращает имя функции активации.
+func (r ReLU) GetName() string {
+	return "ReLU"
+}
+
+// LeakyReLU структура имплементирующая интерфейс Activation: z при z > 0 и Slope * z иначе.
+// Наклон записывается в имени функции активации, например "LeakyReLU(0.01)".
+type LeakyReLU struct {
+	Slope float64 // Наклон при отрицательных z, обычно 0.01
+}
+
+// Fnc возвращает результат функции активации.
+func (r LeakyReLU) Fnc(z float64) float64 {
+	if z > 0 {
+		return z
+	}
+	return r.Slope * z
+}
+
+// Prime возвращает результат производной функции активации.
+func (r LeakyReLU) Prime(z float64) float64 {
+	if z > 0 {
+		return 1
+	}
+	return r.Slope
+}
+
+// GetName возвращает имя функции активации вместе с наклоном.
+func (r LeakyReLU) GetName() string {
+	return parametricName("LeakyReLU", r.Slope)
+}
+
+// Tanh структура имплементирующая интерфейс Activation: гиперболический тангенс.
+type Tanh struct {
+}
+
+// Fnc возвращает результат функции активации.
+func (t Tanh) Fnc(z float64) float64 {
+	return math.Tanh(z)
+}
+
+// Prime возвращает результат производной функции активации.
+func (t Tanh) Prime(z float64) float64 {
+	th := math.Tanh(z)
+	return 1 - th*th
+}
+
+// GetName возвращает имя функции активации.
+func (t Tanh) GetName() string {
+	return "Tanh"
+}
+
+// ELU структура имплементирующая интерфейс Activation: z при z > 0 и Alpha * (e^z - 1) иначе.
+// Параметр записывается в имени функции активации, например "ELU(1)".
+type ELU struct {
+	Alpha float64 // Предел функции при z, стремящемся к минус бесконечности, со знаком минус, обычно 1
+}
+
+// Fnc возвращает результат функции активации.
+func (e ELU) Fnc(z float64) float64 {
+	if z > 0 {
+		return z
+	}
+	return e.Alpha * math.Expm1(z)
+}
+
+// Prime возвращает результат производной функции активации.
+func (e ELU) Prime(z float64) float64 {
+	if z > 0 {
+		return 1
+	}
+	return e.Alpha * math.Exp(z)
+}
+
+// GetName возвращает имя функции активации вместе с параметром.
+func (e ELU) GetName() string {
+	return parametricName("ELU", e.Alpha)
+}
+
+// Константы SELU (Klambauer et al., 2017), при которых активации сохраняют нулевое среднее и единичную дисперсию.
+const (
+	seluAlpha = 1.6732632423543772848170429916717
+	seluScale = 1.0507009873554804934193349852946
+)
+
+// SELU структура имплементирующая интерфейс Activation: масштабированная ELU с фиксированными параметрами.
+type SELU struct {
+}
+
+// Fnc возвращает результат функции активации.
+func (s SELU) Fnc(z float64) float64 {
+	return seluScale * ELU{Alpha: seluAlpha}.Fnc(z)
+}
+
+// Prime возвращает результат производной функции активации.
+func (s SELU) Prime(z float64) float64 {
+	return seluScale * ELU{Alpha: seluAlpha}.Prime(z)
+}
+
+// GetName возвращает имя функции активации.
+func (s SELU) GetName() string {
+	return "SELU"
+}
+
+// GELU структура имплементирующая интерфейс Activation: z * Φ(z), где Φ функция распределения
+// стандартного нормального распределения (точная формула без приближения tanh).
+type GELU struct {
+}
+
+// Fnc возвращает результат функции активации.
+func (g GELU) Fnc(z float64) float64 {
+	return 0.5 * z * (1 + math.Erf(z/math.Sqrt2))
+}
+
+// Prime возвращает результат производной функции активации: Φ(z) + z * φ(z).
+func (g GELU) Prime(z float64) float64 {
+	return 0.5*(1+math.Erf(z/math.Sqrt2)) + z*math.Exp(-0.5*z*z)/math.Sqrt(2*math.Pi)
+}
+
+// GetName возвращает имя функции активации.
+func (g GELU) GetName() string {
+	return "GELU"
+}
+
+// Swish структура имплементирующая интерфейс Activation: z * sigmoid(z) (SiLU).
+type Swish struct {
+}
+
+// Fnc возвращает результат функции активации.
+func (s Swish) Fnc(z float64) float64 {
+	return z * Sigmoid{}.Fnc(z)
+}
+
+// Prime возвращает результат производной функции активации: sigmoid(z) * (1 + z * (1 - sigmoid(z))).
+func (s Swish) Prime(z float64) float64 {
+	sg := Sigmoid{}.Fnc(z)
+	return sg * (1 + z*(1-sg))
+}
+
+// GetName возвращает имя функции активации.
+func (s Swish) GetName() string {
+	return "Swish"
+}
+
+// Softplus структура имплементирующая интерфейс Activation: ln(1 + e^z).
+// Вычисляется без переполнения при больших z.
+type Softplus struct {
+}
+
+// Fnc возвращает результат функции активации.
+func (s Softplus) Fnc(z float64) float64 {
+	if z > 0 {
+		return z + math.Log1p(math.Exp(-z))
+	}
+	return math.Log1p(math.Exp(z))
+}
+
+// Prime возвращает результат производной функции активации, равной сигмоиде.
+func (s Softplus) Prime(z float64) float64 {
+	return Sigmoid{}.Fnc(z)
+}
+
+// GetName возвращает имя функции активации.
+func (s Softplus) GetName() string {
+	return "Softplus"
+}
+
+// Identity структура имплементирующая интерфейс Activation: тождественная функция,
+// например для выходного слоя в задачах регрессии.
+type Identity struct {
+}
+
+// Fnc возвращает результат функции активации.
+func (i Identity) Fnc(z float64) float64 {
+	return z
+}
+
+// Prime возвращает результат производной функции активации.
+func (i Identity) Prime(z float64) float64 {
+	return 1
+}
+
+// GetName возвращает имя функции активации.
+func (i Identity) GetName() string {
+	return "Identity"
+}
+
+// Softmax структура имплементирующая интерфейс Activation: exp(z_i) / Σ exp(z_k) по нейронам слоя.
+// Выход слоя является распределением вероятностей классов, поэтому Softmax используется на выходном слое
+// в задачах многоклассовой классификации вместе с CategoricalCrossEntropy (функцией потерь по умолчанию для Softmax).
+// Softmax применяется ко всему вектору слоя, поэтому поэлементные методы Fnc и Prime не определены
+// и всегда возвращают NaN, нейронная сеть вычисляет Softmax и ее производную по всему слою.
+// Вне нейронной сети softmax вектора вычисляется методом matrix.Matrix.Softmax.
+type Softmax struct {
+}
+
+// Fnc всегда возвращает NaN, так как Softmax не является поэлементной функцией активации.
+func (s Softmax) Fnc(z float64) float64 {
+	return math.NaN()
+}
+
+// Prime всегда возвращает NaN, так как Softmax не является поэлементной функцией активации.
+func (s Softmax) Prime(z float64) float64 {
+	return math.NaN()
+}
+
+// GetName возвращает имя функции активации.
+func (s Softmax) GetName() string {
+	return "Softmax"
+}
+
+// apply записывает в dst softmax вектора z (см. matrix.SoftmaxInto).
+func (s Softmax) apply(dst, z matrix.Matrix) {
+	matrix.SoftmaxInto(dst, z)
+}
+
+// backward записывает в dst произведение delta на матрицу Якоби softmax в точке z (см. matrix.SoftmaxJVPInto).
+func (s Softmax) backward(dst, z, delta matrix.Matrix) {
+	matrix.SoftmaxJVPInto(dst, z, delta)
+}
+
+// variable записывает softmax на ленту автоматического дифференцирования.
+func (s Softmax) variable(z autodiff.Variable) autodiff.Variable {
+	return z.Softmax()
 }
@@ -0,0 +1,141 @@
+package neural_network
+
+// файл содержит представления вектора признаков, подаваемого на входной слой
+
+import (
+	"github.com/Davmgiz/GoblinNeuronet/pkg/autodiff"
+	"github.com/Davmgiz/GoblinNeuronet/pkg/data_frame"
+	"github.com/Davmgiz/GoblinNeuronet/pkg/matrix"
+)
+
+// input интерфейс вектора признаков, подаваемого на входной слой нейронной сети.
+// Плотный и разреженный векторы отличаются только вычислениями на первом слое,
+// остальные слои всегда работают с плотными матрицами.
+type input interface {
+	getRows() int                                 // количество признаков
+	getColumns() int                              // количество столбцов, для вектора равно 1
+	asType(dtype matrix.DType) input              // вектор признаков для вычислений с типом элементов dtype
+	normalize(norm matrix.Matrix) (input, error)  // вектор признаков, умноженный поэлементно на вектор нормализации
+	weigh(w matrix.Matrix) (matrix.Matrix, error) // произведение матрицы весов первого слоя на вектор признаков
+	weighInto(dst, w matrix.Matrix)               // записывает в dst произведение матрицы весов первого слоя на вектор признаков
+	outerInto(dst, delta matrix.Matrix)           // записывает в dst произведение ошибки первого слоя на транспонированный вектор признаков
+
+	// произведение переменной весов первого слоя на вектор признаков, записанное на ленту tape
+	weighVariable(tape *autodiff.Tape, w autodiff.Variable) autodiff.Variable
+}
+
+// denseInput плотный вектор признаков (матрица размерности n на 1).
+type denseInput struct {
+	x matrix.Matrix
+}
+
+// getRows возвращает количество признаков.
+func (in denseInput) getRows() int {
+	return in.x.GetRows()
+}
+
+// getColumns возвращает количество столбцов вектора признаков.
+func (in denseInput) getColumns() int {
+	return in.x.GetColumns()
+}
+
+// asType возвращает вектор признаков, приведенный к типу элементов dtype.
+// Если тип элементов совпадает, то возвращается исходный вектор без копирования.
+func (in denseInput) asType(dtype matrix.DType) input {
+	if in.x.DType() == dtype {
+		return in
+	}
+	return denseInput{x: in.x.AsType(dtype)}
+}
+
+// normalize умножает вектор признаков на вектор нормализации, изменяя его.
+func (in denseInput) normalize(norm matrix.Matrix) (input, error) {
+	return in, in.x.TryHadamardProductInPlace(norm)
+}
+
+// weigh возвращает произведение матрицы весов на вектор признаков.
+func (in denseInput) weigh(w matrix.Matrix) (matrix.Matrix, error) {
+	return w.TryDot(in.x)
+}
+
+// weighInto записывает в dst произведение матрицы весов на вектор признаков.
+func (in denseInput) weighInto(dst, w matrix.Matrix) {
+	matrix.DotInto(dst, w, in.x)
+}
+
+// outerInto записывает в dst произведение ошибки на транспонированный вектор признаков.
+func (in denseInput) outerInto(dst, delta matrix.Matrix) {
+	matrix.DotTInto(dst, delta, in.x)
+}
+
+// weighVariable возвращает переменную ленты, равную произведению переменной весов на вектор признаков.
+func (in denseInput) weighVariable(tape *autodiff.Tape, w autodiff.Variable) autodiff.Variable {
+	return w.Dot(tape.Constant(in.x))
+}
+
+// sparseInput разреженный вектор признаков (разреженная матрица размерности n на 1).
+// Вычисления на первом слое перебирают только ненулевые признаки.
+type sparseInput struct {
+	x matrix.Sparse
+}
+
+// getRows возвращает количество признаков.
+func (in sparseInput) getRows() int {
+	return in.x.GetRows()
+}
+
+// getColumns возвращает количество столбцов вектора признаков.
+func (in sparseInput) getColumns() int {
+	return in.x.GetColumns()
+}
+
+// asType возвращает исходный вектор признаков: разреженные векторы хранят элементы float64,
+// а тип элементов произведений совпадает с типом элементов плотного операнда.
+func (in sparseInput) asType(dtype matrix.DType) input {
+	return in
+}
+
+// normalize возвращает вектор признаков, умноженный на вектор нормализации.
+func (in sparseInput) normalize(norm matrix.Matrix) (input, error) {
+	x, err := in.x.TryScaleRows(norm)
+	return sparseInput{x: x}, err
+}
+
+// weigh возвращает произведение матрицы весов на вектор признаков.
+func (in sparseInput) weigh(w matrix.Matrix) (matrix.Matrix, error) {
+	return w.TryDotSparse(in.x)
+}
+
+// weighInto записывает в dst произведение матрицы весов на вектор признаков.
+func (in sparseInput) weighInto(dst, w matrix.Matrix) {
+	matrix.DotSparseInto(dst, w, in.x)
+}
+
+// outerInto записывает в dst произведение ошибки на транспонированный вектор признаков.
+func (in sparseInput) outerInto(dst, delta matrix.Matrix) {
+	matrix.DotTSparseInto(dst, delta, in.x)
+}
+
+// weighVariable возвращает переменную ленты, равную произведению переменной весов на вектор признаков.
+func (in sparseInput) weighVariable(tape *autodiff.Tape, w autodiff.Variable) autodiff.Variable {
+	return w.DotSparse(in.x)
+}
+
+// rowInput возвращает вектор признаков наблюдения с индексом i датафрейма df
+// в том виде, в котором он хранится в датафрейме.
+func rowInput(df data_frame.DataFrame, i int) input {
+	if df.Data[i].IsSparse() {
+		return sparseInput{x: df.Data[i].GetSparseX()}
+	}
+	return denseInput{x: df.Data[i].GetX()}
+}
+
+// predictInput возвращает вектор признаков наблюдения с индексом i датафрейма df для предсказания.
+// Плотный вектор копируется с приведением к типу элементов dtype, поэтому нормализация не изменяет датафрейм,
+// разреженный вектор при нормализации не изменяется и не копируется.
+func predictInput(df data_frame.DataFrame, i int, dtype matrix.DType) input {
+	if df.Data[i].IsSparse() {
+		return sparseInput{x: df.Data[i].GetSparseX()}
+	}
+	return denseInput{x: df.Data[i].GetX().AsType(dtype)}
+}
@@ -2,8 +2,10 @@ package neural_network
 
 import (
 	"bufio"
+	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"strconv"
 	"strings"
@@ -16,11 +18,14 @@ import (
 // Метод записывает в формате:
 // сначала число слоев,
 // с новой строки перечисление через пробел количество нейронов в каждом слое соответственно,
-// с новой строки имя функции активации,
+// с новой строки имена функций активации слоев через пробел, начиная с первого скрытого,
 // с новой строки 1, если есть нормализация, далее если есть нормализация, то с новой строки вектор из абсолютных максимумов,
 // если 0, то нормализации нет, далее если нормализации нет, то идет с новой строки пустая строка
 // далее идут веса,
-// и наконец смещения
+// и наконец смещения.
+// Тип элементов весов и смещений записывается вместе с размерностью каждой матрицы (см. matrix.WriteMatrixes).
+// Элементы записываются с шестью знаками после запятой, для записи без потери точности используйте WriteBinary.
+// Функция потерь (см. SetLoss) не записывается.
 func (nn *NeuralNetwork) Write(writer io.Writer) error {
 	_, err := fmt.Fprintf(writer, "%d\n", nn.numLayers)
 	if err != nil {
@@ -39,7 +44,7 @@ func (nn *NeuralNetwork) Write(writer io.Writer) error {
 		return err
 	}
 
-	_, err = fmt.Fprintf(writer, nn.actFunc.getName()+"\n")
+	_, err = fmt.Fprintf(writer, "%s\n", strings.Join(nn.activationNames(), " "))
 	if err != nil {
 		return err
 	}
@@ -81,11 +86,16 @@ func (nn *NeuralNetwork) Write(writer io.Writer) error {
 // Функция читает параметры только в формате:
 // сначала число слоев,
 // с новой строки перечисление через пробел количество нейронов в каждом слое соответственно,
-// с новой строки имя функции активации,
+// с новой строки имена функций активации слоев через пробел
+// или одно имя функции активации всех слоев (формат файлов, записанных до появления функций активации слоев),
 // с новой строки 1, если есть нормализация, далее если есть нормализация, то с новой строки вектор из абсолютных максимумов,
 // если 0, то нормализации нет, далее если нормализации нет, то идет с новой строки пустая строка
 // далее идут веса,
-// и наконец смещения
+// и наконец смещения.
+// Тип элементов весов и смещений сети определяется по прочитанным матрицам.
+// Функция возвращает ошибку, оборачивающую ErrUnknownActivation, если функция активации не зарегистрирована,
+// и ошибку, если количество или размерности весов, смещений и вектора нормализации не соответствуют
+// количеству нейронов слоев или типы элементов весов и смещений различаются.
 func Read(reader io.Reader) (NeuralNetwork, error) {
 
 	scanner := bufio.NewScanner(reader)
@@ -135,14 +145,12 @@ func Read(reader io.Reader) (NeuralNetwork, error) {
 		return NeuralNetwork{}, fmt.Errorf("unexpected end of file while reading neural network parameters")
 	}
 
-	line = strings.Fields(scanner.Text())
-	if len(line) != 1 {
-		return NeuralNetwork{}, fmt.Errorf("incorrect name of activation function")
+	// файлы, записанные до появления функций активации слоев, содержат одно имя на все слои
+	actFuncs, err := newActivations(strings.Fields(scanner.Text()), numLayers-1)
+	if err != nil {
+		return NeuralNetwork{}, err
 	}
 
-	nameFuncAct := line[0]
-	actFunc := nameToActFunc(nameFuncAct)
-
 	if !scanner.Scan() {
 		return NeuralNetwork{}, fmt.Errorf("unexpected end of file while reading neural network parameters")
 	}
@@ -179,15 +187,28 @@ func Read(reader io.Reader) (NeuralNetwork, error) {
 		return NeuralNetwork{}, err
 	}
 
-	return NeuralNetwork{
+	if len(norm) != 1 {
+		return NeuralNetwork{}, fmt.Errorf("expected 1 normalization vector, got %d", len(norm))
+	}
+	// вектор нормализации приводится к типу элементов весов, как при чтении архива .npz
+	if haveNormalization && len(weights) > 0 && norm[0].DType() != weights[0].DType() {
+		norm[0] = norm[0].AsType(weights[0].DType())
+	}
+
+	nn := NeuralNetwork{
 		numLayers:         numLayers,
 		sizes:             sizes,
 		biases:            biases,
 		weights:           weights,
-		actFunc:           actFunc,
+		actFuncs:          actFuncs,
 		norm:              norm[0],
 		haveNormalization: haveNormalization,
-	}, nil
+	}
+	if err := nn.checkParameters(); err != nil {
+		return NeuralNetwork{}, err
+	}
+
+	return nn, nil
 }
 
 // WriteToFile записывает параметры нейронной сети (структуры NeuralNetwork) в файл и в случае неудачи возвращает ошибку.
@@ -224,3 +245,398 @@ func ReadFromFile(filename string) (NeuralNetwork, error) {
 
 	return nn, nil
 }
+
+// binaryMagic первые байты двоичного файла параметров нейронной сети.
+const binaryMagic = "GBNN"
+
+// binaryVersion текущая версия двоичного формата параметров нейронной сети.
+// Версия 1 хранит одну функцию активации для всех слоев.
+const binaryVersion = 2
+
+// WriteBinary записывает параметры нейронной сети (структуры NeuralNetwork) в поток writer
+// в двоичном формате без потери точности и возвращает ошибку, если она возникла при записи.
+// Формат (числа little-endian): магические байты "GBNN", версия uint16,
+// количество функций активации uint16, для каждой функции активации длина имени uint16 и само имя,
+// флаг нормализации uint8 (1 если нормализация есть),
+// далее веса, смещения и, если есть нормализация, вектор нормализации в двоичном формате матриц
+// (см. matrix.WriteMatrixesBinary).
+// Метод возвращает ошибку, если функций активации или байтов в имени функции активации больше math.MaxUint16.
+func (nn *NeuralNetwork) WriteBinary(writer io.Writer) error {
+	w := bufio.NewWriter(writer)
+
+	names := nn.activationNames()
+	if len(names) > math.MaxUint16 {
+		return fmt.Errorf("too many activation functions for binary format: %d", len(names))
+	}
+	header := make([]byte, 0, len(binaryMagic)+5)
+	header = append(header, binaryMagic...)
+	header = binary.LittleEndian.AppendUint16(header, binaryVersion)
+	header = binary.LittleEndian.AppendUint16(header, uint16(len(names)))
+	for _, name := range names {
+		if len(name) > math.MaxUint16 {
+			return fmt.Errorf("activation function name is too long for binary format: %d bytes", len(name))
+		}
+		header = binary.LittleEndian.AppendUint16(header, uint16(len(name)))
+		header = append(header, name...)
+	}
+
+	matrixes := append(append([]matrix.Matrix{}, nn.weights...), nn.biases...)
+	if nn.haveNormalization {
+		header = append(header, 1)
+		matrixes = append(matrixes, nn.norm)
+	} else {
+		header = append(header, 0)
+	}
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	if err := matrix.WriteMatrixesBinary(w, matrixes); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+// ReadBinary читает параметры нейронной сети (структуры NeuralNetwork) из потока reader
+// в двоичном формате (см. WriteBinary), в том числе в формате версии 1 с одной функцией активации.
+// Возвращает нейронную сеть (структуру NeuralNetwork) и ошибку, если она возникла при чтении,
+// в том числе matrix.ErrChecksum, если данные повреждены,
+// ошибку, оборачивающую ErrUnknownActivation, если функция активации не зарегистрирована,
+// и ошибку, если размерности или типы элементов параметров не согласованы между собой
+// (вектор нормализации должен иметь размерность sizes[0] на 1 и тип элементов весов).
+func ReadBinary(reader io.Reader) (NeuralNetwork, error) {
+	r := bufio.NewReader(reader)
+	errEOF := fmt.Errorf("unexpected end of file while reading neural network parameters")
+
+	header := make([]byte, len(binaryMagic)+2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return NeuralNetwork{}, errEOF
+	}
+	if string(header[:len(binaryMagic)]) != binaryMagic {
+		return NeuralNetwork{}, fmt.Errorf("incorrect magic of neural network binary file")
+	}
+	version := binary.LittleEndian.Uint16(header[len(binaryMagic):])
+	if version != 1 && version != binaryVersion {
+		return NeuralNetwork{}, fmt.Errorf("unsupported neural network binary format version %d", version)
+	}
+
+	// версия 1 хранит одно имя функции активации без их количества
+	count := uint16(1)
+	if version != 1 {
+		if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+			return NeuralNetwork{}, errEOF
+		}
+	}
+
+	names := make([]string, count)
+	for i := range names {
+		var length uint16
+		if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+			return NeuralNetwork{}, errEOF
+		}
+		name := make([]byte, length)
+		if _, err := io.ReadFull(r, name); err != nil {
+			return NeuralNetwork{}, errEOF
+		}
+		names[i] = string(name)
+	}
+
+	flag, err := r.ReadByte()
+	if err != nil {
+		return NeuralNetwork{}, errEOF
+	}
+	haveNormalization := flag == 1
+
+	matrixes, err := matrix.ReadMatrixesBinary(r)
+	if err != nil {
+		return NeuralNetwork{}, err
+	}
+
+	var norm matrix.Matrix
+	if haveNormalization {
+		if len(matrixes) == 0 {
+			return NeuralNetwork{}, fmt.Errorf("missing normalization vector")
+		}
+		norm = matrixes[len(matrixes)-1]
+		matrixes = matrixes[:len(matrixes)-1]
+	}
+
+	if len(matrixes) == 0 || len(matrixes)%2 != 0 {
+		return NeuralNetwork{}, fmt.Errorf("incorrect number of layers")
+	}
+
+	numLayers := len(matrixes)/2 + 1
+	weights, biases := matrixes[:numLayers-1], matrixes[numLayers-1:]
+
+	sizes := []int{weights[0].GetColumns()}
+	for i, w := range weights {
+		if w.GetColumns() != sizes[i] || biases[i].GetRows() != w.GetRows() || biases[i].GetColumns() != 1 ||
+			w.DType() != weights[0].DType() || biases[i].DType() != weights[0].DType() {
+			return NeuralNetwork{}, fmt.Errorf("incorrect dimension or element type of layer %d parameters", i)
+		}
+		sizes = append(sizes, w.GetRows())
+	}
+
+	actFuncs, err := newActivations(names, numLayers-1)
+	if err != nil {
+		return NeuralNetwork{}, err
+	}
+
+	nn := NeuralNetwork{
+		numLayers:         numLayers,
+		sizes:             sizes,
+		biases:            biases,
+		weights:           weights,
+		actFuncs:          actFuncs,
+		norm:              norm,
+		haveNormalization: haveNormalization,
+	}
+	// размерности весов и смещений проверены выше, остается вектор нормализации
+	if err := nn.checkParameters(); err != nil {
+		return NeuralNetwork{}, err
+	}
+
+	return nn, nil
+}
+
+// WriteBinaryFile записывает параметры нейронной сети (структуры NeuralNetwork) в файл
+// в двоичном формате (см. WriteBinary) и в случае неудачи возвращает ошибку.
+func (nn *NeuralNetwork) WriteBinaryFile(filename string) error {
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+
+	if err := nn.WriteBinary(file); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// ReadBinaryFile читает параметры нейронной сети (структуры NeuralNetwork) из файла
+// в двоичном формате (см. ReadBinary).
+func ReadBinaryFile(filename string) (NeuralNetwork, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return NeuralNetwork{}, err
+	}
+	defer file.Close()
+
+	return ReadBinary(file)
+}
+
+/*
+Обмен параметрами нейронной сети с NumPy.
+В архиве .npz веса i слоя хранятся в массиве weights_i размерности sizes[i+1] на sizes[i],
+смещения в массиве biases_i размерности sizes[i+1] на 1, где i = 0, 1, ..., numLayers-2,
+вектор нормализации, если она включена, в массиве norm размерности sizes[0] на 1.
+Имена функций активации слоев через пробел, как в текстовом формате (см. Write), хранятся в массиве activations
+размерности 1 на n с элементами float32, равными байтам строки имен
+(в NumPy строка восстанавливается как bytes(a.astype(numpy.uint8)).decode()).
+Функция потерь в архиве не хранится.
+*/
+
+// WriteNpz записывает параметры и функции активации слоев нейронной сети (структуры NeuralNetwork)
+// в поток writer в формате .npz и возвращает ошибку, если она возникла при записи.
+// Функция потерь (см. SetLoss) не записывается.
+func (nn *NeuralNetwork) WriteNpz(writer io.Writer) error {
+	return matrix.WriteNpz(writer, nn.npzArrays())
+}
+
+// WriteNpzFile записывает параметры нейронной сети (структуры NeuralNetwork) в файл в формате .npz
+// и в случае неудачи возвращает ошибку.
+func (nn *NeuralNetwork) WriteNpzFile(filename string) error {
+	return matrix.WriteNpzFile(filename, nn.npzArrays())
+}
+
+// npzArrays возвращает параметры нейронной сети по именам массивов архива .npz.
+func (nn *NeuralNetwork) npzArrays() map[string]matrix.Matrix {
+	arrays := make(map[string]matrix.Matrix, 2*len(nn.weights)+1)
+	for i := range nn.weights {
+		arrays[fmt.Sprintf("weights_%d", i)] = nn.weights[i]
+		arrays[fmt.Sprintf("biases_%d", i)] = nn.biases[i]
+	}
+
+	if nn.haveNormalization {
+		arrays["norm"] = nn.norm
+	}
+
+	names := strings.Join(nn.activationNames(), " ")
+	activations := matrix.ZeroOf(1, len(names), matrix.Float32)
+	for j := 0; j < len(names); j++ {
+		activations.SetIJ(0, j, float64(names[j]))
+	}
+	arrays["activations"] = activations
+
+	return arrays
+}
+
+// npzActivations возвращает имена функций активации, записанные в массиве activations архива .npz,
+// и ошибку, если элементы массива не являются байтами.
+func npzActivations(activations matrix.Matrix) ([]string, error) {
+	if activations.GetRows() != 1 {
+		return nil, fmt.Errorf("npz: activations has %d rows, expected 1", activations.GetRows())
+	}
+
+	names := make([]byte, activations.GetColumns())
+	for j := range names {
+		x := activations.GetIJ(0, j)
+		if x != math.Trunc(x) || x < 0 || x > math.MaxUint8 {
+			return nil, fmt.Errorf("npz: activations contains %v, expected bytes", x)
+		}
+		names[j] = byte(x)
+	}
+
+	return strings.Fields(string(names)), nil
+}
+
+// ReadNpz читает параметры нейронной сети (структуры NeuralNetwork) из архива .npz размером size байт
+// и возвращает нейронную сеть и ошибку, если она возникла при чтении.
+// Функции активации слоев читаются из архива (см. WriteNpz), если actFuncs пуст.
+// Иначе, например для архивов без функций активации, записанных NumPy, используются actFuncs:
+// одна функция активации для всех слоев или по одной для каждого слоя, начиная с первого скрытого.
+// Функция потерь в архиве не хранится и задается после чтения методом SetLoss.
+// Тип элементов весов и смещений сети определяется по прочитанным массивам.
+// Функция возвращает ошибку, если в архиве нет весов, размерности или типы элементов массивов
+// не согласованы между собой, функции активации не переданы и не записаны в архиве
+// или их количество не соответствует количеству слоев, и ошибку, оборачивающую ErrUnknownActivation,
+// если записанная в архиве функция активации не зарегистрирована.
+func ReadNpz(reader io.ReaderAt, size int64, actFuncs ...Activation) (NeuralNetwork, error) {
+	arrays, err := matrix.ReadNpz(reader, size)
+	if err != nil {
+		return NeuralNetwork{}, err
+	}
+
+	return npzToNeuralNetwork(arrays, actFuncs)
+}
+
+// ReadNpzFile читает параметры нейронной сети (структуры NeuralNetwork) из файла в формате .npz (см. ReadNpz).
+func ReadNpzFile(filename string, actFuncs ...Activation) (NeuralNetwork, error) {
+	arrays, err := matrix.ReadNpzFile(filename)
+	if err != nil {
+		return NeuralNetwork{}, err
+	}
+
+	return npzToNeuralNetwork(arrays, actFuncs)
+}
+
+// npzToNeuralNetwork возвращает нейронную сеть (структуру NeuralNetwork) с параметрами из массивов arrays
+// и функциями активации actFuncs и ошибку, если массивы не согласованы между собой.
+func npzToNeuralNetwork(arrays map[string]matrix.Matrix, actFuncs []Activation) (NeuralNetwork, error) {
+	var weights, biases []matrix.Matrix
+	for i := 0; ; i++ {
+		w, ok := arrays[fmt.Sprintf("weights_%d", i)]
+		if !ok {
+			break
+		}
+
+		b, ok := arrays[fmt.Sprintf("biases_%d", i)]
+		if !ok {
+			return NeuralNetwork{}, fmt.Errorf("npz: missing array biases_%d", i)
+		}
+
+		weights = append(weights, w)
+		biases = append(biases, b)
+	}
+
+	if len(weights) == 0 {
+		return NeuralNetwork{}, fmt.Errorf("npz: missing array weights_0")
+	}
+
+	dtype := weights[0].DType()
+	sizes := []int{weights[0].GetColumns()}
+	for i, w := range weights {
+		if w.GetColumns() != sizes[i] {
+			return NeuralNetwork{}, fmt.Errorf("npz: weights_%d has %d columns, expected %d", i, w.GetColumns(), sizes[i])
+		}
+		if b := biases[i]; b.GetRows() != w.GetRows() || b.GetColumns() != 1 {
+			return NeuralNetwork{}, fmt.Errorf("npz: biases_%d has shape %d*%d, expected %d*1", i, b.GetRows(), b.GetColumns(), w.GetRows())
+		}
+		if w.DType() != dtype || biases[i].DType() != dtype {
+			return NeuralNetwork{}, fmt.Errorf("npz: parameters of layer %d are not %v", i, dtype)
+		}
+
+		sizes = append(sizes, w.GetRows())
+	}
+
+	norm, haveNormalization := arrays["norm"]
+	if haveNormalization && (norm.GetRows() != sizes[0] || norm.GetColumns() != 1) {
+		return NeuralNetwork{}, fmt.Errorf("npz: norm has shape %d*%d, expected %d*1", norm.GetRows(), norm.GetColumns(), sizes[0])
+	}
+	if haveNormalization {
+		norm = norm.AsType(dtype)
+	}
+
+	// функции активации, переданные явно, заменяют записанные в архиве
+	if activations, ok := arrays["activations"]; ok && len(actFuncs) == 0 {
+		names, err := npzActivations(activations)
+		if err != nil {
+			return NeuralNetwork{}, err
+		}
+		actFuncs, err = newActivations(names, len(weights))
+		if err != nil {
+			return NeuralNetwork{}, err
+		}
+	}
+
+	actFuncs, err := layerActivations(actFuncs, len(weights))
+	if err != nil {
+		return NeuralNetwork{}, err
+	}
+
+	return NeuralNetwork{
+		numLayers:         len(sizes),
+		sizes:             sizes,
+		biases:            biases,
+		weights:           weights,
+		actFuncs:          actFuncs,
+		norm:              norm,
+		haveNormalization: haveNormalization,
+	}, nil
+}
+
+// activationNames возвращает имена функций активации слоев нейронной сети.
+func (nn *NeuralNetwork) activationNames() []string {
+	names := make([]string, len(nn.actFuncs))
+	for i, actFunc := range nn.actFuncs {
+		names[i] = actFunc.GetName()
+	}
+	return names
+}
+
+// newActivations возвращает функции активации layers слоев по прочитанным именам names (см. layerActivations)
+// и ошибку, если функция активации не зарегистрирована или количество имен не соответствует количеству слоев.
+func newActivations(names []string, layers int) ([]Activation, error) {
+	actFuncs := make([]Activation, len(names))
+	for i, name := range names {
+		actFunc, err := NewActivation(name)
+		if err != nil {
+			return nil, err
+		}
+		actFuncs[i] = actFunc
+	}
+
+	return layerActivations(actFuncs, layers)
+}
+
+// layerActivations возвращает функции активации layers слоев: actFuncs, если их количество равно layers,
+// или layers копий единственной функции активации.
+// Функция возвращает ошибку, если количество функций активации не равно ни 1, ни layers.
+func layerActivations(actFuncs []Activation, layers int) ([]Activation, error) {
+	if len(actFuncs) == layers {
+		return append([]Activation{}, actFuncs...), nil
+	}
+	if len(actFuncs) != 1 {
+		return nil, fmt.Errorf("incorrect number of activation functions: %d for %d layers", len(actFuncs), layers)
+	}
+
+	res := make([]Activation, layers)
+	for i := range res {
+		res[i] = actFuncs[0]
+	}
+	return res, nil
+}
@@ -0,0 +1,265 @@
+package neural_network
+
+// файл содержит функции потерь
+
+import (
+	"math"
+
+	"github.com/Davmgiz/GoblinNeuronet/pkg/matrix"
+)
+
+// lossEpsilon граница, до которой прижимаются вероятности в логарифмических функциях потерь,
+// чтобы значение и градиент оставались конечными при выходе сети, равном 0 или 1.
+const lossEpsilon = 1e-12
+
+// Loss интерфейс для функций потерь.
+// Значение функции потерь считается на одном наблюдении как сумма по выходным нейронам,
+// по датафрейму оно усредняется (см. NeuralNetwork.AverageLoss).
+type Loss interface {
+	Value(a, y matrix.Matrix) float64 // значение функции потерь по выходу сети a и целевой переменной y
+	Gradient(dst, a, y matrix.Matrix) // записывает в dst градиент функции потерь по выходу сети a
+	GetName() string                  // имя функции потерь
+}
+
+// fusedLoss функция потерь, для которой ошибка на выходном слое с некоторыми функциями активации
+// вычисляется сразу, без умножения градиента на производную функции активации.
+// Так ошибка не обращается в ноль при насыщении функции активации и не делится на малые вероятности.
+type fusedLoss interface {
+	// fusedDelta записывает в dst ошибку на выходном слое и возвращает true,
+	// если для функции активации actFunc есть совмещенная формула.
+	fusedDelta(dst, a, y matrix.Matrix, actFunc Activation) bool
+}
+
+// defaultLoss возвращает функцию потерь нейронной сети с функцией активации выходного слоя actFunc,
+// для которой не задана функция потерь: бинарную перекрестную энтропию для сигмоиды,
+// категориальную перекрестную энтропию для Softmax и MSE иначе.
+func defaultLoss(actFunc Activation) Loss {
+	switch actFunc.(type) {
+	case Sigmoid:
+		return BinaryCrossEntropy{}
+	case Softmax:
+		return CategoricalCrossEntropy{}
+	}
+	return MSE{}
+}
+
+// outputDelta записывает в dst ошибку на выходном слое, то есть градиент функции потерь loss
+// по взвешенной сумме z выходного слоя с функцией активации actFunc и активацией a:
+// совмещенную формулу, если она есть (см. fusedLoss), иначе loss'(a) ⊙ actFunc'(z)
+// или произведение loss'(a) на матрицу Якоби для функций активации всего слоя, таких как Softmax.
+// Функция не выделяет память.
+func outputDelta(dst, z, a, y matrix.Matrix, actFunc Activation, loss Loss) {
+	if fused, ok := loss.(fusedLoss); ok && fused.fusedDelta(dst, a, y, actFunc) {
+		return
+	}
+
+	loss.Gradient(dst, a, y)
+	if v, ok := actFunc.(vectorActivation); ok {
+		v.backward(dst, z, dst)
+		return
+	}
+	for i := 0; i < dst.GetRows(); i++ {
+		for j := 0; j < dst.GetColumns(); j++ {
+			dst.SetIJ(i, j, dst.GetIJ(i, j)*actFunc.Prime(z.GetIJ(i, j)))
+		}
+	}
+}
+
+// sumElements возвращает сумму f(a, y) по соответствующим элементам матриц a и y.
+func sumElements(a, y matrix.Matrix, f func(a, y float64) float64) float64 {
+	sum := 0.
+	for i := 0; i < a.GetRows(); i++ {
+		for j := 0; j < a.GetColumns(); j++ {
+			sum += f(a.GetIJ(i, j), y.GetIJ(i, j))
+		}
+	}
+	return sum
+}
+
+// gradientElements записывает в dst значения f(a, y) по соответствующим элементам матриц a и y.
+func gradientElements(dst, a, y matrix.Matrix, f func(a, y float64) float64) {
+	for i := 0; i < a.GetRows(); i++ {
+		for j := 0; j < a.GetColumns(); j++ {
+			dst.SetIJ(i, j, f(a.GetIJ(i, j), y.GetIJ(i, j)))
+		}
+	}
+}
+
+// clipProbability прижимает вероятность p к отрезку [lossEpsilon, 1 - lossEpsilon].
+func clipProbability(p float64) float64 {
+	return math.Min(math.Max(p, lossEpsilon), 1-lossEpsilon)
+}
+
+// MSE структура имплементирующая интерфейс Loss: квадратичная функция потерь ½·Σ(a - y)²,
+// среднее которой по наблюдениям равно половине среднеквадратичной ошибки.
+// Подходит для задач регрессии.
+type MSE struct {
+}
+
+// Value возвращает значение функции потерь.
+func (MSE) Value(a, y matrix.Matrix) float64 {
+	return sumElements(a, y, func(a, y float64) float64 { return 0.5 * (a - y) * (a - y) })
+}
+
+// Gradient записывает в dst градиент функции потерь: a - y.
+func (MSE) Gradient(dst, a, y matrix.Matrix) {
+	matrix.SubInto(dst, a, y)
+}
+
+// GetName возвращает имя функции потерь.
+func (MSE) GetName() string {
+	return "MSE"
+}
+
+// BinaryCrossEntropy структура имплементирующая интерфейс Loss: бинарная перекрестная энтропия
+// -Σ(y·ln(a) + (1 - y)·ln(1 - a)) для выходов сети a из (0, 1), каждый выходной нейрон
+// считается отдельным бинарным классификатором.
+// С сигмоидой на выходном слое ошибка вычисляется совмещенной формулой a - y.
+type BinaryCrossEntropy struct {
+}
+
+// Value возвращает значение функции потерь.
+func (BinaryCrossEntropy) Value(a, y matrix.Matrix) float64 {
+	return sumElements(a, y, func(a, y float64) float64 {
+		a = clipProbability(a)
+		return -(y*math.Log(a) + (1-y)*math.Log(1-a))
+	})
+}
+
+// Gradient записывает в dst градиент функции потерь: (a - y) / (a·(1 - a)).
+func (BinaryCrossEntropy) Gradient(dst, a, y matrix.Matrix) {
+	gradientElements(dst, a, y, func(a, y float64) float64 {
+		a = clipProbability(a)
+		return (a - y) / (a * (1 - a))
+	})
+}
+
+// GetName возвращает имя функции потерь.
+func (BinaryCrossEntropy) GetName() string {
+	return "BinaryCrossEntropy"
+}
+
+// fusedDelta записывает в dst ошибку a - y для сигмоиды на выходном слое.
+func (BinaryCrossEntropy) fusedDelta(dst, a, y matrix.Matrix, actFunc Activation) bool {
+	if _, ok := actFunc.(Sigmoid); !ok {
+		return false
+	}
+	matrix.SubInto(dst, a, y)
+	return true
+}
+
+// CategoricalCrossEntropy структура имплементирующая интерфейс Loss: категориальная перекрестная энтропия
+// -Σ y·ln(a) для выхода сети a, являющегося распределением вероятностей классов,
+// и целевой переменной y, закодированной вектором (см. DataFrame.Num2Vec).
+// С Softmax на выходном слое ошибка вычисляется совмещенной формулой a·Σy - y
+// (a - y для y, закодированной вектором) без деления на малые вероятности.
+type CategoricalCrossEntropy struct {
+}
+
+// Value возвращает значение функции потерь.
+func (CategoricalCrossEntropy) Value(a, y matrix.Matrix) float64 {
+	return sumElements(a, y, func(a, y float64) float64 {
+		if y == 0 {
+			return 0
+		}
+		return -y * math.Log(clipProbability(a))
+	})
+}
+
+// Gradient записывает в dst градиент функции потерь: -y / a.
+func (CategoricalCrossEntropy) Gradient(dst, a, y matrix.Matrix) {
+	gradientElements(dst, a, y, func(a, y float64) float64 {
+		return -y / clipProbability(a)
+	})
+}
+
+// GetName возвращает имя функции потерь.
+func (CategoricalCrossEntropy) GetName() string {
+	return "CategoricalCrossEntropy"
+}
+
+// fusedDelta записывает в dst ошибку a·Σy - y для Softmax на выходном слое.
+func (CategoricalCrossEntropy) fusedDelta(dst, a, y matrix.Matrix, actFunc Activation) bool {
+	if _, ok := actFunc.(Softmax); !ok {
+		return false
+	}
+	sum := y.Sum()
+	gradientElements(dst, a, y, func(a, y float64) float64 { return a*sum - y })
+	return true
+}
+
+// Huber структура имплементирующая интерфейс Loss: функция потерь Хьюбера,
+// квадратичная ½·(a - y)² при |a - y| <= Delta и линейная Delta·(|a - y| - ½·Delta) иначе.
+// Менее чувствительна к выбросам, чем MSE.
+type Huber struct {
+	Delta float64 // Порог перехода к линейной части, при 0 используется 1
+}
+
+// delta возвращает порог перехода к линейной части.
+func (h Huber) delta() float64 {
+	if h.Delta == 0 {
+		return 1
+	}
+	return h.Delta
+}
+
+// Value возвращает значение функции потерь.
+func (h Huber) Value(a, y matrix.Matrix) float64 {
+	delta := h.delta()
+	return sumElements(a, y, func(a, y float64) float64 {
+		r := math.Abs(a - y)
+		if r <= delta {
+			return 0.5 * r * r
+		}
+		return delta * (r - 0.5*delta)
+	})
+}
+
+// Gradient записывает в dst градиент функции потерь: a - y, ограниченное отрезком [-Delta, Delta].
+func (h Huber) Gradient(dst, a, y matrix.Matrix) {
+	delta := h.delta()
+	gradientElements(dst, a, y, func(a, y float64) float64 {
+		return math.Min(math.Max(a-y, -delta), delta)
+	})
+}
+
+// GetName возвращает имя функции потерь.
+func (h Huber) GetName() string {
+	return parametricName("Huber", h.delta())
+}
+
+// Hinge структура имплементирующая интерфейс Loss: кусочно-линейная функция потерь Σ max(0, 1 - t·a),
+// где t = 1 для положительных y и t = -1 иначе, поэтому подходят целевые переменные как 0 и 1, так и -1 и 1.
+// Используется с выходным слоем без насыщения, например Identity или Tanh.
+type Hinge struct {
+}
+
+// hingeTarget возвращает метку класса -1 или 1 для целевой переменной y.
+func hingeTarget(y float64) float64 {
+	if y > 0 {
+		return 1
+	}
+	return -1
+}
+
+// Value возвращает значение функции потерь.
+func (Hinge) Value(a, y matrix.Matrix) float64 {
+	return sumElements(a, y, func(a, y float64) float64 {
+		return math.Max(0, 1-hingeTarget(y)*a)
+	})
+}
+
+// Gradient записывает в dst градиент функции потерь: -t при t·a < 1 и 0 иначе.
+func (Hinge) Gradient(dst, a, y matrix.Matrix) {
+	gradientElements(dst, a, y, func(a, y float64) float64 {
+		if t := hingeTarget(y); t*a < 1 {
+			return -t
+		}
+		return 0
+	})
+}
+
+// GetName возвращает имя функции потерь.
+func (Hinge) GetName() string {
+	return "Hinge"
+}
@@ -3,24 +3,41 @@ package neural_network
 // файл содержит метрики
 
 import (
+	"errors"
+
 	"github.com/Davmgiz/GoblinNeuronet/pkg/data_frame"
 	"github.com/Davmgiz/GoblinNeuronet/pkg/matrix"
 )
 
 // Accuracy возвращает accuracy в процентах (количество правильно угаданных предсказаний)
 // Метод принимает тестовый датасет для подсчета.
+// Предсказанный класс является номером наибольшей вероятности (см. PredictProba),
+// а для выходного слоя, не дающего вероятностей, номером наибольшего выхода сети.
+// Целевая переменная наблюдения является номером класса (матрица 1 на 1)
+// или вектором, закодированным DataFrame.Num2Vec.
+// Датафрейм не изменяется, наблюдения приводятся к типу элементов сети так же, как в Predict.
+// Метод вызывает панику, если размерность наблюдения не соответствует нейронной сети,
+// для проверки отдельных наблюдений без паники используется Predict.
 func (nn *NeuralNetwork) Accuracy(dataTest data_frame.DataFrame) float64 {
 	//mp := make(map[int]int)
+	predict := nn.feedforwardInput
+	if nn.givesProbabilities() {
+		predict = nn.probabilities
+	}
+
 	cnt := 0
 	for i := 0; i < len(dataTest.Data); i++ {
 
 		// находим предсказание
-		pred := matrix.Vec2Num(nn.feedforward(dataTest.Data[i].GetX()))
+		out, err := predict(predictInput(dataTest, i, nn.DType()))
+		if err != nil {
+			panic(err)
+		}
+		pred, _ := out.ArgMax()
 		//mp[pred]++
 
 		//сравниваем предсказание со значением по факту.
-		y := int(matrix.Num(dataTest.Data[i].GetY()))
-		if y == pred {
+		if pred == targetClass(dataTest.Data[i].GetY()) {
 			cnt++
 		}
 	}
@@ -29,3 +46,41 @@ func (nn *NeuralNetwork) Accuracy(dataTest data_frame.DataFrame) float64 {
 
 	return (float64(cnt) / float64(len(dataTest.Data))) * 100
 }
+
+// targetClass возвращает номер класса целевой переменной y: единственный элемент матрицы 1 на 1
+// или номер наибольшего элемента вектора, закодированного DataFrame.Num2Vec.
+func targetClass(y matrix.Matrix) int {
+	if y.GetRows() == 1 && y.GetColumns() == 1 {
+		return int(matrix.Num(y))
+	}
+	i, _ := y.ArgMax()
+	return i
+}
+
+// AverageLoss возвращает среднее по наблюдениям датафрейма dataTest значение функции потерь
+// нейронной сети (см. SetLoss и GetLoss) без слагаемого регуляризации L2.
+// Датафрейм не изменяется, наблюдения приводятся к типу элементов сети так же, как в Predict.
+// Метод возвращает ошибку, если датафрейм пуст
+// или размерность наблюдения не соответствует нейронной сети (*matrix.ShapeError).
+func (nn *NeuralNetwork) AverageLoss(dataTest data_frame.DataFrame) (float64, error) {
+	if len(dataTest.Data) == 0 {
+		return 0, errors.New("average loss of empty data frame")
+	}
+
+	loss := nn.GetLoss()
+	sum := 0.
+	for i := 0; i < len(dataTest.Data); i++ {
+		out, err := nn.feedforwardInput(predictInput(dataTest, i, nn.DType()))
+		if err != nil {
+			return 0, err
+		}
+
+		y := dataTest.Data[i].GetY()
+		if err := nn.checkTarget(y); err != nil {
+			return 0, err
+		}
+		sum += loss.Value(out, y)
+	}
+
+	return sum / float64(len(dataTest.Data)), nil
+}
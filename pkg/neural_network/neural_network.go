@@ -11,30 +11,94 @@ Package neural_network предоставляет инструментарий 
 package neural_network
 
 import (
+	"errors"
 	"fmt"
+	"math/rand"
 
 	"github.com/Davmgiz/GoblinNeuronet/pkg/data_frame"
 	"github.com/Davmgiz/GoblinNeuronet/pkg/matrix"
 )
 
+// ErrNoProbabilities ошибка предсказания вероятностей классов нейронной сетью,
+// функция активации выходного слоя которой не является Softmax или Sigmoid.
+var ErrNoProbabilities = errors.New("output activation function does not give probabilities")
+
 // NeuralNetwork представляет структуру полносвязной нейронной сети.
-// Функция активации одна на все слои нейронной сети.
+// Каждый слой, кроме входного, имеет свою функцию активации.
 // Всегда используется регуляризация L2.
 type NeuralNetwork struct {
 	numLayers         int             // Количество слоев
 	sizes             []int           // Количество нейронов в каждом слое
 	biases            []matrix.Matrix // Смещения
 	weights           []matrix.Matrix // Веса
-	actFunc           activationFunc  // Функция активации
+	actFuncs          []Activation    // Функции активации слоев, начиная с первого скрытого
 	norm              matrix.Matrix   // Вектор максимальных значений по модулю по всем признакам наблюдений
 	haveNormalization bool            // Включена ли нормализация или нет
+	rng               *rand.Rand      // Генератор для перемешивания при обучении, nil для глобального генератора
+	useAutodiff       bool            // Вычисляются ли градиенты автоматическим дифференцированием
+	loss              Loss            // Функция потерь, nil для функции потерь по умолчанию (см. GetLoss)
 }
 
 // NewNeuralNetwork возвращает нейронную сеть (структуру NeuralNetwork)
 // Принимает слайс из количества нейронов в каждом слое соответственно и
-// интерфейс activationFunc который представляет из себя функцию активации.
+// интерфейс Activation который представляет из себя функцию активации.
+// Веса и смещения сети хранятся с элементами float64.
+// Для чтения записанной нейронной сети функция активации должна быть зарегистрирована (см. RegisterActivation).
+// Функция вызывает панику, если элементы слайса sizes не положительны.
+func NewNeuralNetwork(sizes []int, actFunc Activation) NeuralNetwork {
+	return NewNeuralNetworkOf(sizes, actFunc, matrix.Float64)
+}
+
+// NewNeuralNetworkByName работает так же, как NewNeuralNetwork, но функция активации задается
+// именем, под которым она зарегистрирована (см. RegisterActivation).
+// Функция возвращает ошибку, оборачивающую ErrUnknownActivation, если функция активации с таким именем не зарегистрирована.
+// Функция вызывает панику, если элементы слайса sizes не положительны.
+func NewNeuralNetworkByName(sizes []int, name string) (NeuralNetwork, error) {
+	actFunc, err := NewActivation(name)
+	if err != nil {
+		return NeuralNetwork{}, err
+	}
+
+	return NewNeuralNetwork(sizes, actFunc), nil
+}
+
+// NewNeuralNetworkOf работает так же, как NewNeuralNetwork, но веса и смещения сети
+// хранятся и вычисляются с типом элементов dtype (например matrix.Float32).
 // Функция вызывает панику, если элементы слайса sizes не положительны.
-func NewNeuralNetwork(sizes []int, actFunc activationFunc) NeuralNetwork {
+func NewNeuralNetworkOf(sizes []int, actFunc Activation, dtype matrix.DType) NeuralNetwork {
+	return NewNeuralNetworkFrom(sizes, actFunc, dtype, nil)
+}
+
+// NewNeuralNetworkFrom работает так же, как NewNeuralNetworkOf, но веса и смещения инициализируются
+// генератором rng, который затем используется методом Sgd для перемешивания датафрейма.
+// Сети, созданные генераторами с одинаковым начальным значением и обученные на одинаковых данных
+// с одинаковыми параметрами, имеют побитово равные веса и смещения.
+// Если rng равен nil, используется глобальный генератор пакета math/rand.
+// Функция вызывает панику, если элементы слайса sizes не положительны.
+func NewNeuralNetworkFrom(sizes []int, actFunc Activation, dtype matrix.DType, rng *rand.Rand) NeuralNetwork {
+	actFuncs := make([]Activation, len(sizes)-1)
+	for i := range actFuncs {
+		actFuncs[i] = actFunc
+	}
+
+	return NewNeuralNetworkLayersFrom(sizes, actFuncs, dtype, rng)
+}
+
+// NewNeuralNetworkLayers возвращает нейронную сеть (структуру NeuralNetwork), каждый слой которой
+// имеет свою функцию активации: actFuncs[i] применяется к выходу i+1 слоя, например ReLU на скрытых слоях
+// и Sigmoid на выходном. Функция потерь по умолчанию зависит от функции активации выходного слоя (см. GetLoss).
+// Веса и смещения сети хранятся с элементами float64.
+// Функция вызывает панику, если элементы слайса sizes не положительны
+// или количество функций активации не равно количеству слоев без входного.
+func NewNeuralNetworkLayers(sizes []int, actFuncs []Activation) NeuralNetwork {
+	return NewNeuralNetworkLayersFrom(sizes, actFuncs, matrix.Float64, nil)
+}
+
+// NewNeuralNetworkLayersFrom работает так же, как NewNeuralNetworkLayers, но веса и смещения сети
+// хранятся с типом элементов dtype и инициализируются генератором rng (см. NewNeuralNetworkFrom).
+// Функция вызывает панику, если элементы слайса sizes не положительны
+// или количество функций активации не равно количеству слоев без входного.
+func NewNeuralNetworkLayersFrom(sizes []int, actFuncs []Activation, dtype matrix.DType, rng *rand.Rand) NeuralNetwork {
 	//
 	numLayers := len(sizes)
 	for i := 0; i < numLayers; i++ {
@@ -43,13 +107,17 @@ func NewNeuralNetwork(sizes []int, actFunc activationFunc) NeuralNetwork {
 		}
 	}
 
+	if len(actFuncs) != numLayers-1 {
+		panic("Incorrect number of activation functions")
+	}
+
 	biases := make([]matrix.Matrix, numLayers-1)
 	weights := make([]matrix.Matrix, numLayers-1)
 
 	// генерируем веса и смещения с математическим ожиданием 0 и стандартным отклонением 0.01
 	for i := 0; i < numLayers-1; i++ {
-		biases[i] = matrix.RandMatrix(sizes[i+1], 1)
-		weights[i] = matrix.RandMatrix(sizes[i+1], sizes[i])
+		biases[i] = matrix.RandMatrixFrom(sizes[i+1], 1, dtype, rng)
+		weights[i] = matrix.RandMatrixFrom(sizes[i+1], sizes[i], dtype, rng)
 	}
 
 	return NeuralNetwork{
@@ -57,36 +125,275 @@ func NewNeuralNetwork(sizes []int, actFunc activationFunc) NeuralNetwork {
 		sizes:             sizes,
 		biases:            biases,
 		weights:           weights,
-		actFunc:           actFunc,
+		actFuncs:          append([]Activation{}, actFuncs...),
 		haveNormalization: false,
+		rng:               rng,
+	}
+}
+
+// SetRand устанавливает генератор rng, которым метод Sgd перемешивает датафрейм,
+// например для сети, считанной из файла.
+// Если rng равен nil, используется глобальный генератор пакета math/rand.
+func (nn *NeuralNetwork) SetRand(rng *rand.Rand) {
+	nn.rng = rng
+}
+
+// SetAutodiff включает (при enabled равном true) вычисление градиентов методом Sgd автоматическим
+// дифференцированием (пакет autodiff) вместо обратного распространения с выведенными вручную производными.
+// Результаты обучения совпадают с точностью до ошибок округления, автоматическое дифференцирование
+// выделяет память на каждом наблюдении и поэтому медленнее. Настройка не сохраняется при записи сети.
+func (nn *NeuralNetwork) SetAutodiff(enabled bool) {
+	nn.useAutodiff = enabled
+}
+
+// SetLoss устанавливает функцию потерь loss, градиент которой используется методом Sgd
+// для ошибки на выходном слое и значение которой возвращает метод AverageLoss.
+// Если loss равна nil, используется функция потерь по умолчанию (см. GetLoss).
+// Функция потерь не записывается методами Write, WriteBinary и WriteNpz: у прочитанной сети
+// функция потерь по умолчанию, и перед продолжением обучения ее нужно снова установить методом SetLoss.
+func (nn *NeuralNetwork) SetLoss(loss Loss) {
+	nn.loss = loss
+}
+
+// GetLoss возвращает функцию потерь нейронной сети (структуры NeuralNetwork).
+// Если функция потерь не установлена методом SetLoss, то для сигмоиды на выходном слое
+// возвращается BinaryCrossEntropy, для Softmax CategoricalCrossEntropy, для остальных функций активации MSE.
+func (nn *NeuralNetwork) GetLoss() Loss {
+	if nn.loss != nil {
+		return nn.loss
 	}
+	return defaultLoss(nn.actFuncs[len(nn.actFuncs)-1])
 }
 
-// feedforward возвращает матрицу (структуру Matrix) результат нейронной сети (структуры NeuralNetwork)
+// DType возвращает тип элементов весов и смещений нейронной сети (структуры NeuralNetwork).
+func (nn *NeuralNetwork) DType() matrix.DType {
+	if len(nn.weights) == 0 {
+		return matrix.Float64
+	}
+	return nn.weights[0].DType()
+}
+
+// AsType возвращает копию нейронной сети (структуры NeuralNetwork), веса, смещения и вектор нормализации
+// которой преобразованы к типу элементов dtype.
+// Исходная нейронная сеть не изменяется.
+func (nn *NeuralNetwork) AsType(dtype matrix.DType) NeuralNetwork {
+	res := *nn
+
+	res.sizes = make([]int, len(nn.sizes))
+	copy(res.sizes, nn.sizes)
+
+	res.actFuncs = append([]Activation{}, nn.actFuncs...)
+
+	res.weights = make([]matrix.Matrix, len(nn.weights))
+	res.biases = make([]matrix.Matrix, len(nn.biases))
+	for i := range nn.weights {
+		res.weights[i] = nn.weights[i].AsType(dtype)
+		res.biases[i] = nn.biases[i].AsType(dtype)
+	}
+
+	if nn.haveNormalization {
+		res.norm = nn.norm.AsType(dtype)
+	}
+
+	return res
+}
+
+// Predict возвращает выход нейронной сети (структуры NeuralNetwork) для вектора признаков x (матрицы размерности n на 1)
+// и ошибку.
+// Вектор x не изменяется.
+// Метод возвращает *matrix.ShapeError, если размерность x не равна n на 1, где n количество входных нейронов нейронной сети.
+func (nn *NeuralNetwork) Predict(x matrix.Matrix) (matrix.Matrix, error) {
+	return nn.feedforward(x.AsType(nn.DType()))
+}
+
+// PredictSparse возвращает выход нейронной сети (структуры NeuralNetwork) для разреженного вектора признаков x
+// (разреженной матрицы размерности n на 1) и ошибку.
+// Первый слой перебирает только ненулевые признаки, вектор x не преобразуется в плотную матрицу.
+// Метод возвращает *matrix.ShapeError, если размерность x не равна n на 1, где n количество входных нейронов нейронной сети.
+func (nn *NeuralNetwork) PredictSparse(x matrix.Sparse) (matrix.Matrix, error) {
+	return nn.feedforwardInput(sparseInput{x: x})
+}
+
+// PredictProba возвращает вероятности классов (вектор размерности m на 1, элементы которого в сумме дают 1,
+// где m количество выходных нейронов) для вектора признаков x и ошибку.
+// Вектор x не изменяется.
+// Для Softmax на выходном слое вероятности равны выходу сети, для сигмоиды выходы нейронов считаются
+// независимыми бинарными классификаторами (один против остальных) и нормируются на их сумму.
+// Для сети с одним выходным нейроном с сигмоидой, выход которого p является вероятностью класса 1,
+// возвращается вектор размерности 2 на 1 вероятностей классов 0 и 1: (1 - p, p).
+// Метод возвращает ErrNoProbabilities для других функций активации выходного слоя
+// и *matrix.ShapeError, если размерность x не равна n на 1, где n количество входных нейронов нейронной сети.
+func (nn *NeuralNetwork) PredictProba(x matrix.Matrix) (matrix.Matrix, error) {
+	return nn.probabilities(denseInput{x: x.AsType(nn.DType())})
+}
+
+// PredictProbaSparse работает так же, как PredictProba, но для разреженного вектора признаков x (см. PredictSparse).
+func (nn *NeuralNetwork) PredictProbaSparse(x matrix.Sparse) (matrix.Matrix, error) {
+	return nn.probabilities(sparseInput{x: x})
+}
+
+// givesProbabilities возвращает true, если по выходу нейронной сети вычисляются вероятности классов,
+// то есть функция активации выходного слоя Softmax или Sigmoid.
+func (nn *NeuralNetwork) givesProbabilities() bool {
+	switch nn.actFuncs[len(nn.actFuncs)-1].(type) {
+	case Softmax, Sigmoid:
+		return true
+	}
+	return false
+}
+
+// probabilities возвращает вероятности классов для плотного или разреженного вектора признаков (см. PredictProba).
+func (nn *NeuralNetwork) probabilities(in input) (matrix.Matrix, error) {
+	actFunc := nn.actFuncs[len(nn.actFuncs)-1]
+	if !nn.givesProbabilities() {
+		return matrix.Matrix{}, fmt.Errorf("%w: %s", ErrNoProbabilities, actFunc.GetName())
+	}
+	_, sigmoid := actFunc.(Sigmoid)
+
+	out, err := nn.feedforwardInput(in)
+	if err != nil {
+		return matrix.Matrix{}, err
+	}
+	if !sigmoid {
+		return out, nil
+	}
+
+	if out.GetRows() == 1 {
+		p := out.GetIJ(0, 0)
+		res := matrix.ZeroOf(2, 1, out.DType())
+		res.SetIJ(0, 0, 1-p)
+		res.SetIJ(1, 0, p)
+		return res, nil
+	}
+
+	// при всех выходах, равных 0, классы считаются равновероятными
+	if sum := out.Sum(); sum > 0 {
+		out.ScaleInPlace(1 / sum)
+	} else {
+		out.Fill(1 / float64(out.GetRows()))
+	}
+
+	return out, nil
+}
+
+// feedforward возвращает матрицу (структуру Matrix) результат нейронной сети (структуры NeuralNetwork) и ошибку.
 // Метод реализует прямое распространение.
-// Метод вызывает панику, если количество строк исходного вектора (матрицы x) не ровняется
+// Если тип элементов x отличается от типа элементов сети, то вычисления производятся над копией x,
+// приведенной к типу элементов сети.
+// Метод возвращает *matrix.ShapeError, если количество строк исходного вектора (матрицы x) не ровняется
 // количеству входных нейронов нейронной сети.
-func (nn *NeuralNetwork) feedforward(x matrix.Matrix) matrix.Matrix {
-	if x.GetRows() != nn.sizes[0] || x.GetColumns() != 1 {
-		panic(fmt.Sprintf("Dimension of the input matrix must be %d * %d", nn.sizes[0], 1))
+func (nn *NeuralNetwork) feedforward(x matrix.Matrix) (matrix.Matrix, error) {
+	// вектор признаков приводится к типу элементов нейронной сети
+	if x.DType() != nn.DType() {
+		x = x.AsType(nn.DType())
+	}
+
+	return nn.feedforwardInput(denseInput{x: x})
+}
+
+// feedforwardInput реализует прямое распространение для плотного или разреженного вектора признаков.
+// Метод возвращает *matrix.ShapeError, если количество строк вектора признаков не ровняется
+// количеству входных нейронов нейронной сети.
+func (nn *NeuralNetwork) feedforwardInput(in input) (matrix.Matrix, error) {
+	if err := nn.checkInput(in); err != nil {
+		return matrix.Matrix{}, err
 	}
 
 	// если включена нормализация то приводим к нормализованному виду вектор признаков
 	if nn.haveNormalization {
-		x.HadamardProductInPlace(nn.norm)
+		var err error
+		if in, err = in.normalize(nn.norm); err != nil {
+			return matrix.Matrix{}, err
+		}
 	}
 
+	var x matrix.Matrix
+	var err error
+
 	// вычисление производится рекуррентно
 	for i := 0; i < nn.numLayers-1; i++ {
 
 		// a = activation_function(w * a + b)
 
-		x = nn.weights[i].Dot(x)
-		x.AddInPlace(nn.biases[i])
-		x.ForEachInPlace(nn.actFunc.fnc)
+		if i == 0 {
+			x, err = in.weigh(nn.weights[i])
+		} else {
+			x, err = nn.weights[i].TryDot(x)
+		}
+		if err != nil {
+			return matrix.Matrix{}, err
+		}
+
+		if err := x.TryAddInPlace(nn.biases[i]); err != nil {
+			return matrix.Matrix{}, err
+		}
+		activate(x, x, nn.actFuncs[i], nn.actFuncs[i].Fnc)
+	}
+
+	return x, nil
+}
+
+// checkInput возвращает *matrix.ShapeError, если размерность вектора признаков in не равна n на 1,
+// где n количество входных нейронов нейронной сети.
+func (nn *NeuralNetwork) checkInput(in input) error {
+	if in.getRows() != nn.sizes[0] || in.getColumns() != 1 {
+		return &matrix.ShapeError{
+			Op: "feedforward",
+			A:  matrix.Shape{Rows: nn.sizes[0], Columns: 1},
+			B:  matrix.Shape{Rows: in.getRows(), Columns: in.getColumns()},
+		}
+	}
+	return nil
+}
+
+// checkTarget возвращает *matrix.ShapeError, если размерность целевой переменной y не равна n на 1,
+// где n количество выходных нейронов нейронной сети.
+func (nn *NeuralNetwork) checkTarget(y matrix.Matrix) error {
+	if outputs := nn.sizes[nn.numLayers-1]; y.GetRows() != outputs || y.GetColumns() != 1 {
+		return &matrix.ShapeError{
+			Op: "backProp",
+			A:  matrix.Shape{Rows: outputs, Columns: 1},
+			B:  matrix.Shape{Rows: y.GetRows(), Columns: y.GetColumns()},
+		}
+	}
+	return nil
+}
+
+// checkParameters возвращает ошибку, если параметры нейронной сети не соответствуют количеству нейронов слоев sizes:
+// количество весов или смещений не равно numLayers-1, веса i слоя не имеют размерность sizes[i+1] на sizes[i],
+// смещения не имеют размерность sizes[i+1] на 1, вектор нормализации (если она включена) не имеет размерность
+// sizes[0] на 1 или типы элементов параметров различаются.
+func (nn *NeuralNetwork) checkParameters() error {
+	if nn.numLayers < 2 || len(nn.sizes) != nn.numLayers {
+		return fmt.Errorf("incorrect number of layers")
+	}
+	if len(nn.weights) != nn.numLayers-1 || len(nn.biases) != nn.numLayers-1 {
+		return fmt.Errorf("expected %d weights and biases, got %d and %d", nn.numLayers-1, len(nn.weights), len(nn.biases))
+	}
+
+	dtype := nn.weights[0].DType()
+	for i, w := range nn.weights {
+		if w.GetRows() != nn.sizes[i+1] || w.GetColumns() != nn.sizes[i] {
+			return fmt.Errorf("weights of layer %d have shape %d*%d, expected %d*%d", i, w.GetRows(), w.GetColumns(), nn.sizes[i+1], nn.sizes[i])
+		}
+		if b := nn.biases[i]; b.GetRows() != nn.sizes[i+1] || b.GetColumns() != 1 {
+			return fmt.Errorf("biases of layer %d have shape %d*%d, expected %d*1", i, b.GetRows(), b.GetColumns(), nn.sizes[i+1])
+		}
+		if w.DType() != dtype || nn.biases[i].DType() != dtype {
+			return fmt.Errorf("parameters of layer %d are not %v", i, dtype)
+		}
 	}
 
-	return x
+	if nn.haveNormalization {
+		if nn.norm.GetRows() != nn.sizes[0] || nn.norm.GetColumns() != 1 {
+			return fmt.Errorf("normalization vector has shape %d*%d, expected %d*1", nn.norm.GetRows(), nn.norm.GetColumns(), nn.sizes[0])
+		}
+		if nn.norm.DType() != dtype {
+			return fmt.Errorf("normalization vector is not %v", dtype)
+		}
+	}
+
+	return nil
 }
 
 // Sgd реализует стохастический градиентный спуск.
@@ -97,26 +404,48 @@ func (nn *NeuralNetwork) feedforward(x matrix.Matrix) matrix.Matrix {
 // lmd коэффициент регуляризации L2,
 // isPrintEpoch если true то печатает текущую эпоху,
 // haveNormalization если true то выполняет нормализацию.
-// Предупреждение: метод может вызвать панику,
-// если ошибка для выходного слоя не определена в методе нейронной сети (структуре NeuralNetwork) backProp,
-// поскольку внутренне вызывается функция backProp, требующая явного определения
-// ошибки для выходного слоя для каждой активационной функции.
-// Функция вызывает панику, если возникла ошибка при нормализации дата сета.
-func (nn *NeuralNetwork) Sgd(dataTrain *data_frame.DataFrame, epochs int, miniBatchSize int, eta float64, lmd float64, isPrintEpoch, haveNormalization bool) {
+// Минимизируется функция потерь сети (см. SetLoss и GetLoss).
+// Метод возвращает ошибку до начала обучения, если epochs, eta или lmd отрицательны, miniBatchSize не положителен
+// или параметры сети не согласованы с количеством нейронов слоев,
+// а также если возникла ошибка при нормализации дата сета
+// или размерность наблюдения не соответствует нейронной сети (*matrix.ShapeError),
+// при этом веса обновлены мини батчами, обработанными до ошибки.
+// Если тип элементов датафрейма отличается от типа элементов сети, то каждое наблюдение приводится
+// к типу элементов сети при обработке, для ускорения датафрейм можно заранее преобразовать методом DataFrame.AsType.
+// Датафрейм перемешивается генератором сети (см. NewNeuralNetworkFrom и SetRand).
+// За эпоху каждое наблюдение обрабатывается ровно один раз: если длина датафрейма не делится на miniBatchSize,
+// то последний мини батч содержит оставшиеся наблюдения.
+func (nn *NeuralNetwork) Sgd(dataTrain *data_frame.DataFrame, epochs int, miniBatchSize int, eta float64, lmd float64, isPrintEpoch, haveNormalization bool) error {
+	switch {
+	case epochs < 0:
+		return fmt.Errorf("number of epochs must not be negative, got %d", epochs)
+	case miniBatchSize <= 0:
+		return fmt.Errorf("mini batch size must be positive, got %d", miniBatchSize)
+	case !(eta >= 0):
+		return fmt.Errorf("learning rate must not be negative, got %v", eta)
+	case !(lmd >= 0):
+		return fmt.Errorf("regularization coefficient must not be negative, got %v", lmd)
+	}
+	if err := nn.checkParameters(); err != nil {
+		return err
+	}
 
 	// если включена нормализация, то выполняем нормализацию и сохраняем
 	// вектор максимальных значений по модулю по всем признакам наблюдений
 	if haveNormalization {
 		norm, err := dataTrain.Normalization()
 		if err != nil {
-			panic(err)
+			return err
 		}
 
 		// устанавливаем параметры
-		nn.norm = norm
+		nn.norm = norm.AsType(nn.DType())
 		nn.haveNormalization = true
 	}
 
+	// буферы обучения создаются один раз и переиспользуются всеми мини батчами
+	tr := newTrainer(nn)
+
 	for epoch := 0; epoch < epochs; epoch++ {
 
 		// вывод текущей эпохи
@@ -125,128 +454,25 @@ func (nn *NeuralNetwork) Sgd(dataTrain *data_frame.DataFrame, epochs int, miniBa
 		}
 
 		// перемешивание датафрейма
-		dataTrain.Shuffle()
-
-		// разбиваем датафрейм на части длины которых равны miniBatchSize и на основе каждой такой части обновляем веса
-		for i := 0; i < dataTrain.Lenght(); i += miniBatchSize {
-			nn.updateMiniBatch(dataTrain.CopyMiniBatch(i, miniBatchSize), eta, lmd, len(dataTrain.Data))
+		dataTrain.ShuffleFrom(nn.rng)
+
+		// разбиваем датафрейм на части длины которых равны miniBatchSize и на основе каждой такой части обновляем веса,
+		// неполная последняя часть сюда не попадает: она обрабатывается ниже как остаток
+		for i := 0; i+miniBatchSize <= dataTrain.Lenght(); i += miniBatchSize {
+			if err := tr.updateMiniBatch(dataTrain.CopyMiniBatch(i, miniBatchSize), eta, lmd, len(dataTrain.Data)); err != nil {
+				return err
+			}
 		}
 
 		// обрабатываем остаток
 		if dataTrain.Lenght()%miniBatchSize != 0 {
 			start := (dataTrain.Lenght() / miniBatchSize) * miniBatchSize
 			length := dataTrain.Lenght() - start
-			nn.updateMiniBatch(dataTrain.CopyMiniBatch(start, length), eta, lmd, len(dataTrain.Data))
-		}
-	}
-}
-
-// updateMiniBatch обновляет веса и смещения исходной нейронной сети.
-// eta скорость обучения,
-// lmd коэффициент регуляризации L2,
-// lenDf размер датафрейма на котором производится обучение.
-func (nn *NeuralNetwork) updateMiniBatch(miniBatch data_frame.DataFrame, eta float64, lmd float64, lenDf int) {
-
-	// создаем массивы из нулевых матриц такой же размерности что и веса и смещения нейронной сети,
-	// чтобы считать градиент по miniBatch
-	nablaWeights := matrix.Zeros(&nn.weights)
-	nablaBiases := matrix.Zeros(&nn.biases)
-
-	// используем обратное распространение чтобы найти градиент по каждому наблюдению из miniBatch
-	for i := 0; i < miniBatch.Lenght(); i++ {
-		deltaNablaBiases, deltaNablaWeights := nn.backProp(miniBatch.GetRow(i))
-
-		// считаем градиент для весов по miniBatch
-		for j := 0; j < len(*nablaWeights); j++ {
-			(*nablaWeights)[j].AddInPlace((*deltaNablaWeights)[j])
-		}
-
-		// считаем градиент для смещений по miniBatch
-		for j := 0; j < len(*nablaBiases); j++ {
-			(*nablaBiases)[j].AddInPlace((*deltaNablaBiases)[j])
+			if err := tr.updateMiniBatch(dataTrain.CopyMiniBatch(start, length), eta, lmd, len(dataTrain.Data)); err != nil {
+				return err
+			}
 		}
 	}
 
-	// считаем коэффициенты для регуляризации L2
-	k := eta / float64(miniBatch.Lenght())
-	wk := 1. - eta*(lmd/float64(lenDf))
-
-	// обновляем веса
-	for j := 0; j < len(nn.weights); j++ {
-		nn.weights[j].ForEachInPlace(func(w float64) float64 {
-			return w * wk
-		})
-
-		(*nablaWeights)[j].ForEachInPlace(func(w float64) float64 {
-			return w * k
-		})
-
-		nn.weights[j].SubInPlace((*nablaWeights)[j])
-	}
-
-	// обновляем смещения
-	for j := 0; j < len(nn.biases); j++ {
-		(*nablaBiases)[j].ForEachInPlace(func(w float64) float64 {
-			return w * k
-		})
-
-		nn.biases[j].SubInPlace((*nablaBiases)[j])
-	}
-
-}
-
-// backProp возвращает градиенты по одному наблюдению.
-// Реализует обратное распространение.
-// x вектор признаков наблюдения,
-// y целевая переменная наблюдения представленная виде матрицы.
-func (nn *NeuralNetwork) backProp(x matrix.Matrix, y matrix.Matrix) (*[]matrix.Matrix, *[]matrix.Matrix) {
-
-	// храним градиенты для одного наблюдения
-	nablaWeights := matrix.Zeros(&nn.weights)
-	nablaBiases := matrix.Zeros(&nn.biases)
-
-	activation := x
-
-	// храним активации слоев
-	activations := make([]matrix.Matrix, nn.numLayers)
-	activations[0] = activation
-
-	// храним взвешенную сумму слоев
-	zs := make([]matrix.Matrix, nn.numLayers-1)
-
-	// заполняем activations и zs
-	for i := 0; i < nn.numLayers-1; i++ {
-
-		z := nn.weights[i].Dot(activation)
-		z.AddInPlace(nn.biases[i])
-
-		zs[i] = z
-
-		activation = z.ForEach(nn.actFunc.fnc)
-
-		activations[i+1] = activation
-	}
-
-	// ошибка для выходного слоя
-	delta := nn.actFunc.getDelta(zs[len(zs)-1], activations[len(activations)-1], y)
-
-	// находим градиенты в выходном слое
-	(*nablaBiases)[len(*nablaBiases)-1] = delta
-
-	(*nablaWeights)[len(*nablaWeights)-1] = delta.Dot(activations[len(activations)-2].T())
-
-	// находим градиенты в остальных весах
-	for i := 2; i < nn.numLayers; i++ {
-		z := zs[len(zs)-i]
-
-		delta = nn.weights[len(nn.weights)-i+1].T().Dot(delta)
-		delta.HadamardProductInPlace(z.ForEach(nn.actFunc.prime))
-
-		(*nablaBiases)[len(*nablaBiases)-i] = delta
-
-		(*nablaWeights)[len(*nablaWeights)-i] = delta.Dot(activations[len(activations)-i-1].T())
-
-	}
-
-	return nablaBiases, nablaWeights
+	return nil
 }
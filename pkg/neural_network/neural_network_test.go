@@ -1,6 +1,13 @@
 package neural_network
 
 import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/Davmgiz/GoblinNeuronet/pkg/data_frame"
@@ -51,7 +58,9 @@ func TestSvg(t *testing.T) {
 
 	dfTrain.Num2Vec(2)
 
-	nn.Sgd(&dfTrain, 200, 7, 0.5, 5, false, false)
+	if err := nn.Sgd(&dfTrain, 200, 7, 0.5, 5, false, false); err != nil {
+		t.Fatal(err)
+	}
 
 	expectedWeights := []matrix.Matrix{
 		matrix.DataToMatrix([][]float64{
@@ -138,7 +147,10 @@ func TestFeedForward(t *testing.T) {
 		{3.},
 	})
 
-	result := nn.feedforward(x)
+	result, err := nn.feedforward(x)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	expected := matrix.DataToMatrix([][]float64{
 		{0.50175975},
@@ -160,3 +172,1113 @@ func TestFeedForward(t *testing.T) {
 		t.Errorf("It is forbidden to change original matrix")
 	}
 }
+
+// TestFloat32 проверяет обучение и запись нейронной сети (структуры NeuralNetwork) с элементами float32.
+// Результат обучения сравнивается с той же сетью с элементами float64.
+func TestFloat32(t *testing.T) {
+	nn64 := NewNeuralNetwork([]int{4, 3, 2}, Sigmoid{})
+	nn32 := nn64.AsType(matrix.Float32)
+
+	if nn32.DType() != matrix.Float32 || nn64.DType() != matrix.Float64 {
+		t.Fatalf("Incorrect element types of neural network")
+	}
+
+	dfTrain, err := data_frame.ReadCSV("../../data/neural_network_test/test_svg_data_train.csv", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dfTrain.Num2Vec(2)
+
+	// обе сети обучаются без перемешивания, на одном мини батче
+	if err := newTrainer(&nn64).updateMiniBatch(dfTrain, 0.5, 5, dfTrain.Lenght()); err != nil {
+		t.Fatal(err)
+	}
+	if err := newTrainer(&nn32).updateMiniBatch(dfTrain.AsType(matrix.Float32), 0.5, 5, dfTrain.Lenght()); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range nn64.weights {
+		if nn32.weights[i].DType() != matrix.Float32 {
+			t.Errorf("Weights must stay float32 after training")
+		}
+
+		for r := 0; r < nn64.weights[i].GetRows(); r++ {
+			for c := 0; c < nn64.weights[i].GetColumns(); c++ {
+				if d := nn64.weights[i].GetIJ(r, c) - nn32.weights[i].GetIJ(r, c); d > 1e-4 || d < -1e-4 {
+					t.Fatalf("Dont equal float32 and float64 weights")
+				}
+			}
+		}
+	}
+
+	// тип элементов сохраняется при записи и чтении параметров
+	buf := new(bytes.Buffer)
+	if err := nn32.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	nnRead, err := Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if nnRead.DType() != matrix.Float32 {
+		t.Errorf("Expected float32 neural network after reading, got %v", nnRead.DType())
+	}
+
+	// accuracy сети float32, обученной с нормализацией, на датафрейме float64 не изменяет датафрейм
+	if err := nn32.Sgd(&dfTrain, 2, 7, 0.5, 5, false, true); err != nil {
+		t.Fatal(err)
+	}
+	dfTest, err := data_frame.ReadCSV("../../data/neural_network_test/test_svg_data_test.csv", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := dfTest.Data[0].GetX().AsType(matrix.Float64)
+	acc := nn32.Accuracy(dfTest)
+	if acc < 0 || acc > 100 || nn32.Accuracy(dfTest) != acc {
+		t.Errorf("Incorrect accuracy of float32 neural network %v", acc)
+	}
+	if !matrix.IsMatrixesEqual(dfTest.Data[0].GetX(), x) || dfTest.Data[0].GetX().DType() != matrix.Float64 {
+		t.Errorf("It is forbidden to change original data frame")
+	}
+}
+
+// TestSparseInput проверяет обучение и предсказание нейронной сети (структуры NeuralNetwork)
+// на разреженных векторах признаков.
+// Результат сравнивается с той же сетью, обученной на тех же векторах признаков в плотном виде.
+func TestSparseInput(t *testing.T) {
+	nnDense := NewNeuralNetwork([]int{6, 4, 3, 2}, Sigmoid{})
+	nnSparse := nnDense.AsType(matrix.Float64)
+
+	dfDense := data_frame.DataFrame{}
+	dfSparse := data_frame.DataFrame{}
+
+	for i := 0; i < 8; i++ {
+		x := matrix.Zero(6, 1)
+		x.SetIJ(i%6, 0, 1.)
+		x.SetIJ((3*i+1)%6, 0, 0.5)
+
+		y := matrix.Zero(2, 1)
+		y.SetIJ(i%2, 0, 1.)
+
+		dfDense.Append(x, y)
+		dfSparse.AppendSparse(matrix.SparseFromMatrix(x), y)
+	}
+
+	if err := newTrainer(&nnDense).updateMiniBatch(dfDense, 0.5, 5, dfDense.Lenght()); err != nil {
+		t.Fatal(err)
+	}
+	if err := newTrainer(&nnSparse).updateMiniBatch(dfSparse, 0.5, 5, dfSparse.Lenght()); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range nnDense.weights {
+		if !isClose(nnDense.weights[i], nnSparse.weights[i]) || !isClose(nnDense.biases[i], nnSparse.biases[i]) {
+			t.Fatalf("Dont equal parameters after training on dense and sparse data")
+		}
+	}
+
+	x, _ := dfDense.GetRow(3)
+	predDense, err := nnDense.Predict(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	predSparse, err := nnSparse.PredictSparse(dfSparse.Data[3].GetSparseX())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !isClose(predDense, predSparse) {
+		t.Errorf("Dont equal predictions on dense and sparse data")
+	}
+
+	if nnDense.Accuracy(dfDense) != nnSparse.Accuracy(dfSparse) {
+		t.Errorf("Dont equal accuracy on dense and sparse data")
+	}
+}
+
+// TestShapeErrors проверяет, что прямое распространение и обучение нейронной сети (структуры NeuralNetwork)
+// возвращают *matrix.ShapeError для наблюдений неверной размерности вместо паники.
+func TestShapeErrors(t *testing.T) {
+	nn := NewNeuralNetwork([]int{4, 3, 2}, Sigmoid{})
+	weights := nn.AsType(matrix.Float64).weights
+
+	var shapeErr *matrix.ShapeError
+
+	if _, err := nn.Predict(matrix.Zero(5, 1)); !errors.As(err, &shapeErr) {
+		t.Errorf("Predict: expected *matrix.ShapeError, got %v", err)
+	}
+
+	sparseX, err := matrix.SparseFromTriplets(3, 1, []int{0}, []int{0}, []float64{1.})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := nn.PredictSparse(sparseX); !errors.As(err, &shapeErr) {
+		t.Errorf("PredictSparse: expected *matrix.ShapeError, got %v", err)
+	}
+
+	// признаки верной размерности, целевая переменная неверной
+	df := data_frame.DataFrame{}
+	df.Append(matrix.Zero(4, 1), matrix.Zero(3, 1))
+
+	if err := nn.Sgd(&df, 1, 1, 0.5, 5, false, false); !errors.As(err, &shapeErr) {
+		t.Errorf("Sgd: expected *matrix.ShapeError, got %v", err)
+	}
+
+	// признаки неверной размерности
+	df = data_frame.DataFrame{}
+	df.Append(matrix.Zero(4, 1), matrix.Zero(2, 1))
+	df.Append(matrix.Zero(2, 1), matrix.Zero(2, 1))
+
+	if err := nn.Sgd(&df, 1, 2, 0.5, 5, false, false); !errors.As(err, &shapeErr) {
+		t.Errorf("Sgd: expected *matrix.ShapeError, got %v", err)
+	}
+
+	// мини батч с ошибкой не изменяет веса
+	for i := range weights {
+		if !matrix.IsMatrixesEqual(weights[i], nn.weights[i]) {
+			t.Errorf("Weights must not change on error")
+		}
+	}
+}
+
+// isClose вспомогательная функция для тестов.
+// Возвращает true, если размерности матриц равны, а элементы отличаются не более чем на 1e-12.
+func isClose(A, B matrix.Matrix) bool {
+	if A.GetRows() != B.GetRows() || A.GetColumns() != B.GetColumns() {
+		return false
+	}
+
+	for i := 0; i < A.GetRows(); i++ {
+		for j := 0; j < A.GetColumns(); j++ {
+			if d := A.GetIJ(i, j) - B.GetIJ(i, j); d > 1e-12 || d < -1e-12 {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// TestSgdArguments проверяет, что метод Sgd возвращает ошибку без изменения весов при некорректных аргументах
+// и при параметрах сети, не согласованных с количеством нейронов слоев.
+func TestSgdArguments(t *testing.T) {
+	nn := NewNeuralNetwork([]int{4, 3, 2}, Sigmoid{})
+	weights := nn.AsType(matrix.Float64).weights
+
+	df := data_frame.DataFrame{}
+	df.Append(matrix.Zero(4, 1), matrix.Zero(2, 1))
+
+	args := []struct {
+		name          string
+		epochs, batch int
+		eta, lmd      float64
+	}{
+		{"zero mini batch size", 1, 0, 0.5, 1},
+		{"negative mini batch size", 1, -2, 0.5, 1},
+		{"negative epochs", -1, 1, 0.5, 1},
+		{"negative eta", 1, 1, -0.5, 1},
+		{"NaN eta", 1, 1, math.NaN(), 1},
+		{"negative lmd", 1, 1, 0.5, -1},
+	}
+
+	for _, a := range args {
+		if err := nn.Sgd(&df, a.epochs, a.batch, a.eta, a.lmd, false, false); err == nil {
+			t.Errorf("Sgd with %s: expected error", a.name)
+		}
+	}
+
+	for i := range weights {
+		if !matrix.IsMatrixesEqual(weights[i], nn.weights[i]) {
+			t.Errorf("Weights must not change on error")
+		}
+	}
+
+	// веса, не соответствующие количеству нейронов слоев
+	nn.weights[1] = matrix.Zero(2, 4)
+	if err := nn.Sgd(&df, 1, 1, 0.5, 1, false, false); err == nil {
+		t.Errorf("Sgd with inconsistent weights: expected error")
+	}
+}
+
+// TestUpdateMiniBatchAllocs проверяет, что обучение на мини батче после первого мини батча
+// не выделяет память для плотных и разреженных векторов признаков.
+// Слои сети небольшие, поэтому операции выполняются последовательно без передачи задач исполнителям.
+func TestUpdateMiniBatchAllocs(t *testing.T) {
+	for _, sparse := range []bool{false, true} {
+		nn := NewNeuralNetwork([]int{20, 16, 8, 4}, Sigmoid{})
+
+		df := data_frame.DataFrame{}
+		for i := 0; i < 10; i++ {
+			x := matrix.RandMatrix(20, 1)
+			y := matrix.Zero(4, 1)
+			y.SetIJ(i%4, 0, 1.)
+
+			if sparse {
+				df.AppendSparse(matrix.SparseFromMatrix(x), y)
+			} else {
+				df.Append(x, y)
+			}
+		}
+
+		tr := newTrainer(&nn)
+
+		allocs := testing.AllocsPerRun(10, func() {
+			if err := tr.updateMiniBatch(df, 0.5, 5, df.Lenght()); err != nil {
+				t.Fatal(err)
+			}
+		})
+
+		if allocs != 0 {
+			t.Errorf("sparse=%v: expected no allocations per mini batch, got %v", sparse, allocs)
+		}
+	}
+}
+
+// TestReproducibleSgd проверяет, что сети, созданные генераторами с одинаковым начальным значением
+// и обученные на одинаковых данных, имеют побитово равные веса и смещения,
+// а разные начальные значения дают разные параметры.
+func TestReproducibleSgd(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	df := data_frame.DataFrame{}
+	for i := 0; i < 25; i++ {
+		y := matrix.Zero(2, 1)
+		y.SetIJ(i%2, 0, 1.)
+		df.Append(matrix.RandMatrixFrom(5, 1, matrix.Float64, rng), y)
+	}
+
+	train := func(seed int64) NeuralNetwork {
+		nn := NewNeuralNetworkFrom([]int{5, 4, 2}, Sigmoid{}, matrix.Float64, rand.New(rand.NewSource(seed)))
+		dfTrain := data_frame.DataFrame{Data: append(df.Data[:0:0], df.Data...)}
+		if err := nn.Sgd(&dfTrain, 5, 4, 0.5, 1, false, false); err != nil {
+			t.Fatal(err)
+		}
+		return nn
+	}
+
+	nn1, nn2, nn3 := train(7), train(7), train(8)
+
+	for i := range nn1.weights {
+		if !isBitEqual(nn1.weights[i], nn2.weights[i]) || !isBitEqual(nn1.biases[i], nn2.biases[i]) {
+			t.Fatalf("Parameters of networks trained with equal seeds are different")
+		}
+	}
+	if isBitEqual(nn1.weights[0], nn3.weights[0]) {
+		t.Errorf("Parameters of networks trained with different seeds are equal")
+	}
+}
+
+// TestAutodiffSgd проверяет, что обучение с градиентами, вычисленными автоматическим дифференцированием,
+// совпадает с обучением обратным распространением для плотных и разреженных векторов признаков.
+func TestAutodiffSgd(t *testing.T) {
+	for _, sparse := range []bool{false, true} {
+		rng := rand.New(rand.NewSource(3))
+		df := data_frame.DataFrame{}
+		for i := 0; i < 12; i++ {
+			x := matrix.RandMatrixFrom(6, 1, matrix.Float64, rng)
+			x.SetIJ(i%6, 0, 0)
+			y := matrix.Zero(3, 1)
+			y.SetIJ(i%3, 0, 1.)
+			if sparse {
+				df.AppendSparse(matrix.SparseFromMatrix(x), y)
+			} else {
+				df.Append(x, y)
+			}
+		}
+
+		train := func(autodiff bool) NeuralNetwork {
+			nn := NewNeuralNetworkFrom([]int{6, 5, 4, 3}, Sigmoid{}, matrix.Float64, rand.New(rand.NewSource(5)))
+			nn.SetAutodiff(autodiff)
+			dfTrain := data_frame.DataFrame{Data: append(df.Data[:0:0], df.Data...)}
+			if err := nn.Sgd(&dfTrain, 3, 5, 3, 0.1, false, false); err != nil {
+				t.Fatal(err)
+			}
+			return nn
+		}
+
+		manual, auto := train(false), train(true)
+		for i := range manual.weights {
+			if !isClose(manual.weights[i], auto.weights[i]) || !isClose(manual.biases[i], auto.biases[i]) {
+				t.Errorf("Parameters of layer %d trained with autodiff are different, sparse: %v", i, sparse)
+			}
+		}
+	}
+}
+
+// TestSgdPartialMiniBatch проверяет, что при длине датафрейма, не кратной размеру мини батча,
+// эпоха метода Sgd совпадает с последовательным обновлением весов полными мини батчами и остатком,
+// то есть каждое наблюдение обрабатывается ровно один раз.
+func TestSgdPartialMiniBatch(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	df := data_frame.DataFrame{}
+	for i := 0; i < 11; i++ {
+		y := matrix.Zero(2, 1)
+		y.SetIJ(i%2, 0, 1.)
+		df.Append(matrix.RandMatrixFrom(3, 1, matrix.Float64, rng), y)
+	}
+	copyDf := func() data_frame.DataFrame {
+		return data_frame.DataFrame{Data: append(df.Data[:0:0], df.Data...)}
+	}
+
+	nn := NewNeuralNetworkFrom([]int{3, 4, 2}, Sigmoid{}, matrix.Float64, rand.New(rand.NewSource(3)))
+	dfTrain := copyDf()
+	if err := nn.Sgd(&dfTrain, 1, 4, 0.5, 1, false, false); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := NewNeuralNetworkFrom([]int{3, 4, 2}, Sigmoid{}, matrix.Float64, rand.New(rand.NewSource(3)))
+	dfExpected := copyDf()
+	dfExpected.ShuffleFrom(expected.rng)
+	tr := newTrainer(&expected)
+	for _, batch := range [][2]int{{0, 4}, {4, 4}, {8, 3}} {
+		if err := tr.updateMiniBatch(dfExpected.CopyMiniBatch(batch[0], batch[1]), 0.5, 1, df.Lenght()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := range nn.weights {
+		if !isBitEqual(nn.weights[i], expected.weights[i]) || !isBitEqual(nn.biases[i], expected.biases[i]) {
+			t.Fatalf("Sgd with partial mini batch differs from updates with mini batches of 4, 4 and 3 observations")
+		}
+	}
+}
+
+// isBitEqual вспомогательная функция для тестов.
+// Возвращает true, если размерности матриц равны и все элементы совпадают точно.
+func isBitEqual(A, B matrix.Matrix) bool {
+	if A.GetRows() != B.GetRows() || A.GetColumns() != B.GetColumns() {
+		return false
+	}
+
+	for i := 0; i < A.GetRows(); i++ {
+		for j := 0; j < A.GetColumns(); j++ {
+			if A.GetIJ(i, j) != B.GetIJ(i, j) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// TestNpz проверяет запись и чтение параметров нейронной сети (структуры NeuralNetwork) в формате .npz.
+func TestNpz(t *testing.T) {
+	for _, dtype := range []matrix.DType{matrix.Float64, matrix.Float32} {
+		nn := NewNeuralNetworkOf([]int{5, 4, 3, 2}, Sigmoid{}, dtype)
+		nn.norm = matrix.RandMatrixOf(5, 1, dtype)
+		nn.haveNormalization = true
+
+		buf := new(bytes.Buffer)
+		if err := nn.WriteNpz(buf); err != nil {
+			t.Fatal(err)
+		}
+
+		nnRead, err := ReadNpz(bytes.NewReader(buf.Bytes()), int64(buf.Len()), Sigmoid{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if nnRead.DType() != dtype || nnRead.numLayers != 4 || !nnRead.haveNormalization || !isBitEqual(nnRead.norm, nn.norm) {
+			t.Fatalf("Incorrect neural network (%v) after reading .npz", dtype)
+		}
+		for i := range nn.weights {
+			if nnRead.sizes[i] != nn.sizes[i] || !isBitEqual(nnRead.weights[i], nn.weights[i]) || !isBitEqual(nnRead.biases[i], nn.biases[i]) {
+				t.Errorf("Dont equal parameters (%v) of layer %d after reading .npz", dtype, i)
+			}
+		}
+	}
+
+	// несогласованные размерности слоев
+	buf := new(bytes.Buffer)
+	err := matrix.WriteNpz(buf, map[string]matrix.Matrix{
+		"weights_0": matrix.Zero(3, 4),
+		"biases_0":  matrix.Zero(3, 1),
+		"weights_1": matrix.Zero(2, 2),
+		"biases_1":  matrix.Zero(2, 1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadNpz(bytes.NewReader(buf.Bytes()), int64(buf.Len()), Sigmoid{}); err == nil {
+		t.Errorf("Expected error for inconsistent layer sizes")
+	}
+
+	// архив без функций активации, например записанный NumPy, требует явных функций активации
+	buf.Reset()
+	err = matrix.WriteNpz(buf, map[string]matrix.Matrix{
+		"weights_0": matrix.Zero(3, 4),
+		"biases_0":  matrix.Zero(3, 1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadNpz(bytes.NewReader(buf.Bytes()), int64(buf.Len())); err == nil {
+		t.Errorf("Expected error for archive without activation functions")
+	}
+	if _, err := ReadNpz(bytes.NewReader(buf.Bytes()), int64(buf.Len()), Sigmoid{}); err != nil {
+		t.Errorf("Unexpected error for archive with explicit activation functions: %v", err)
+	}
+}
+
+// TestWriteReadBinary проверяет, что параметры нейронной сети (структуры NeuralNetwork),
+// записанные в двоичном формате, читаются без потери точности и сеть дает те же предсказания.
+func TestWriteReadBinary(t *testing.T) {
+	for _, dtype := range []matrix.DType{matrix.Float64, matrix.Float32} {
+		nn := NewNeuralNetworkOf([]int{6, 5, 3}, Sigmoid{}, dtype)
+		nn.norm = matrix.RandMatrixOf(6, 1, dtype)
+		nn.haveNormalization = true
+
+		buf := new(bytes.Buffer)
+		if err := nn.WriteBinary(buf); err != nil {
+			t.Fatal(err)
+		}
+
+		nnRead, err := ReadBinary(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if nnRead.DType() != dtype || nnRead.numLayers != 3 || !nnRead.haveNormalization || !isBitEqual(nnRead.norm, nn.norm) {
+			t.Fatalf("Incorrect neural network (%v) after reading binary format", dtype)
+		}
+		for i := range nn.weights {
+			if !isBitEqual(nnRead.weights[i], nn.weights[i]) || !isBitEqual(nnRead.biases[i], nn.biases[i]) {
+				t.Errorf("Dont equal parameters (%v) of layer %d after reading binary format", dtype, i)
+			}
+		}
+
+		x := matrix.RandMatrixOf(6, 1, dtype)
+		want, err := nn.Predict(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := nnRead.Predict(x)
+		if err != nil || !isBitEqual(got, want) {
+			t.Errorf("Dont equal predictions (%v) after reading binary format: %v", dtype, err)
+		}
+	}
+
+	if _, err := ReadBinary(bytes.NewReader([]byte("2\n1 1\nSigmoid\n0\n\n"))); err == nil {
+		t.Errorf("Expected error for text format")
+	}
+}
+
+// TestReadInconsistentParameters проверяет, что чтение нейронной сети, параметры которой не согласованы
+// с количеством нейронов слоев, возвращает ошибку, а не сеть, падающую при предсказании или обучении.
+func TestReadInconsistentParameters(t *testing.T) {
+	corruptions := map[string]func(nn *NeuralNetwork){
+		"weights shape":       func(nn *NeuralNetwork) { nn.weights[1] = matrix.Zero(2, 4) },
+		"biases shape":        func(nn *NeuralNetwork) { nn.biases[0] = matrix.Zero(3, 2) },
+		"sizes":               func(nn *NeuralNetwork) { nn.sizes[1] = 5 },
+		"missing layer":       func(nn *NeuralNetwork) { nn.weights, nn.biases = nn.weights[:1], nn.biases[:1] },
+		"element type":        func(nn *NeuralNetwork) { nn.biases[1] = nn.biases[1].AsType(matrix.Float32) },
+		"normalization shape": func(nn *NeuralNetwork) { nn.norm, nn.haveNormalization = matrix.Zero(5, 7), true },
+	}
+
+	for name, corrupt := range corruptions {
+		nn := NewNeuralNetwork([]int{4, 3, 2}, Sigmoid{})
+		corrupt(&nn)
+
+		buf := new(bytes.Buffer)
+		if err := nn.Write(buf); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Read(buf); err == nil {
+			t.Errorf("Read with inconsistent %s: expected error", name)
+		}
+	}
+
+	// двоичный формат определяет количество нейронов по весам, поэтому проверяется вектор нормализации
+	for name, norm := range map[string]matrix.Matrix{
+		"normalization shape":        matrix.ZeroOf(5, 7, matrix.Float32),
+		"normalization element type": matrix.ZeroOf(4, 1, matrix.Float32),
+	} {
+		nn := NewNeuralNetwork([]int{4, 3, 2}, Sigmoid{})
+		nn.norm, nn.haveNormalization = norm, true
+
+		buf := new(bytes.Buffer)
+		if err := nn.WriteBinary(buf); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadBinary(buf); err == nil {
+			t.Errorf("ReadBinary with inconsistent %s: expected error", name)
+		}
+	}
+}
+
+// namedActivation функция активации для тестов с произвольным именем.
+type namedActivation struct {
+	Sigmoid
+	name string
+}
+
+func (a namedActivation) GetName() string { return a.name }
+
+// TestWriteBinaryLimits проверяет, что запись в двоичном формате возвращает ошибку,
+// если количество функций активации или длина имени не помещаются в uint16, вместо записи обрезанных значений.
+func TestWriteBinaryLimits(t *testing.T) {
+	nn := NewNeuralNetwork([]int{4, 3, 2}, Sigmoid{})
+	nn.actFuncs[0] = namedActivation{name: strings.Repeat("a", math.MaxUint16+1)}
+	if err := nn.WriteBinary(io.Discard); err == nil {
+		t.Errorf("Expected error for too long activation function name")
+	}
+
+	nn = NewNeuralNetwork([]int{4, 3, 2}, Sigmoid{})
+	nn.actFuncs = make([]Activation, math.MaxUint16+1)
+	for i := range nn.actFuncs {
+		nn.actFuncs[i] = Sigmoid{}
+	}
+	if err := nn.WriteBinary(io.Discard); err == nil {
+		t.Errorf("Expected error for too many activation functions")
+	}
+}
+
+// softsign пользовательская функция активации для проверки реестра функций активации.
+type softsign struct{}
+
+func (softsign) Fnc(z float64) float64 { return z / (1 + math.Abs(z)) }
+
+func (softsign) Prime(z float64) float64 { return 1 / ((1 + math.Abs(z)) * (1 + math.Abs(z))) }
+
+func (softsign) GetName() string { return "test.Softsign" }
+
+// TestRegisterActivation проверяет запись и чтение нейронной сети с пользовательской функцией активации
+// и ошибку при чтении сети с незарегистрированной функцией активации.
+func TestRegisterActivation(t *testing.T) {
+	RegisterActivation("test.Softsign", func() Activation { return softsign{} })
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Expected panic for duplicate activation function")
+			}
+		}()
+		RegisterActivation("Sigmoid", func() Activation { return Sigmoid{} })
+	}()
+
+	for _, name := range []string{"", "test.Soft sign", "test.Soft\tsign"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected panic for activation function name %q", name)
+				}
+			}()
+			RegisterActivation(name, func() Activation { return softsign{} })
+		}()
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected panic for parametric activation function name %q", name)
+				}
+			}()
+			RegisterParametricActivation(name, func([]float64) (Activation, error) { return softsign{}, nil })
+		}()
+	}
+
+	nn, err := NewNeuralNetworkByName([]int{4, 3, 2}, "test.Softsign")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []string{"text", "binary"} {
+		buf := new(bytes.Buffer)
+		read := Read
+		if format == "binary" {
+			read = ReadBinary
+			err = nn.WriteBinary(buf)
+		} else {
+			err = nn.Write(buf)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := buf.String()
+
+		nnRead, err := read(buf)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if _, ok := nnRead.actFuncs[0].(softsign); !ok {
+			t.Errorf("%s: incorrect activation function %v", format, nnRead.actFuncs[0])
+		}
+
+		// незарегистрированная функция активации с именем той же длины
+		unknown := strings.Replace(data, "test.Softsign", "test.Unknown1", 1)
+		if _, err := read(strings.NewReader(unknown)); !errors.Is(err, ErrUnknownActivation) {
+			t.Errorf("%s: expected ErrUnknownActivation, got %v", format, err)
+		}
+	}
+
+	if _, err := NewNeuralNetworkByName([]int{4, 2}, "Unknown"); !errors.Is(err, ErrUnknownActivation) {
+		t.Errorf("Expected ErrUnknownActivation, got %v", err)
+	}
+	if names := Activations(); len(names) < 2 || names[0] != "ELU" {
+		t.Errorf("Incorrect registered activation functions %v", names)
+	}
+}
+
+// TestActivations проверяет производные встроенных функций активации численным дифференцированием,
+// ошибку на выходном слое для MSE и восстановление функции активации по имени.
+func TestActivations(t *testing.T) {
+	acts := []Activation{
+		Sigmoid{}, ReLU{}, LeakyReLU{Slope: 0.01}, LeakyReLU{Slope: 1. / 3}, Tanh{},
+		ELU{Alpha: 1}, ELU{Alpha: 0.25}, SELU{}, GELU{}, Swish{}, Softplus{}, Identity{},
+	}
+
+	h := 1e-6
+	for _, act := range acts {
+		// точки выбраны вдали от нуля, где производная ReLU не определена
+		for _, z := range []float64{-4, -1.3, -0.2, 0.35, 1.1, 3.7} {
+			num := (act.Fnc(z+h) - act.Fnc(z-h)) / (2 * h)
+			if math.Abs(num-act.Prime(z)) > 1e-7 {
+				t.Errorf("%s: derivative at %v: %v != %v", act.GetName(), z, act.Prime(z), num)
+			}
+		}
+
+		read, err := NewActivation(act.GetName())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(read, act) {
+			t.Errorf("%s: incorrect activation function %#v after reading by name", act.GetName(), read)
+		}
+
+		// ошибка на выходном слое равна градиенту квадратичной функции потерь по z
+		z, y := matrix.Zero(3, 1), matrix.Zero(3, 1)
+		z.Slice2Matrix([]float64{-0.7, 0.4, 2.1})
+		y.Slice2Matrix([]float64{0.2, 1, -0.5})
+		delta := matrix.Zero(3, 1)
+		outputDelta(delta, z, z.ForEach(act.Fnc), y, act, MSE{})
+		for i := 0; i < 3; i++ {
+			zi, yi := z.GetIJ(i, 0), y.GetIJ(i, 0)
+			loss := func(z float64) float64 { return 0.5 * (act.Fnc(z) - yi) * (act.Fnc(z) - yi) }
+			if num := (loss(zi+h) - loss(zi-h)) / (2 * h); math.Abs(num-delta.GetIJ(i, 0)) > 1e-7 {
+				t.Errorf("%s: delta %d: %v != %v", act.GetName(), i, delta.GetIJ(i, 0), num)
+			}
+		}
+	}
+
+	if x := (Softplus{}).Fnc(1000); x != 1000 {
+		t.Errorf("Softplus overflow: %v", x)
+	}
+
+	for _, name := range []string{"LeakyReLU", "LeakyReLU()", "LeakyReLU(0.1,2)", "ELU(x)", "ELU(1"} {
+		if _, err := NewActivation(name); err == nil {
+			t.Errorf("Expected error for %q", name)
+		}
+	}
+
+	// сеть с функцией активации с параметрами записывается и читается
+	nn := NewNeuralNetwork([]int{3, 2}, LeakyReLU{Slope: 0.2})
+	buf := new(bytes.Buffer)
+	if err := nn.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	nnRead, err := Read(buf)
+	if err != nil || nnRead.actFuncs[0] != (LeakyReLU{Slope: 0.2}) {
+		t.Errorf("Incorrect activation function after reading: %v, %v", nnRead.actFuncs[0], err)
+	}
+}
+
+// TestLayerActivations проверяет нейронную сеть с функциями активации слоев:
+// прямое распространение, обучение и запись и чтение во всех форматах,
+// в том числе чтение файлов с одной функцией активации.
+func TestLayerActivations(t *testing.T) {
+	acts := []Activation{ReLU{}, LeakyReLU{Slope: 0.1}, Sigmoid{}}
+	nn := NewNeuralNetworkLayersFrom([]int{4, 5, 3, 2}, acts, matrix.Float64, rand.New(rand.NewSource(2)))
+
+	x := matrix.RandMatrixFrom(4, 1, matrix.Float64, rand.New(rand.NewSource(3)))
+	a := x
+	for i := range nn.weights {
+		a = nn.weights[i].Dot(a).Add(nn.biases[i]).ForEach(acts[i].Fnc)
+	}
+	out, err := nn.Predict(x)
+	if err != nil || !isClose(out, a) {
+		t.Fatalf("Incorrect feedforward with layer activations: %v", err)
+	}
+
+	// обучение обратным распространением совпадает с автоматическим дифференцированием
+	df := data_frame.DataFrame{}
+	for i := 0; i < 8; i++ {
+		y := matrix.Zero(2, 1)
+		y.SetIJ(i%2, 0, 1.)
+		df.Append(matrix.RandMatrixFrom(4, 1, matrix.Float64, rand.New(rand.NewSource(int64(i)))), y)
+	}
+	train := func(autodiff bool) NeuralNetwork {
+		nn := NewNeuralNetworkLayersFrom([]int{4, 5, 3, 2}, acts, matrix.Float64, rand.New(rand.NewSource(2)))
+		nn.SetAutodiff(autodiff)
+		dfTrain := data_frame.DataFrame{Data: append(df.Data[:0:0], df.Data...)}
+		if err := nn.Sgd(&dfTrain, 2, 3, 1, 0.1, false, false); err != nil {
+			t.Fatal(err)
+		}
+		return nn
+	}
+	manual, auto := train(false), train(true)
+	for i := range manual.weights {
+		if !isClose(manual.weights[i], auto.weights[i]) || !isClose(manual.biases[i], auto.biases[i]) {
+			t.Errorf("Parameters of layer %d trained with autodiff are different", i)
+		}
+	}
+
+	checkActs := func(format string, nnRead NeuralNetwork, expected []Activation) {
+		t.Helper()
+		if !reflect.DeepEqual(nnRead.actFuncs, expected) {
+			t.Errorf("%s: incorrect activation functions %v", format, nnRead.actFuncs)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := nn.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	nnRead, err := Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkActs("text", nnRead, acts)
+
+	buf.Reset()
+	if err := nn.WriteBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+	if nnRead, err = ReadBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+	checkActs("binary", nnRead, acts)
+
+	buf.Reset()
+	if err := nn.WriteNpz(buf); err != nil {
+		t.Fatal(err)
+	}
+	if nnRead, err = ReadNpz(bytes.NewReader(buf.Bytes()), int64(buf.Len()), acts...); err != nil {
+		t.Fatal(err)
+	}
+	checkActs("npz", nnRead, acts)
+	if nnRead, err = ReadNpz(bytes.NewReader(buf.Bytes()), int64(buf.Len())); err != nil {
+		t.Fatal(err)
+	}
+	checkActs("npz with stored activations", nnRead, acts)
+	if nnRead, err = ReadNpz(bytes.NewReader(buf.Bytes()), int64(buf.Len()), Tanh{}); err != nil {
+		t.Fatal(err)
+	}
+	checkActs("npz with explicit activations", nnRead, []Activation{Tanh{}, Tanh{}, Tanh{}})
+	if _, err = ReadNpz(bytes.NewReader(buf.Bytes()), int64(buf.Len()), acts[:2]...); err == nil {
+		t.Errorf("Expected error for incorrect number of activation functions")
+	}
+
+	// файл с одной функцией активации на все слои
+	old, err := ReadFromFile("../../data/network_parameters/net_par.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkActs("old text", old, []Activation{Sigmoid{}, Sigmoid{}})
+
+	// двоичный формат версии 1: одно имя функции активации без их количества
+	buf.Reset()
+	buf.WriteString(binaryMagic)
+	buf.Write([]byte{1, 0, 4, 0})
+	buf.WriteString("Tanh")
+	buf.WriteByte(0)
+	if err := matrix.WriteMatrixesBinary(buf, append(append([]matrix.Matrix{}, nn.weights...), nn.biases...)); err != nil {
+		t.Fatal(err)
+	}
+	if nnRead, err = ReadBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+	checkActs("binary version 1", nnRead, []Activation{Tanh{}, Tanh{}, Tanh{}})
+
+	if _, err := Read(strings.NewReader("3\n4 5 2\nReLU Tanh Sigmoid\n0\n\n")); err == nil {
+		t.Errorf("Expected error for incorrect number of activation functions")
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Expected panic for incorrect number of activation functions")
+			}
+		}()
+		NewNeuralNetworkLayers([]int{4, 5, 2}, acts)
+	}()
+}
+
+// TestLoss проверяет градиенты функций потерь численным дифференцированием, совмещенную ошибку
+// бинарной перекрестной энтропии с сигмоидой, обучение с заданной функцией потерь и среднее значение потерь.
+func TestLoss(t *testing.T) {
+	a, y := matrix.Zero(3, 1), matrix.Zero(3, 1)
+	a.Slice2Matrix([]float64{0.2, 0.65, 0.9})
+	y.Slice2Matrix([]float64{0, 1, 0.1})
+
+	h := 1e-6
+	losses := []Loss{MSE{}, BinaryCrossEntropy{}, CategoricalCrossEntropy{}, Huber{Delta: 0.5}, Huber{}, Hinge{}}
+	for _, loss := range losses {
+		grad := matrix.Zero(3, 1)
+		loss.Gradient(grad, a, y)
+		for i := 0; i < 3; i++ {
+			ai := a.GetIJ(i, 0)
+			a.SetIJ(i, 0, ai+h)
+			plus := loss.Value(a, y)
+			a.SetIJ(i, 0, ai-h)
+			minus := loss.Value(a, y)
+			a.SetIJ(i, 0, ai)
+			if num := (plus - minus) / (2 * h); math.Abs(num-grad.GetIJ(i, 0)) > 1e-6 {
+				t.Errorf("%s: gradient %d: %v != %v", loss.GetName(), i, grad.GetIJ(i, 0), num)
+			}
+		}
+	}
+	if name := (Huber{}).GetName(); name != "Huber(1)" {
+		t.Errorf("Incorrect name %q", name)
+	}
+
+	// совмещенная ошибка совпадает с общей формулой и не обращается в ноль при насыщении сигмоиды
+	z := matrix.Zero(3, 1)
+	z.Slice2Matrix([]float64{-1.2, 0.3, 50})
+	out := z.ForEach(Sigmoid{}.Fnc)
+	fused, general := matrix.Zero(3, 1), matrix.Zero(3, 1)
+	outputDelta(fused, z, out, y, Sigmoid{}, BinaryCrossEntropy{})
+	BinaryCrossEntropy{}.Gradient(general, out, y)
+	general = general.HadamardProduct(z.ForEach(Sigmoid{}.Prime))
+	if !isClose(fused, out.Sub(y)) || math.Abs(fused.GetIJ(0, 0)-general.GetIJ(0, 0)) > 1e-9 ||
+		math.Abs(fused.GetIJ(1, 0)-general.GetIJ(1, 0)) > 1e-9 {
+		t.Errorf("Incorrect fused delta %v, general %v", fused, general)
+	}
+
+	nn := NewNeuralNetwork([]int{4, 2}, Sigmoid{})
+	if _, ok := nn.GetLoss().(BinaryCrossEntropy); !ok {
+		t.Errorf("Incorrect default loss %v for Sigmoid", nn.GetLoss())
+	}
+	nn.SetLoss(Hinge{})
+	if _, ok := nn.GetLoss().(Hinge); !ok {
+		t.Errorf("Incorrect loss %v", nn.GetLoss())
+	}
+	nn.SetLoss(nil)
+	nnReLU := NewNeuralNetwork([]int{4, 2}, ReLU{})
+	if _, ok := nnReLU.GetLoss().(MSE); !ok || nn.GetLoss() != (BinaryCrossEntropy{}) {
+		t.Errorf("Incorrect default loss")
+	}
+
+	// обучение с заданной функцией потерь совпадает с автоматическим дифференцированием
+	df := data_frame.DataFrame{}
+	for i := 0; i < 8; i++ {
+		y := matrix.Zero(2, 1)
+		y.SetIJ(i%2, 0, 1.)
+		df.Append(matrix.RandMatrixFrom(4, 1, matrix.Float64, rand.New(rand.NewSource(int64(i)))), y)
+	}
+	for _, loss := range []Loss{Huber{Delta: 0.1}, Hinge{}, CategoricalCrossEntropy{}} {
+		train := func(autodiff bool) NeuralNetwork {
+			nn := NewNeuralNetworkLayersFrom([]int{4, 5, 2}, []Activation{Tanh{}, Sigmoid{}}, matrix.Float64, rand.New(rand.NewSource(2)))
+			nn.SetLoss(loss)
+			nn.SetAutodiff(autodiff)
+			dfTrain := data_frame.DataFrame{Data: append(df.Data[:0:0], df.Data...)}
+			if err := nn.Sgd(&dfTrain, 2, 3, 1, 0.1, false, false); err != nil {
+				t.Fatal(err)
+			}
+			return nn
+		}
+		manual, auto := train(false), train(true)
+		for i := range manual.weights {
+			if !isClose(manual.weights[i], auto.weights[i]) || !isClose(manual.biases[i], auto.biases[i]) {
+				t.Errorf("%s: parameters of layer %d trained with autodiff are different", loss.GetName(), i)
+			}
+		}
+
+		avg, err := manual.AverageLoss(df)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum := 0.
+		for i := range df.Data {
+			out, _ := manual.Predict(df.Data[i].GetX())
+			sum += loss.Value(out, df.Data[i].GetY())
+		}
+		if math.Abs(avg-sum/float64(len(df.Data))) > 1e-12 {
+			t.Errorf("%s: incorrect average loss %v", loss.GetName(), avg)
+		}
+	}
+
+	// среднее значение потерь сети с нормализацией не изменяет датафрейм,
+	// сеть float32 принимает датафрейм float64
+	nn = NewNeuralNetworkFrom([]int{4, 2}, Sigmoid{}, matrix.Float64, rand.New(rand.NewSource(5)))
+	dfTrain := data_frame.DataFrame{Data: append(df.Data[:0:0], df.Data...)}
+	if err := nn.Sgd(&dfTrain, 1, 3, 0.5, 0.1, false, true); err != nil {
+		t.Fatal(err)
+	}
+	dfTest := data_frame.DataFrame{}
+	for i := 0; i < 4; i++ {
+		x := matrix.RandMatrixFrom(4, 1, matrix.Float64, rand.New(rand.NewSource(int64(10+i))))
+		x.ScaleInPlace(500)
+		dfTest.Append(x, df.Data[i].GetY())
+	}
+	x := dfTest.Data[0].GetX().AsType(matrix.Float64)
+	first, err := nn.AverageLoss(dfTest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second, err := nn.AverageLoss(dfTest); err != nil || second != first {
+		t.Errorf("Average loss changed on second call: %v != %v, %v", second, first, err)
+	}
+	if !matrix.IsMatrixesEqual(dfTest.Data[0].GetX(), x) {
+		t.Errorf("It is forbidden to change original data frame")
+	}
+	nn32 := nn.AsType(matrix.Float32)
+	if avg, err := nn32.AverageLoss(dfTest); err != nil || math.Abs(avg-first) > 1e-4 {
+		t.Errorf("Incorrect average loss of float32 neural network %v: %v", avg, err)
+	}
+
+	if _, err := nn.AverageLoss(data_frame.DataFrame{}); err == nil {
+		t.Errorf("Expected error for empty data frame")
+	}
+	var shapeErr *matrix.ShapeError
+	nn = NewNeuralNetwork([]int{4, 3}, Sigmoid{})
+	if _, err := nn.AverageLoss(df); !errors.As(err, &shapeErr) {
+		t.Errorf("Expected *matrix.ShapeError, got %v", err)
+	}
+}
+
+// TestSoftmax проверяет Softmax на выходном и скрытом слоях: вероятности на выходе, совмещенную ошибку
+// с категориальной перекрестной энтропией, обучение, запись и чтение, вероятности классов и accuracy.
+func TestSoftmax(t *testing.T) {
+	z := matrix.Zero(4, 1)
+	z.Slice2Matrix([]float64{-1.5, 0.2, 3, 800})
+	a := matrix.Zero(4, 1)
+	Softmax{}.apply(a, z)
+	if !isClose(a, z.Softmax()) || math.Abs(a.Sum()-1) > 1e-12 {
+		t.Errorf("Incorrect softmax %v", a)
+	}
+
+	z.Slice2Matrix([]float64{-1.5, 0.2, 3, 0.7})
+	Softmax{}.apply(a, z)
+	g := matrix.Zero(4, 1)
+	g.Slice2Matrix([]float64{0.3, -2, 0.1, 1})
+	jvp := matrix.Zero(4, 1)
+	Softmax{}.backward(jvp, z, g)
+	if !isClose(jvp, z.SoftmaxJVP(g)) {
+		t.Errorf("Incorrect softmax backward %v", jvp)
+	}
+
+	// совмещенная ошибка совпадает с произведением градиента на матрицу Якоби
+	for _, values := range [][]float64{{0, 0, 1, 0}, {0.1, 0.2, 0.3, 0.4}, {0, 2, 0, 0}} {
+		y := matrix.Zero(4, 1)
+		y.Slice2Matrix(values)
+		fused, general := matrix.Zero(4, 1), matrix.Zero(4, 1)
+		outputDelta(fused, z, a, y, Softmax{}, CategoricalCrossEntropy{})
+		CategoricalCrossEntropy{}.Gradient(general, a, y)
+		Softmax{}.backward(general, z, general)
+		if !isClose(fused, general) {
+			t.Errorf("Fused delta %v != %v", fused, general)
+		}
+	}
+
+	act, err := NewActivation("Softmax")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !math.IsNaN(act.Fnc(0)) || !math.IsNaN(act.Prime(0)) {
+		t.Errorf("Expected NaN for element-wise Softmax, got %v and %v", act.Fnc(0), act.Prime(0))
+	}
+
+	df, dfLabels := data_frame.DataFrame{}, data_frame.DataFrame{}
+	for i := 0; i < 9; i++ {
+		x := matrix.RandMatrixFrom(4, 1, matrix.Float64, rand.New(rand.NewSource(int64(i))))
+		x.ScaleInPlace(100)
+		y, label := matrix.Zero(3, 1), matrix.Zero(1, 1)
+		y.SetIJ(i%3, 0, 1.)
+		label.SetIJ(0, 0, float64(i%3))
+		df.Append(x, y)
+		dfLabels.Append(x, label)
+	}
+
+	for _, acts := range [][]Activation{{ReLU{}, Softmax{}}, {Tanh{}, Softmax{}, Softmax{}}} {
+		sizes := []int{4, 5, 3}
+		if len(acts) == 3 {
+			sizes = []int{4, 5, 3, 3}
+		}
+		train := func(autodiff bool) NeuralNetwork {
+			nn := NewNeuralNetworkLayersFrom(sizes, acts, matrix.Float64, rand.New(rand.NewSource(2)))
+			nn.SetAutodiff(autodiff)
+			dfTrain := data_frame.DataFrame{Data: append(df.Data[:0:0], df.Data...)}
+			if err := nn.Sgd(&dfTrain, 3, 2, 0.5, 0.1, false, false); err != nil {
+				t.Fatal(err)
+			}
+			return nn
+		}
+		nn, auto := train(false), train(true)
+		for i := range nn.weights {
+			if !isClose(nn.weights[i], auto.weights[i]) || !isClose(nn.biases[i], auto.biases[i]) {
+				t.Errorf("%v: parameters of layer %d trained with autodiff are different", acts, i)
+			}
+		}
+		if _, ok := nn.GetLoss().(CategoricalCrossEntropy); !ok {
+			t.Errorf("Incorrect default loss %v for Softmax", nn.GetLoss())
+		}
+
+		cnt := 0
+		for i := range df.Data {
+			out, err := nn.Predict(df.Data[i].GetX())
+			if err != nil || math.Abs(out.Sum()-1) > 1e-12 {
+				t.Fatalf("Output is not probabilities: %v, %v", out, err)
+			}
+			proba, err := nn.PredictProba(df.Data[i].GetX())
+			if err != nil || !isBitEqual(proba, out) {
+				t.Errorf("Incorrect probabilities %v: %v", proba, err)
+			}
+			if pred, _ := out.ArgMax(); pred == i%3 {
+				cnt++
+			}
+		}
+		if acc := nn.Accuracy(df); acc != float64(cnt)/9*100 || nn.Accuracy(dfLabels) != acc {
+			t.Errorf("Incorrect accuracy %v", acc)
+		}
+
+		for _, format := range []string{"text", "binary"} {
+			buf := new(bytes.Buffer)
+			read := Read
+			var err error
+			if format == "binary" {
+				read = ReadBinary
+				err = nn.WriteBinary(buf)
+			} else {
+				err = nn.Write(buf)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			nnRead, err := read(buf)
+			if err != nil || !reflect.DeepEqual(nnRead.actFuncs, acts) {
+				t.Errorf("%s: incorrect activation functions %v: %v", format, nnRead.actFuncs, err)
+			}
+		}
+	}
+
+	// вероятности классов для сигмоиды нормируются, для одного выходного нейрона два класса
+	x := df.Data[0].GetX()
+	nn := NewNeuralNetwork([]int{4, 3}, Sigmoid{})
+	out, _ := nn.Predict(x)
+	proba, err := nn.PredictProba(x)
+	out.ScaleInPlace(1 / out.Sum())
+	if err != nil || !isClose(proba, out) {
+		t.Errorf("Incorrect probabilities %v: %v", proba, err)
+	}
+	// вектор признаков не изменяется при нормализации
+	nn.norm, nn.haveNormalization = matrix.RandMatrix(4, 1), true
+	xCopy := x.AsType(matrix.Float64)
+	if _, err := nn.PredictProba(x); err != nil || !isBitEqual(x, xCopy) {
+		t.Errorf("It is forbidden to change original matrix: %v", err)
+	}
+
+	nn = NewNeuralNetwork([]int{4, 1}, Sigmoid{})
+	out, _ = nn.Predict(x)
+	proba, err = nn.PredictProbaSparse(matrix.SparseFromMatrix(x))
+	binary := matrix.Zero(2, 1)
+	binary.Slice2Matrix([]float64{1 - matrix.Num(out), matrix.Num(out)})
+	if err != nil || !isClose(proba, binary) {
+		t.Errorf("Incorrect binary probabilities %v: %v", proba, err)
+	}
+	nn = NewNeuralNetwork([]int{4, 3}, ReLU{})
+	if _, err := nn.PredictProba(x); !errors.Is(err, ErrNoProbabilities) {
+		t.Errorf("Expected ErrNoProbabilities, got %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+package neural_network
+
+// файл содержит обучение нейронной сети на мини батчах
+
+import (
+	"github.com/Davmgiz/GoblinNeuronet/pkg/autodiff"
+	"github.com/Davmgiz/GoblinNeuronet/pkg/data_frame"
+	"github.com/Davmgiz/GoblinNeuronet/pkg/matrix"
+)
+
+// trainer хранит буферы для обучения нейронной сети (структуры NeuralNetwork).
+// Градиенты по мини батчу создаются один раз, а промежуточные матрицы одного наблюдения
+// берутся из набора matrix.Workspace и возвращаются в него после обработки наблюдения,
+// поэтому после первого мини батча обучение не выделяет память.
+type trainer struct {
+	nn           *NeuralNetwork
+	fncs         []func(float64) float64 // Функции активации слоев, сохраненные один раз, чтобы не создавать значение метода на каждом вызове
+	primes       []func(float64) float64 // Производные функций активации слоев
+	loss         Loss                    // Функция потерь сети
+	ws           *matrix.Workspace       // Промежуточные матрицы одного наблюдения
+	nablaWeights []matrix.Matrix         // Градиенты весов по мини батчу
+	nablaBiases  []matrix.Matrix         // Градиенты смещений по мини батчу
+	zs           []matrix.Matrix         // Взвешенные суммы слоев текущего наблюдения
+	activations  []matrix.Matrix         // Активации слоев текущего наблюдения, активации входного слоя хранятся во входе
+	tape         *autodiff.Tape          // Лента вычислений одного наблюдения при автоматическом дифференцировании
+}
+
+// newTrainer возвращает указатель на trainer для обучения нейронной сети nn.
+// Тип элементов и архитектура сети не должны меняться во время использования trainer.
+func newTrainer(nn *NeuralNetwork) *trainer {
+	fncs := make([]func(float64) float64, len(nn.actFuncs))
+	primes := make([]func(float64) float64, len(nn.actFuncs))
+	for i, actFunc := range nn.actFuncs {
+		fncs[i] = actFunc.Fnc
+		primes[i] = actFunc.Prime
+	}
+
+	return &trainer{
+		nn:           nn,
+		fncs:         fncs,
+		primes:       primes,
+		loss:         nn.GetLoss(),
+		ws:           matrix.NewWorkspace(),
+		nablaWeights: *matrix.Zeros(&nn.weights),
+		nablaBiases:  *matrix.Zeros(&nn.biases),
+		zs:           make([]matrix.Matrix, nn.numLayers-1),
+		activations:  make([]matrix.Matrix, nn.numLayers),
+		tape:         autodiff.NewTape(),
+	}
+}
+
+// updateMiniBatch обновляет веса и смещения исходной нейронной сети.
+// eta скорость обучения,
+// lmd коэффициент регуляризации L2,
+// lenDf размер датафрейма на котором производится обучение.
+// Метод возвращает ошибку, если размерность наблюдения не соответствует нейронной сети,
+// при этом веса и смещения не изменяются.
+func (tr *trainer) updateMiniBatch(miniBatch data_frame.DataFrame, eta float64, lmd float64, lenDf int) error {
+	nn := tr.nn
+
+	// используем обратное распространение чтобы найти градиент по каждому наблюдению из miniBatch,
+	// градиенты наблюдений прибавляются к градиентам по miniBatch
+	for i := 0; i < miniBatch.Lenght(); i++ {
+		if err := tr.backProp(rowInput(miniBatch, i), miniBatch.Data[i].GetY()); err != nil {
+			tr.clearGradients()
+			return err
+		}
+	}
+
+	// считаем коэффициенты для регуляризации L2
+	k := eta / float64(miniBatch.Lenght())
+	wk := 1. - eta*(lmd/float64(lenDf))
+
+	// обновляем веса: w = w * wk - k * nablaW
+	for j := 0; j < len(nn.weights); j++ {
+		nn.weights[j].ScaleInPlace(wk)
+		nn.weights[j].AddScaledInPlace(-k, tr.nablaWeights[j])
+	}
+
+	// обновляем смещения: b = b - k * nablaB
+	for j := 0; j < len(nn.biases); j++ {
+		nn.biases[j].AddScaledInPlace(-k, tr.nablaBiases[j])
+	}
+
+	tr.clearGradients()
+
+	return nil
+}
+
+// clearGradients обнуляет градиенты по мини батчу.
+func (tr *trainer) clearGradients() {
+	for j := range tr.nablaWeights {
+		tr.nablaWeights[j].Fill(0.)
+		tr.nablaBiases[j].Fill(0.)
+	}
+}
+
+// backProp прибавляет градиенты по одному наблюдению к градиентам по мини батчу.
+// Реализует обратное распространение.
+// in вектор признаков наблюдения, плотный или разреженный,
+// y целевая переменная наблюдения представленная виде матрицы.
+// Градиенты весов первого слоя для разреженного вектора признаков считаются
+// без преобразования его в плотную матрицу.
+// Метод возвращает *matrix.ShapeError, если размерность in или y не соответствует нейронной сети.
+func (tr *trainer) backProp(in input, y matrix.Matrix) error {
+	nn := tr.nn
+
+	if err := nn.checkInput(in); err != nil {
+		return err
+	}
+	if err := nn.checkTarget(y); err != nil {
+		return err
+	}
+
+	// вектор признаков и целевая переменная приводятся к типу элементов нейронной сети
+	dtype := nn.DType()
+	in = in.asType(dtype)
+	if y.DType() != dtype {
+		y = y.AsType(dtype)
+	}
+
+	if nn.useAutodiff {
+		tr.backPropTape(in, y)
+		return nil
+	}
+
+	// промежуточные матрицы наблюдения возвращаются в набор после подсчета градиентов
+	defer tr.ws.Reset()
+
+	// заполняем activations и zs: z = w * a + b, a = activation_function(z)
+	for i := 0; i < nn.numLayers-1; i++ {
+		z := tr.ws.Get(nn.sizes[i+1], 1, dtype)
+		if i == 0 {
+			in.weighInto(z, nn.weights[i])
+		} else {
+			matrix.DotInto(z, nn.weights[i], tr.activations[i])
+		}
+		matrix.AddInto(z, z, nn.biases[i])
+		tr.zs[i] = z
+
+		activation := tr.ws.Get(nn.sizes[i+1], 1, dtype)
+		activate(activation, z, nn.actFuncs[i], tr.fncs[i])
+		tr.activations[i+1] = activation
+	}
+
+	// ошибка для выходного слоя: градиент функции потерь по взвешенной сумме выходного слоя
+	last := nn.numLayers - 2
+	delta := tr.ws.Get(nn.sizes[last+1], 1, dtype)
+	outputDelta(delta, tr.zs[last], tr.activations[last+1], y, nn.actFuncs[last], tr.loss)
+
+	// находим градиенты слоев от выходного к входному
+	for l := last; l >= 0; l-- {
+
+		// ошибка скрытого слоя: delta = (w[l+1]^T * delta) ⊙ activation_function'(z),
+		// для функций активации всего слоя произведение на матрицу Якоби
+		if l < last {
+			next := tr.ws.Get(nn.sizes[l+1], 1, dtype)
+			matrix.TDotInto(next, nn.weights[l+1], delta)
+
+			if v, ok := nn.actFuncs[l].(vectorActivation); ok {
+				v.backward(next, tr.zs[l], next)
+			} else {
+				prime := tr.ws.Get(nn.sizes[l+1], 1, dtype)
+				matrix.ForEachInto(prime, tr.zs[l], tr.primes[l])
+				matrix.HadamardProductInto(next, next, prime)
+			}
+
+			delta = next
+		}
+
+		tr.nablaBiases[l].AddInPlace(delta)
+
+		// градиент весов: delta * a^T, активации входного слоя берутся из вектора признаков
+		grad := tr.ws.Get(nn.sizes[l+1], nn.sizes[l], dtype)
+		if l == 0 {
+			in.outerInto(grad, delta)
+		} else {
+			matrix.DotTInto(grad, delta, tr.activations[l])
+		}
+		tr.nablaWeights[l].AddInPlace(grad)
+	}
+
+	return nil
+}
+
+// backPropTape прибавляет градиенты по одному наблюдению к градиентам по мини батчу,
+// вычисляя их автоматическим дифференцированием.
+// Ошибка на выходном слое является градиентом функции потерь по взвешенной сумме выходного слоя
+// и вычисляется так же, как в backProp, обратный проход начинается от нее.
+// Размерности и типы элементов in и y должны быть проверены и приведены заранее.
+func (tr *trainer) backPropTape(in input, y matrix.Matrix) {
+	nn := tr.nn
+	tape := tr.tape
+	defer tape.Reset()
+
+	weights := make([]autodiff.Variable, len(nn.weights))
+	biases := make([]autodiff.Variable, len(nn.biases))
+	for i := range nn.weights {
+		weights[i] = tape.Variable(nn.weights[i])
+		biases[i] = tape.Variable(nn.biases[i])
+	}
+
+	// прямое распространение записывается на ленту: z = w * a + b, a = activation_function(z)
+	var z, a autodiff.Variable
+	for i := 0; i < nn.numLayers-1; i++ {
+		if i == 0 {
+			z = in.weighVariable(tape, weights[i])
+		} else {
+			z = weights[i].Dot(a)
+		}
+		z = z.Add(biases[i])
+		if v, ok := nn.actFuncs[i].(vectorActivation); ok {
+			a = v.variable(z)
+		} else {
+			a = z.ForEach(tr.fncs[i], tr.primes[i])
+		}
+	}
+
+	delta := matrix.ZeroOf(z.Value().GetRows(), 1, z.Value().DType())
+	outputDelta(delta, z.Value(), a.Value(), y, nn.actFuncs[len(nn.actFuncs)-1], tr.loss)
+	tape.BackwardWith(z, delta)
+
+	for i := range nn.weights {
+		tr.nablaWeights[i].AddInPlace(weights[i].Grad())
+		tr.nablaBiases[i].AddInPlace(biases[i].Grad())
+	}
+}